@@ -0,0 +1,7 @@
+package config
+
+// CommentConfig 评论配置
+type CommentConfig struct {
+	// 评论发布后可以编辑的时间，例如 15m，默认 15 分钟
+	EditWindow string `yaml:"edit_window"`
+}
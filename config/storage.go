@@ -0,0 +1,18 @@
+package config
+
+// StorageConfig 对象存储配置
+type StorageConfig struct {
+	// 存储类型：oss、local、memory
+	Type  string             `yaml:"type"`
+	Local LocalStorageConfig `yaml:"local"`
+}
+
+// LocalStorageConfig 本地文件系统存储配置
+type LocalStorageConfig struct {
+	// 文件存放的目录
+	Dir string `yaml:"dir"`
+	// 服务对外的地址，文件通过 {base_url}/uploads/{key} 访问
+	BaseURL string `yaml:"base_url"`
+	// 直传地址的签名密钥，不配置时每次启动随机生成
+	Secret string `yaml:"secret"`
+}
@@ -0,0 +1,25 @@
+package config
+
+// UploadConfig 上传限制和图片处理配置
+type UploadConfig struct {
+	// 单个文件的最大字节数，默认 10MB
+	MaxSize int64 `yaml:"max_size"`
+	// 图片的最大像素数（宽 × 高），默认 4000 万
+	MaxPixels int `yaml:"max_pixels"`
+	// 生成的缩略图宽度，默认 200 和 800，比原图宽的尺寸会被跳过
+	VariantWidths []int `yaml:"variant_widths"`
+	// 直传地址的有效期，例如 15m，默认 15 分钟
+	PresignExpire string `yaml:"presign_expire"`
+	// 附件分片上传配置
+	Attachment AttachmentConfig `yaml:"attachment"`
+}
+
+// AttachmentConfig 文章附件分片上传配置
+type AttachmentConfig struct {
+	// 单个附件的最大字节数，默认 200MB
+	MaxSize int64 `yaml:"max_size"`
+	// 默认分片大小，范围 1MB~16MB，默认 5MB
+	ChunkSize int64 `yaml:"chunk_size"`
+	// 上传任务的有效期，例如 24h，过期之后已上传的分片会被清理
+	Expire string `yaml:"expire"`
+}
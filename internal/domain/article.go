@@ -1,16 +1,26 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/Fairy-nn/inspora/pkg/render"
+)
 
 type Article struct {
-	ID      int64
-	Title   string
-	Content string
-	Author  Author
-	Status  ArticleStatus // 文章状态
-	Ctime   time.Time     // 创建时间
-	Utime   time.Time     // 更新时间
-	ImgUrls []string // 图片地址
+	ID        int64
+	Title     string
+	Content   string
+	Format    ContentFormat // 内容格式
+	HTML      string        // 根据内容格式渲染并清洗之后的 HTML
+	Author    Author
+	Status    ArticleStatus // 文章状态
+	Ctime     time.Time     // 创建时间
+	Utime     time.Time     // 更新时间
+	ImgUrls   []string      // 图片地址
+	PublishAt time.Time     // 定时发布时间，仅在定时发布状态下有效
+	Tags      []string      // 标签
+	SeriesNav *SeriesNav    // 文章所在系列的导航，只在查看已发布文章详情时填充
+	Version   int64         // 版本号，用于协作编辑时的乐观锁，修改文章时必须携带，小于等于 0 视为冲突
 }
 
 type Author struct {
@@ -26,6 +36,8 @@ const (
 	ArticleStatusDraft                          // 草稿状态
 	ArticleStatusPublished                      // 已发布状态
 	ArticleStatusPrivate                        // 私密状态
+	ArticleStatusScheduled                      // 定时发布状态
+	ArticleStatusDeleted                        // 已删除状态（软删除）
 )
 
 func (a ArticleStatus) ToUint8() uint8 {
@@ -35,7 +47,7 @@ func (a ArticleStatus) ToUint8() uint8 {
 // 判断文章状态是否有效
 func (a ArticleStatus) Valid() bool {
 	switch a {
-	case ArticleStatusUnknown, ArticleStatusDraft, ArticleStatusPublished, ArticleStatusPrivate:
+	case ArticleStatusUnknown, ArticleStatusDraft, ArticleStatusPublished, ArticleStatusPrivate, ArticleStatusScheduled, ArticleStatusDeleted:
 		return true
 	}
 	return false
@@ -50,15 +62,82 @@ func (a ArticleStatus) String() string {
 		return "已发布状态"
 	case ArticleStatusPrivate:
 		return "私密状态"
+	case ArticleStatusScheduled:
+		return "定时发布状态"
+	case ArticleStatusDeleted:
+		return "已删除状态"
 	default:
 		return "未知状态"
 	}
 }
 
+// ContentFormat 文章内容格式
+type ContentFormat uint8
+
+const (
+	ContentFormatPlain    ContentFormat = iota // 纯文本，历史文章默认为纯文本
+	ContentFormatMarkdown                      // Markdown
+	ContentFormatHTML                          // HTML
+)
+
+func (f ContentFormat) ToUint8() uint8 {
+	return uint8(f)
+}
+
+// 将内容格式转换为字符串
+func (f ContentFormat) String() string {
+	switch f {
+	case ContentFormatMarkdown:
+		return "markdown"
+	case ContentFormatHTML:
+		return "html"
+	default:
+		return "plain"
+	}
+}
+
+// ParseContentFormat 解析内容格式，空字符串视为纯文本
+func ParseContentFormat(s string) (ContentFormat, bool) {
+	switch s {
+	case "", "plain":
+		return ContentFormatPlain, true
+	case "markdown":
+		return ContentFormatMarkdown, true
+	case "html":
+		return ContentFormatHTML, true
+	}
+	return ContentFormatPlain, false
+}
+
+// RenderHTML 根据内容格式生成经过清洗的 HTML，用于展示
+func (a *Article) RenderHTML() string {
+	switch a.Format {
+	case ContentFormatMarkdown:
+		return render.Sanitize(render.Markdown(a.Content))
+	case ContentFormatHTML:
+		return render.Sanitize(a.Content)
+	default:
+		return render.PlainToHTML(a.Content)
+	}
+}
+
+// PlainText 根据内容格式生成纯文本，用于摘要、搜索索引和 feed 摘要
+func (a *Article) PlainText() string {
+	switch a.Format {
+	case ContentFormatMarkdown:
+		return render.StripTags(render.Markdown(a.Content))
+	case ContentFormatHTML:
+		return render.StripTags(a.Content)
+	default:
+		return a.Content
+	}
+}
+
 // 生成文章摘要
 func (a *Article) GenerateAbstract() string {
 	// 文章内容转换为 rune 切片,因为中文字符可能会占用多个字节
-	content := []rune(a.Content)
+	// 先转换为纯文本，避免 Markdown/HTML 标记出现在摘要中
+	content := []rune(a.PlainText())
 	// 如果内容长度大于 100 个字符，则截取前 100 个字符并添加省略号
 	if len(content) > 100 {
 		return string(content[:100]) + "..."
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Attachment 文章附件，例如 PDF 和压缩包
+type Attachment struct {
+	ID         int64
+	ArticleID  int64
+	UploaderID int64
+	Filename   string // 上传时的文件名，下载时使用
+	ObjectKey  string
+	URL        string
+	Size       int64
+	MimeType   string
+	SHA256     string // 整个文件的 SHA-256，十六进制
+	Ctime      time.Time
+}
+
+// ChunkUpload 分片上传任务，客户端按分片上传，网络中断之后可以只补传缺少的分片
+type ChunkUpload struct {
+	ID         string
+	UploaderID int64
+	ArticleID  int64
+	Filename   string
+	Size       int64
+	ChunkSize  int64
+	TotalParts int
+	SHA256     string         // 客户端声明的整个文件的 SHA-256
+	Parts      map[int]string // 已上传的分片序号（从 1 开始）到分片的 SHA-256
+	ExpireAt   time.Time
+}
+
+// PartSize 第 n 个分片的大小，除了最后一个分片都等于 ChunkSize
+func (u ChunkUpload) PartSize(n int) int64 {
+	if n < 1 || n > u.TotalParts {
+		return 0
+	}
+	if n < u.TotalParts {
+		return u.ChunkSize
+	}
+	return u.Size - int64(u.TotalParts-1)*u.ChunkSize
+}
+
+// MissingParts 还没有上传的分片序号
+func (u ChunkUpload) MissingParts() []int {
+	var missing []int
+	for n := 1; n <= u.TotalParts; n++ {
+		if _, ok := u.Parts[n]; !ok {
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,15 @@
+package domain
+
+// UserBlock 用户拉黑的记录
+type UserBlock struct {
+	UserID    int64 // 拉黑的用户
+	BlockedID int64 // 被拉黑的用户
+	Ctime     int64 // 毫秒
+}
+
+// UserMute 用户屏蔽的记录，屏蔽只是不再看到对方的内容，对方不会察觉
+type UserMute struct {
+	UserID  int64 // 屏蔽的用户
+	MutedID int64 // 被屏蔽的用户
+	Ctime   int64 // 毫秒
+}
@@ -0,0 +1,83 @@
+package domain
+
+import "time"
+
+// CollaboratorRole 协作者在文章中的角色
+type CollaboratorRole uint8
+
+const (
+	CollaboratorRoleUnknown CollaboratorRole = iota // 没有权限
+	CollaboratorRoleOwner                           // 所有者，即文章的作者
+	CollaboratorRoleEditor                          // 编辑者，可以编辑、发布和撤回文章
+	CollaboratorRoleViewer                          // 查看者，只能查看草稿
+)
+
+func (r CollaboratorRole) ToUint8() uint8 {
+	return uint8(r)
+}
+
+// CanView 是否可以查看文章
+func (r CollaboratorRole) CanView() bool {
+	return r == CollaboratorRoleOwner || r == CollaboratorRoleEditor || r == CollaboratorRoleViewer
+}
+
+// CanEdit 是否可以编辑、发布和撤回文章
+func (r CollaboratorRole) CanEdit() bool {
+	return r == CollaboratorRoleOwner || r == CollaboratorRoleEditor
+}
+
+// Invitable 是否可以邀请其他用户成为该角色，所有者只能是文章的作者
+func (r CollaboratorRole) Invitable() bool {
+	return r == CollaboratorRoleEditor || r == CollaboratorRoleViewer
+}
+
+func (r CollaboratorRole) String() string {
+	switch r {
+	case CollaboratorRoleOwner:
+		return "owner"
+	case CollaboratorRoleEditor:
+		return "editor"
+	case CollaboratorRoleViewer:
+		return "viewer"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseCollaboratorRole 解析客户端传入的角色
+func ParseCollaboratorRole(s string) CollaboratorRole {
+	switch s {
+	case "owner":
+		return CollaboratorRoleOwner
+	case "editor":
+		return CollaboratorRoleEditor
+	case "viewer":
+		return CollaboratorRoleViewer
+	default:
+		return CollaboratorRoleUnknown
+	}
+}
+
+// CollaboratorStatus 协作邀请的状态
+type CollaboratorStatus uint8
+
+const (
+	CollaboratorStatusPending  CollaboratorStatus = iota // 已邀请，等待对方接受
+	CollaboratorStatusAccepted                           // 已接受邀请
+)
+
+// Collaborator 文章的协作者，作者本人不在协作者列表中
+type Collaborator struct {
+	ArticleID int64
+	UserID    int64
+	Role      CollaboratorRole
+	Status    CollaboratorStatus
+	InviterID int64
+	Ctime     time.Time
+	Utime     time.Time
+}
+
+// Active 是否已接受邀请，只有接受邀请之后角色才生效
+func (c Collaborator) Active() bool {
+	return c.Status == CollaboratorStatusAccepted
+}
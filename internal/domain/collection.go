@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultCollectionID 收藏时不指定收藏夹就放在默认收藏夹中，默认收藏夹只有自己可见
+	DefaultCollectionID int64 = 0
+	// MaxCollectionNameLength 收藏夹名称的最大长度（字符数）
+	MaxCollectionNameLength = 64
+	// MaxCollectionDescriptionLength 收藏夹描述的最大长度（字符数）
+	MaxCollectionDescriptionLength = 512
+)
+
+// Collection 用户创建的收藏夹
+type Collection struct {
+	ID          int64
+	Name        string
+	Description string
+	Public      bool // 公开的收藏夹其他用户也可以查看
+	Uid         int64
+	ItemCnt     int64 // 收藏夹中的收藏数量
+	Ctime       time.Time
+	Utime       time.Time
+}
+
+// VisibleTo 收藏夹是否对用户可见，私密的收藏夹只有自己可见
+func (c Collection) VisibleTo(uid int64) bool {
+	return c.Public || c.Uid == uid
+}
+
+// CollectionItem 收藏夹中的一条收藏
+type CollectionItem struct {
+	ID           int64 // 收藏记录的ID，用作分页游标
+	CollectionID int64
+	Biz          string
+	BizID        int64
+	Ctime        time.Time // 收藏时间
+	Article      Article   // 收藏的文章，只包含摘要需要的字段
+}
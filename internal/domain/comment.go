@@ -1,16 +1,27 @@
 package domain
 
+import (
+	"math"
+
+	"github.com/Fairy-nn/inspora/pkg/render"
+)
+
 type Comment struct {
-	ID       int64      `json:"id"`
-	Content  string     `json:"content"`
-	UserID   int64      `json:"user_id"`
-	UserName string     `json:"user_name"`
-	Biz      string     `json:"biz"`
-	BizID    int64      `json:"biz_id"`             // 业务ID
-	ParentID int64      `json:"parent_id"`          // 父评论ID
-	RootID   int64      `json:"root_id"`            // 根评论ID
-	Ctime    int64      `json:"ctime"`              // 创建时间
-	Children []*Comment `json:"children,omitempty"` // 子评论列表
+	ID       int64         `json:"id"`
+	Content  string        `json:"content"`
+	UserID   int64         `json:"user_id"`
+	UserName string        `json:"user_name"`
+	Biz      string        `json:"biz"`
+	BizID    int64         `json:"biz_id"`             // 业务ID
+	ParentID int64         `json:"parent_id"`          // 父评论ID
+	RootID   int64         `json:"root_id"`            // 根评论ID
+	Ctime    int64         `json:"ctime"`              // 创建时间
+	Status   CommentStatus `json:"status"`             // 评论状态
+	Utime    int64         `json:"utime"`              // 最后一次编辑的时间，0 表示没有编辑过
+	LikeCnt  int64         `json:"like_cnt"`           // 点赞数
+	Liked    bool          `json:"liked"`              // 当前用户是否点赞
+	Mentions []Mention     `json:"mentions,omitempty"` // 评论中 @ 提及的用户
+	Children []*Comment    `json:"children,omitempty"` // 子评论列表
 }
 
 // 判断评论是否是根评论
@@ -18,11 +29,49 @@ func (c *Comment) IsRootComment() bool {
 	return c.ParentID <= 0
 }
 
+// RenderHTML 把评论内容转换为 HTML，提及的用户渲染为主页链接
+func (c *Comment) RenderHTML() string {
+	return render.LinkMentions(render.PlainToHTML(c.Content), MentionLinks(c.Mentions))
+}
+
+// IsEdited 判断评论是否被编辑过
+func (c *Comment) IsEdited() bool {
+	return c.Utime > 0
+}
+
+// CommentRevision 评论的历史版本
+type CommentRevision struct {
+	Content string `json:"content"`
+	Ctime   int64  `json:"ctime"` // 这一版内容的发布时间
+}
+
+// IsDeleted 判断评论是否已被删除
+func (c *Comment) IsDeleted() bool {
+	return c.Status == CommentStatusDeleted
+}
+
+// CommentDeletedPlaceholder 已删除评论对外展示的内容，评论本身保留以维持楼层结构
+const CommentDeletedPlaceholder = "[deleted]"
+
+// 热门评论排序参数：一条回复相当于两个赞，每过 12.5 小时分数衰减 1，
+// 也就是说 12.5 小时前的评论需要十倍的互动才能和新评论排在一起
+const (
+	CommentHotReplyWeight = 2
+	CommentHotDecay       = 45000 // 秒
+)
+
+// CommentHotScore 计算根评论的热度分数，ctime 为评论创建时间（秒）
+// 缓存中增量更新分数的 Lua 脚本使用同样的公式
+func CommentHotScore(likes, replies, ctime int64) float64 {
+	weighted := max(likes, 0) + CommentHotReplyWeight*max(replies, 0)
+	return math.Log10(float64(max(weighted, 1))) + float64(ctime)/CommentHotDecay
+}
+
 // 评论状态
 type CommentStatus uint8
 
 const (
 	CommentStatusNormal CommentStatus = iota + 1 // 正常状态
-	// 后面删除评论的时候，将状态改为删除状态，软删除
+	// 删除评论只把状态改为删除状态，子评论保留
 	CommentStatusDeleted // 已删除状态
 )
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor 分页游标格式不正确
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ArticleCursor 文章列表的分页游标，按 (utime, id) 倒序翻页
+// 零值表示从第一页开始
+type ArticleCursor struct {
+	Utime time.Time
+	ID    int64
+}
+
+// IsZero 是否为第一页
+func (c ArticleCursor) IsZero() bool {
+	return c.Utime.IsZero()
+}
+
+// Encode 编码为对客户端不透明的字符串，零值编码为空字符串
+func (c ArticleCursor) Encode() string {
+	if c.IsZero() {
+		return ""
+	}
+	raw := strconv.FormatInt(c.Utime.UnixMilli(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseArticleCursor 解析客户端传回的游标，空字符串表示第一页
+func ParseArticleCursor(s string) (ArticleCursor, error) {
+	if s == "" {
+		return ArticleCursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ArticleCursor{}, ErrInvalidCursor
+	}
+	utimeStr, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return ArticleCursor{}, ErrInvalidCursor
+	}
+	utime, err := strconv.ParseInt(utimeStr, 10, 64)
+	if err != nil || utime <= 0 {
+		return ArticleCursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id < 0 {
+		return ArticleCursor{}, ErrInvalidCursor
+	}
+	return ArticleCursor{Utime: time.UnixMilli(utime), ID: id}, nil
+}
+
+// NextArticleCursor 根据本页最后一篇文章生成下一页的游标
+// 本页不满 limit 时说明已经没有更多数据，返回零值
+func NextArticleCursor(articles []Article, limit int) ArticleCursor {
+	if len(articles) == 0 || len(articles) < limit {
+		return ArticleCursor{}
+	}
+	last := articles[len(articles)-1]
+	return ArticleCursor{Utime: last.Utime, ID: last.ID}
+}
@@ -1,9 +1,18 @@
 package domain
 
 type Interaction struct {
-	ViewCnt    int64 `json:"view_cnt"`
-	LikeCnt    int64 `json:"like_cnt"`
-	CollectCnt int64 `json:"collect_cnt"`
-	Collected  bool  `json:"collected"`
-	Liked      bool  `json:"liked"`
+	ViewCnt       int64 `json:"view_cnt"`        // 总浏览量，不包含爬虫的访问
+	UniqueViewCnt int64 `json:"unique_view_cnt"` // 独立浏览量，同一访客在去重窗口内只算一次
+	LikeCnt       int64 `json:"like_cnt"`
+	CollectCnt    int64 `json:"collect_cnt"`
+	Collected     bool  `json:"collected"`
+	Liked         bool  `json:"liked"`
+}
+
+// InteractionDelta 一段时间内某个业务对象点赞量和收藏量的变化，由计数事件聚合而来
+type InteractionDelta struct {
+	Biz        string
+	BizID      int64
+	LikeCnt    int64
+	CollectCnt int64
 }
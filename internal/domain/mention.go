@@ -0,0 +1,26 @@
+package domain
+
+import "fmt"
+
+// MaxMentions 一段内容最多提及的用户数，超出的部分忽略
+const MaxMentions = 20
+
+// Mention 内容中 @ 提及的用户
+type Mention struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// URL 被提及用户的主页地址
+func (m Mention) URL() string {
+	return fmt.Sprintf("/pub/authors/%d/articles", m.UserID)
+}
+
+// MentionLinks 返回用户名到主页地址的映射，用于把提及渲染为链接
+func MentionLinks(mentions []Mention) map[string]string {
+	links := make(map[string]string, len(mentions))
+	for _, m := range mentions {
+		links[m.Username] = m.URL()
+	}
+	return links
+}
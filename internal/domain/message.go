@@ -0,0 +1,50 @@
+package domain
+
+// MaxMessageLength 一条私信最多的字数
+const MaxMessageLength = 2000
+
+// Message 私信
+type Message struct {
+	ID         int64
+	SenderID   int64
+	ReceiverID int64
+	Content    string
+	Ctime      int64 // 毫秒
+}
+
+// Conversation 用户视角的一对一会话
+type Conversation struct {
+	Peer        Author  // 会话的另一方
+	LastMessage Message // 最近一条私信
+	Unread      int64   // 对方发来的未读私信数
+	Utime       int64   // 最近一条私信的时间，毫秒
+}
+
+// DMPolicy 谁可以给用户发私信
+type DMPolicy uint8
+
+const (
+	DMPolicyEveryone  DMPolicy = iota + 1 // 所有人，默认设置
+	DMPolicyFollowing                     // 只有我关注的人
+	DMPolicyNobody                        // 不接收私信
+)
+
+var dmPolicyNames = map[DMPolicy]string{
+	DMPolicyEveryone:  "everyone",
+	DMPolicyFollowing: "following",
+	DMPolicyNobody:    "nobody",
+}
+
+func (p DMPolicy) String() string {
+	return dmPolicyNames[p]
+}
+
+// ParseDMPolicy 根据名称解析私信设置
+func ParseDMPolicy(name string) (DMPolicy, bool) {
+	for p, n := range dmPolicyNames {
+		if n == name {
+			return p, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "fmt"
+
+// 通知类型，同时也是免打扰设置的类型
+const (
+	NotificationLike    = "like"    // 文章被点赞
+	NotificationComment = "comment" // 文章被评论
+	NotificationCollect = "collect" // 文章被收藏
+	NotificationFollow  = "follow"  // 被关注
+	NotificationMention = "mention" // 在文章或评论中被 @ 提及
+	NotificationReward  = "reward"  // 文章被打赏
+)
+
+// NotificationTypes 所有的通知类型
+var NotificationTypes = []string{
+	NotificationLike, NotificationComment, NotificationCollect,
+	NotificationFollow, NotificationMention, NotificationReward,
+}
+
+// ValidNotificationType 判断通知类型是否存在
+func ValidNotificationType(typ string) bool {
+	for _, t := range NotificationTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification 站内通知
+// 点赞、收藏和关注会聚合：同一对象上未读的同类通知合并为一条，
+// 只保留最近一次触发的用户和触发的人数，例如 "Alice 等 13 人赞了你的文章"
+type Notification struct {
+	ID        int64
+	UserID    int64 // 接收通知的用户
+	Type      string
+	Biz       string // 通知关联的对象，article、comment 或 user
+	BizID     int64
+	ArticleID int64  // 所属文章，没有时为 0
+	Actor     Author // 最近一次触发通知的用户
+	ActorCnt  int64  // 触发通知的人数
+	Content   string // 评论内容或提及所在内容的摘要
+	Amount    int64  // 打赏金额，单位为分
+	Read      bool
+	Ctime     int64 // 毫秒
+	Utime     int64 // 最近一次触发的时间，毫秒
+}
+
+// Aggregatable 判断通知是否需要聚合
+// 评论、提及和打赏各自带有内容，每一次都单独通知
+func (n Notification) Aggregatable() bool {
+	switch n.Type {
+	case NotificationLike, NotificationCollect, NotificationFollow:
+		return true
+	default:
+		return false
+	}
+}
+
+// GroupKey 可聚合通知的分组，同一用户未读的通知中分组唯一
+func (n Notification) GroupKey() string {
+	return fmt.Sprintf("%s:%s:%d", n.Type, n.Biz, n.BizID)
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// Series 作者创建的文章系列，文章按 ArticleIDs 的顺序排列
+type Series struct {
+	ID          int64
+	Title       string
+	Description string
+	AuthorID    int64
+	ArticleIDs  []int64
+	Ctime       time.Time
+	Utime       time.Time
+}
+
+// SeriesNavItem 系列导航中的上一篇/下一篇
+type SeriesNavItem struct {
+	ID    int64
+	Title string
+}
+
+// SeriesNav 文章在系列中的导航信息
+type SeriesNav struct {
+	SeriesID    int64
+	SeriesTitle string
+	Position    int // 从 1 开始的位置
+	Total       int // 系列中已发布的文章数量
+	Prev        *SeriesNavItem
+	Next        *SeriesNavItem
+}
+
+// Nav 计算文章在系列中的导航，published 为系列中已发布的文章，未发布的文章会被跳过
+// 文章不在已发布列表中时返回 nil
+func (s Series) Nav(articleID int64, published []Article) *SeriesNav {
+	titles := make(map[int64]string, len(published))
+	for _, a := range published {
+		titles[a.ID] = a.Title
+	}
+
+	// 按系列顺序过滤出已发布的文章
+	ordered := make([]int64, 0, len(s.ArticleIDs))
+	for _, id := range s.ArticleIDs {
+		if _, ok := titles[id]; ok {
+			ordered = append(ordered, id)
+		}
+	}
+
+	for i, id := range ordered {
+		if id != articleID {
+			continue
+		}
+		nav := &SeriesNav{
+			SeriesID:    s.ID,
+			SeriesTitle: s.Title,
+			Position:    i + 1,
+			Total:       len(ordered),
+		}
+		if i > 0 {
+			nav.Prev = &SeriesNavItem{ID: ordered[i-1], Title: titles[ordered[i-1]]}
+		}
+		if i < len(ordered)-1 {
+			nav.Next = &SeriesNavItem{ID: ordered[i+1], Title: titles[ordered[i+1]]}
+		}
+		return nav
+	}
+	return nil
+}
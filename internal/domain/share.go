@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// ArticleShare 文章的分享链接，持有令牌的人无需登录即可阅读私密文章
+type ArticleShare struct {
+	ID        int64
+	ArticleID int64
+	AuthorID  int64
+	Token     string
+	ExpireAt  time.Time
+	Ctime     time.Time
+}
+
+// Expired 分享链接在 now 时是否已经过期
+func (s ArticleShare) Expired(now time.Time) bool {
+	return !now.Before(s.ExpireAt)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTagsPerArticle = 5  // 每篇文章最多的标签数量
+	MaxTagLength      = 20 // 单个标签的最大字符数
+)
+
+// Tag 文章标签
+type Tag struct {
+	ID         int64
+	Name       string
+	ArticleCnt int64 // 使用该标签的已发布文章数量
+	Ctime      time.Time
+	Utime      time.Time
+}
+
+// NormalizeTag 标准化标签：去掉首尾空白和开头的 #，英文转小写，中间的空白合并为 "-"
+func NormalizeTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimLeft(tag, "#＃")
+	tag = strings.ToLower(tag)
+	return strings.Join(strings.Fields(tag), "-")
+}
+
+// NormalizeTags 标准化并去重标签，过滤空标签和超长标签，最多保留 MaxTagsPerArticle 个
+func NormalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+		if len(result) == MaxTagsPerArticle {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Upload 用户上传到对象存储的文件
+type Upload struct {
+	ID         int64
+	UploaderID int64
+	ObjectKey  string
+	Size       int64
+	MimeType   string
+	Width      int
+	Height     int
+	Variants   []string // 缩略图的对象键，随原图一起删除
+	ArticleID  int64    // 引用该文件的文章，0 表示没有被文章引用
+	Pending    bool     // 已经签发直传地址，还没有确认上传完成
+	Ctime      time.Time
+	Utime      time.Time
+}
+
+// ImageVariant 上传图片生成的缩略图
+type ImageVariant struct {
+	URL    string
+	Width  int
+	Height int
+}
+
+// UploadedImage 上传完成的图片，包含原图和各个尺寸的缩略图，供客户端渲染响应式图片
+type UploadedImage struct {
+	URL      string
+	Width    int
+	Height   int
+	MimeType string
+	Size     int64
+	Variants []ImageVariant // 按宽度从小到大排列，原图不够宽时没有对应的缩略图
+}
+
+// PresignedUpload 客户端直传的上传凭证，上传完成后需要调用确认接口
+type PresignedUpload struct {
+	UploadURL string            // 上传地址
+	Method    string            // 上传使用的 HTTP 方法
+	Headers   map[string]string // 上传时必须带上的请求头
+	FileURL   string            // 上传完成后文件的访问地址，确认上传时使用
+	MaxSize   int64
+	ExpireAt  time.Time
+}
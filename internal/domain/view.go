@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// botUserAgents 爬虫、脚本和预览服务的 User-Agent 中常见的关键字
+var botUserAgents = []string{
+	"bot", "spider", "crawl", "slurp", "scrapy", "curl", "wget", "httpclient",
+	"python-requests", "python-urllib", "go-http-client", "java/", "okhttp",
+	"node-fetch", "axios", "postman", "headless", "phantomjs", "libwww",
+	"facebookexternalhit", "preview",
+}
+
+// Viewer 阅读文章的访客，未登录的访客用设备标识区分
+type Viewer struct {
+	Uid       int64
+	DeviceID  string // 服务端根据 IP 和 User-Agent 生成的设备标识
+	UserAgent string
+}
+
+// Key 访客的唯一标识，同一个访客在去重窗口内多次阅读只算一次独立浏览
+func (v Viewer) Key() string {
+	if v.Uid > 0 {
+		return "u:" + strconv.FormatInt(v.Uid, 10)
+	}
+	return "d:" + v.DeviceID
+}
+
+// IsBot 根据 User-Agent 判断是否为爬虫等自动化程序，这类访问不计入浏览量
+func (v Viewer) IsBot() bool {
+	ua := strings.ToLower(strings.TrimSpace(v.UserAgent))
+	if ua == "" {
+		return true
+	}
+	for _, kw := range botUserAgents {
+		if strings.Contains(ua, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Fairy-nn/inspora/internal/domain"
 	"github.com/Fairy-nn/inspora/internal/repository"
 	"github.com/IBM/sarama"
 )
@@ -87,16 +88,23 @@ func (k *InteractionBatchConsumer) ConsumeClaim(session sarama.ConsumerGroupSess
 			continue // 没有消息，继续下一个批次
 		}
 
-		ids := make([]int64, 0, len(ts))   // ID切片
-		bizs := make([]string, 0, len(ts)) // 业务切片
+		ids := make([]int64, 0, len(ts))       // ID切片
+		bizs := make([]string, 0, len(ts))     // 业务切片
+		visitors := make([]string, 0, len(ts)) // 访客切片
 		for _, v := range ts {
 			ids = append(ids, v.Aid)       // 添加文章ID
 			bizs = append(bizs, "article") // 添加业务类型
+			visitor := v.Visitor
+			if visitor == "" {
+				// 升级前发送的事件没有访客标识，按用户去重
+				visitor = domain.Viewer{Uid: v.Uid}.Key()
+			}
+			visitors = append(visitors, visitor)
 		}
 		// 批量处理消息
 		ctx_1, cancel_1 := context.WithTimeout(context.Background(), time.Second*5)
 
-		err := k.repo.BatchIncrViewCount(ctx_1, bizs, ids) // 批量增加文章的浏览量
+		err := k.repo.BatchIncrViewCount(ctx_1, bizs, ids, visitors) // 批量增加文章的浏览量
 
 		if err != nil {
 			fmt.Println("批量处理消息失败:", err)
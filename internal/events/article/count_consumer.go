@@ -0,0 +1,134 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/IBM/sarama"
+)
+
+// InteractionCountConsumer 消费点赞量、收藏量变化事件
+// 在内存中按业务对象聚合增量，定期批量写入数据库，热门文章的计数行不再被每次点赞争抢
+type InteractionCountConsumer struct {
+	client        sarama.Client
+	repo          repository.InteractionRepositoryInterface
+	batchSize     int           // 聚合的消息数达到批量大小时立即写入
+	flushInterval time.Duration // 最长多久写入一次
+}
+
+func NewInteractionCountConsumer(client sarama.Client, repo repository.InteractionRepositoryInterface) *InteractionCountConsumer {
+	return &InteractionCountConsumer{
+		client:        client,
+		repo:          repo,
+		batchSize:     500,
+		flushInterval: time.Second * 2,
+	}
+}
+
+// Start 启动消费者组
+func (c *InteractionCountConsumer) Start(ctx context.Context) error {
+	cg, err := sarama.NewConsumerGroupFromClient("interaction_count", c.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			if err := cg.Consume(ctx, []string{TopicInteractionCount}, c); err != nil {
+				log.Printf("计数事件消费错误: %v，将在5秒后重试", err)
+				time.Sleep(time.Second * 5)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *InteractionCountConsumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *InteractionCountConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+type countKey struct {
+	biz   string
+	bizID int64
+}
+
+// ConsumeClaim 聚合一个分区的计数事件，写入成功后才提交位移
+// 写入失败时保留已聚合的增量，下次刷新时和新的增量一起重试
+func (c *InteractionCountConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	deltas := make(map[countKey]*domain.InteractionDelta)
+	var last *sarama.ConsumerMessage // 已聚合的最后一条消息
+	pending := 0                     // 还没有写入的消息数
+
+	flush := func() {
+		if pending == 0 {
+			return
+		}
+		if err := c.flush(deltas); err != nil {
+			log.Printf("批量写入点赞量、收藏量失败, 待写入消息数: %d: %v", pending, err)
+			return
+		}
+		// 同一个分区的位移是递增的，标记最后一条即可
+		session.MarkMessage(last, "")
+		deltas = make(map[countKey]*domain.InteractionDelta)
+		pending = 0
+	}
+
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				// 分区被回收前写入已经聚合的增量
+				flush()
+				return nil
+			}
+			last = msg
+			pending++
+
+			var event CountEvent
+			if err := json.Unmarshal(msg.Value, &event); err != nil {
+				log.Println("解析计数事件失败:", err)
+			} else {
+				key := countKey{biz: event.Biz, bizID: event.BizID}
+				d, ok := deltas[key]
+				if !ok {
+					d = &domain.InteractionDelta{Biz: event.Biz, BizID: event.BizID}
+					deltas[key] = d
+				}
+				d.LikeCnt += event.LikeDelta
+				d.CollectCnt += event.CollectDelta
+			}
+
+			if pending%c.batchSize == 0 {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-session.Context().Done():
+			flush()
+			return nil
+		}
+	}
+}
+
+func (c *InteractionCountConsumer) flush(deltas map[countKey]*domain.InteractionDelta) error {
+	batch := make([]domain.InteractionDelta, 0, len(deltas))
+	for _, d := range deltas {
+		batch = append(batch, *d)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	return c.repo.BatchIncrCounts(ctx, batch)
+}
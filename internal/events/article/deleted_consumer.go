@@ -0,0 +1,84 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// DeletedHandler 处理文章删除事件，负责清理文章的关联数据
+type DeletedHandler interface {
+	HandleArticleDeleted(ctx context.Context, event DeletedEvent) error
+}
+
+// DeletedConsumer 消费文章删除事件
+type DeletedConsumer struct {
+	client     sarama.Client
+	handler    DeletedHandler
+	maxRetries int // 处理失败时的最大重试次数
+}
+
+func NewDeletedConsumer(client sarama.Client, handler DeletedHandler) *DeletedConsumer {
+	return &DeletedConsumer{
+		client:     client,
+		handler:    handler,
+		maxRetries: 3,
+	}
+}
+
+// Start 启动消费者组
+func (dc *DeletedConsumer) Start(ctx context.Context) error {
+	cg, err := sarama.NewConsumerGroupFromClient("article_deleted", dc.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			if err := cg.Consume(ctx, []string{"article_deleted"}, dc); err != nil {
+				log.Printf("文章删除事件消费错误: %v，将在5秒后重试", err)
+				time.Sleep(time.Second * 5)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (dc *DeletedConsumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (dc *DeletedConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim 逐条处理删除事件，清理的每一步都是幂等的，失败时整条事件重试
+func (dc *DeletedConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var event DeletedEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			log.Println("解析文章删除事件失败:", err)
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		for i := 0; i < dc.maxRetries; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			err := dc.handler.HandleArticleDeleted(ctx, event)
+			cancel()
+			if err == nil {
+				break
+			}
+			log.Printf("清理已删除文章失败, 文章ID: %d, 第%d次: %v", event.Aid, i+1, err)
+			time.Sleep(time.Second * time.Duration(i+1))
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
@@ -4,21 +4,55 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/IBM/sarama"
 )
 
 type Producer interface {
 	ProducerViewEvent(ctx context.Context, event ViewEvent) error
+	ProduceDeletedEvent(ctx context.Context, event DeletedEvent) error
+	// ProduceVisibilityEvent 发送文章可见性变化事件
+	ProduceVisibilityEvent(ctx context.Context, event VisibilityEvent) error
+	// ProduceCountEvent 发送点赞量、收藏量变化事件，同一业务对象的事件发往同一个分区
+	ProduceCountEvent(ctx context.Context, event CountEvent) error
 }
 
+// TopicInteractionCount 点赞量、收藏量变化事件的主题
+const TopicInteractionCount = "interaction_count"
+
 type KafkaProducer struct {
 	producer sarama.SyncProducer
 }
 
 type ViewEvent struct {
-	Uid int64 // 用户ID
-	Aid int64 // 文章ID
+	Uid     int64  // 用户ID，未登录时为 0
+	Aid     int64  // 文章ID
+	Visitor string // 访客标识，登录用户按用户ID，未登录的按设备，用于统计独立浏览量
+}
+
+// DeletedEvent 文章删除事件，消费者据此清理文章的关联数据
+type DeletedEvent struct {
+	Aid       int64    // 文章ID
+	Uid       int64    // 作者ID
+	ImgUrls   []string // 文章引用的图片
+	Permanent bool     // 是否彻底删除，软删除只下线文章，保留评论、互动数据和图片
+}
+
+// VisibilityEvent 文章可见性变化事件，文章设为私密后消费者把文章从公开渠道下线
+type VisibilityEvent struct {
+	Aid     int64 // 文章ID
+	Uid     int64 // 作者ID
+	Private bool  // 是否设为私密
+}
+
+// CountEvent 点赞、取消点赞、收藏、取消收藏引起的计数变化
+// 消费者按业务对象聚合后批量写入数据库
+type CountEvent struct {
+	Biz          string
+	BizID        int64
+	LikeDelta    int64 // 点赞量的变化，取消点赞为 -1
+	CollectDelta int64 // 收藏量的变化，取消收藏为 -1
 }
 
 func NewKafkaProducer(pc sarama.SyncProducer) Producer {
@@ -42,3 +76,43 @@ func (kp *KafkaProducer) ProducerViewEvent(ctx context.Context, event ViewEvent)
 	})
 	return err
 }
+
+func (kp *KafkaProducer) ProduceDeletedEvent(ctx context.Context, event DeletedEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// 发送消息到Kafka主题，主题名称为"article_deleted"
+	_, _, err = kp.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "article_deleted",
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
+
+func (kp *KafkaProducer) ProduceVisibilityEvent(ctx context.Context, event VisibilityEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, _, err = kp.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: "article_visibility",
+		Key:   sarama.StringEncoder(strconv.FormatInt(event.Aid, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
+
+func (kp *KafkaProducer) ProduceCountEvent(ctx context.Context, event CountEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// 以业务对象作为消息的 key，同一篇文章的计数在同一个分区里聚合
+	_, _, err = kp.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicInteractionCount,
+		Key:   sarama.StringEncoder(event.Biz + ":" + strconv.FormatInt(event.BizID, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
@@ -0,0 +1,84 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// VisibilityHandler 处理文章可见性变化事件，文章设为私密后把文章从公开渠道下线
+type VisibilityHandler interface {
+	HandleArticleVisibility(ctx context.Context, event VisibilityEvent) error
+}
+
+// VisibilityConsumer 消费文章可见性变化事件
+type VisibilityConsumer struct {
+	client     sarama.Client
+	handler    VisibilityHandler
+	maxRetries int // 处理失败时的最大重试次数
+}
+
+func NewVisibilityConsumer(client sarama.Client, handler VisibilityHandler) *VisibilityConsumer {
+	return &VisibilityConsumer{
+		client:     client,
+		handler:    handler,
+		maxRetries: 3,
+	}
+}
+
+// Start 启动消费者组
+func (vc *VisibilityConsumer) Start(ctx context.Context) error {
+	cg, err := sarama.NewConsumerGroupFromClient("article_visibility", vc.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			if err := cg.Consume(ctx, []string{"article_visibility"}, vc); err != nil {
+				log.Printf("文章可见性事件消费错误: %v，将在5秒后重试", err)
+				time.Sleep(time.Second * 5)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (vc *VisibilityConsumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (vc *VisibilityConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim 逐条处理可见性事件，下线的每一步都是幂等的，失败时整条事件重试
+func (vc *VisibilityConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var event VisibilityEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			log.Println("解析文章可见性事件失败:", err)
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		for i := 0; i < vc.maxRetries; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			err := vc.handler.HandleArticleVisibility(ctx, event)
+			cancel()
+			if err == nil {
+				break
+			}
+			log.Printf("下线私密文章失败, 文章ID: %d, 第%d次: %v", event.Aid, i+1, err)
+			time.Sleep(time.Second * time.Duration(i+1))
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package comment
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// EngagementHandler 处理评论互动事件
+type EngagementHandler interface {
+	HandleCommentEngagement(ctx context.Context, event EngagementEvent) error
+}
+
+// EngagementConsumer 消费评论互动事件
+type EngagementConsumer struct {
+	client     sarama.Client
+	handler    EngagementHandler
+	maxRetries int // 处理失败时的最大重试次数
+}
+
+func NewEngagementConsumer(client sarama.Client, handler EngagementHandler) *EngagementConsumer {
+	return &EngagementConsumer{
+		client:     client,
+		handler:    handler,
+		maxRetries: 3,
+	}
+}
+
+// Start 启动消费者组
+func (ec *EngagementConsumer) Start(ctx context.Context) error {
+	cg, err := sarama.NewConsumerGroupFromClient(topicEngagement, ec.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			if err := cg.Consume(ctx, []string{topicEngagement}, ec); err != nil {
+				log.Printf("评论互动事件消费错误: %v，将在5秒后重试", err)
+				time.Sleep(time.Second * 5)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (ec *EngagementConsumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (ec *EngagementConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim 逐条处理互动事件，失败时重试，重试仍失败则丢弃，排行榜过期后会从数据库重建
+func (ec *EngagementConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var event EngagementEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			log.Println("解析评论互动事件失败:", err)
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		for i := 0; i < ec.maxRetries; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			err := ec.handler.HandleCommentEngagement(ctx, event)
+			cancel()
+			if err == nil {
+				break
+			}
+			log.Printf("更新热门评论排行失败, 评论ID: %d, 第%d次: %v", event.CommentID, i+1, err)
+			time.Sleep(time.Millisecond * 100 * time.Duration(i+1))
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package comment
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+const topicEngagement = "comment_engagement"
+
+// 评论互动事件类型
+const (
+	EngagementCreated = "created" // 发布了新的根评论
+	EngagementReplied = "replied" // 根评论下有了新的回复
+	EngagementLiked   = "liked"   // 根评论被点赞或取消点赞
+)
+
+// EngagementEvent 根评论的互动事件，消费者据此增量更新热门评论排行
+type EngagementEvent struct {
+	Type      string
+	Biz       string
+	BizID     int64
+	CommentID int64 // 根评论ID
+	Delta     int64 // 点赞或回复的变化量，取消点赞为 -1
+	Ctime     int64 // 新评论的创建时间（秒），只有 created 事件需要
+}
+
+type Producer interface {
+	ProduceEngagementEvent(ctx context.Context, event EngagementEvent) error
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(pc sarama.SyncProducer) Producer {
+	return &KafkaProducer{
+		producer: pc,
+	}
+}
+
+func (kp *KafkaProducer) ProduceEngagementEvent(ctx context.Context, event EngagementEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// 同一篇文章的事件使用相同的 key，落在同一个分区里按顺序消费
+	_, _, err = kp.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: topicEngagement,
+		Key:   sarama.StringEncoder(event.Biz + ":" + strconv.FormatInt(event.BizID, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
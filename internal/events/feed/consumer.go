@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/events/push"
 	"github.com/Fairy-nn/inspora/internal/repository"
 	"github.com/IBM/sarama"
 )
@@ -34,6 +35,7 @@ type KafkaFeedConsumer struct {
 	followRepo  repository.FollowRepository
 	articleRepo repository.ArticleRepository
 	userRepo    repository.UserRepositoryInterface
+	pusher      push.Publisher // 把新的 Feed 推送给在线的粉丝
 }
 
 // NewKafkaFeedConsumer 创建一个新的 KafkaFeedConsumer
@@ -43,6 +45,7 @@ func NewKafkaFeedConsumer(
 	followRepo repository.FollowRepository,
 	articleRepo repository.ArticleRepository,
 	userRepo repository.UserRepositoryInterface,
+	pusher push.Publisher,
 ) Consumer {
 	consumerGroup, err := sarama.NewConsumerGroupFromClient(ConsumerGroupID, client)
 	if err != nil {
@@ -55,6 +58,7 @@ func NewKafkaFeedConsumer(
 		followRepo:  followRepo,
 		articleRepo: articleRepo,
 		userRepo:    userRepo,
+		pusher:      pusher,
 	}
 }
 
@@ -169,6 +173,16 @@ func (k *KafkaFeedConsumer) processFeedEvent(ctx context.Context, msg *sarama.Co
 		}
 		return err
 
+	case EventTypeArticleDeleted:
+		log.Printf("开始处理文章删除事件: ID=%d", event.ID)
+		err := k.handleArticleDeleted(ctx, event)
+		if err != nil {
+			log.Printf("文章删除事件处理失败: %v", err)
+		} else {
+			log.Printf("文章删除事件处理成功: ID=%d", event.ID)
+		}
+		return err
+
 	default:
 		// 未知事件类型，忽略
 		log.Printf("收到未知事件类型: %s, 已忽略", event.EventType)
@@ -247,12 +261,22 @@ func (k *KafkaFeedConsumer) handleArticlePublished(ctx context.Context, event do
 
 		// 批量推送到粉丝的收件箱
 		successCount := 0
+		msgs := make([]push.Message, 0, len(followers))
 		for _, follower := range followers {
 			if err := k.feedRepo.AddToInbox(ctx, follower.Follower, item); err != nil {
 				log.Printf("推送到用户 %d 的收件箱失败: %v", follower.Follower, err)
 				continue
 			}
 			successCount++
+			if msg, err := push.NewMessage(follower.Follower, push.TypeFeed, item); err == nil {
+				msgs = append(msgs, msg)
+			}
+		}
+		// 实时推送只是提醒，失败时粉丝仍然可以拉取收件箱
+		if k.pusher != nil {
+			if err := k.pusher.Publish(ctx, msgs...); err != nil {
+				log.Printf("实时推送 Feed 失败: %v", err)
+			}
 		}
 
 		totalFanoutCount += successCount
@@ -402,3 +426,46 @@ func (k *KafkaFeedConsumer) handleArticleCollected(ctx context.Context, event do
 
 	return nil
 }
+
+// handleArticleDeleted 处理文章删除事件，从作者的发件箱和收件箱以及所有粉丝的收件箱中移除文章
+// 文章发布后粉丝数量可能发生变化，这里不区分大V，所有粉丝都会清理，移除是幂等的
+func (k *KafkaFeedConsumer) handleArticleDeleted(ctx context.Context, event domain.FeedEvent) error {
+	authorID := event.UserID
+	articleID, ok := event.Content["article_id"].(float64)
+	if !ok {
+		log.Printf("文章删除事件格式不正确: article_id 不存在或格式错误")
+		return nil // 跳过格式不正确的事件
+	}
+
+	if err := k.feedRepo.RemoveArticleItems(ctx, authorID, int64(articleID)); err != nil {
+		log.Printf("从作者(ID=%d)的 Feed 中移除文章失败: %v", authorID, err)
+		return err
+	}
+
+	offset := int64(0)
+	limit := int64(MaxFanoutBatchSize)
+	totalRemoved := 0
+	for {
+		followers, err := k.followRepo.GetFollowerList(ctx, authorID, offset, limit)
+		if err != nil {
+			log.Printf("获取粉丝列表失败: %v", err)
+			return err
+		}
+
+		for _, follower := range followers {
+			if err := k.feedRepo.RemoveArticleItems(ctx, follower.Follower, int64(articleID)); err != nil {
+				log.Printf("从用户 %d 的收件箱移除文章失败: %v", follower.Follower, err)
+				return err
+			}
+		}
+		totalRemoved += len(followers)
+
+		if len(followers) < int(limit) {
+			break // 没有更多粉丝了
+		}
+		offset += limit
+	}
+
+	log.Printf("文章删除事件处理完成，共从 %d 名粉丝的收件箱中移除", totalRemoved)
+	return nil
+}
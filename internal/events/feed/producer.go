@@ -22,6 +22,7 @@ const (
 	EventTypeArticleCommented = "article_commented" // 文章评论事件
 	EventTypeArticleCollected = "article_collected" // 文章收藏事件
 	EventTypeUserFollowed     = "user_followed"     // 用户关注事件
+	EventTypeArticleDeleted   = "article_deleted"   // 文章删除事件，从作者和粉丝的 Feed 中移除文章
 )
 
 // Producer 定义 Feed 事件生产者接口
@@ -36,6 +37,7 @@ type Producer interface {
 	ProduceUserFollowedEvent(ctx context.Context, followerID, followeeID int64) error
 	ProduceArticleCommentedEvent(ctx context.Context, userID, articleID, authorID, commentID int64, commentContent string) error
 	ProduceArticleCollectedEvent(ctx context.Context, userID, articleID, authorID int64) error
+	ProduceArticleDeletedEvent(ctx context.Context, authorID, articleID int64) error
 }
 
 // KafkaProducer 基于 Kafka 实现的 Producer
@@ -192,3 +194,20 @@ func (k *KafkaProducer) ProduceArticleCollectedEvent(ctx context.Context, userID
 
 	return k.ProduceFeedEvent(ctx, event)
 }
+
+// ProduceArticleDeletedEvent 发布文章删除事件，消费者从作者的发件箱和所有粉丝的收件箱中移除这篇文章
+func (k *KafkaProducer) ProduceArticleDeletedEvent(ctx context.Context, authorID, articleID int64) error {
+	log.Printf("生产文章删除事件: 作者ID=%d, 文章ID=%d", authorID, articleID)
+
+	event := domain.FeedEvent{
+		UserID:    authorID,                // 事件发起者为文章作者
+		EventType: EventTypeArticleDeleted, // 事件类型为文章删除
+		Content: map[string]interface{}{
+			"article_id": articleID, // 被删除的文章ID
+			"author_id":  authorID,  // 作者ID（与userID一致）
+		},
+		Ctime: time.Now(),
+	}
+
+	return k.ProduceFeedEvent(ctx, event)
+}
@@ -0,0 +1,149 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/events/feed"
+	"github.com/IBM/sarama"
+)
+
+const consumerGroupID = "notification_consumer_group"
+
+// Handler 处理通知事件
+type Handler interface {
+	HandleNotification(ctx context.Context, event Event) error
+}
+
+// Consumer 消费 Feed 事件和通知事件，为相关用户生成站内通知
+// Feed 事件中点赞、评论、收藏和关注的对象就是需要通知的用户
+type Consumer struct {
+	client     sarama.Client
+	handler    Handler
+	maxRetries int // 处理失败时的最大重试次数
+}
+
+func NewConsumer(client sarama.Client, handler Handler) *Consumer {
+	return &Consumer{
+		client:     client,
+		handler:    handler,
+		maxRetries: 3,
+	}
+}
+
+// Start 启动消费者组
+func (c *Consumer) Start(ctx context.Context) error {
+	cg, err := sarama.NewConsumerGroupFromClient(consumerGroupID, c.client)
+	if err != nil {
+		return err
+	}
+
+	topics := []string{feed.FeedTopic, TopicNotification}
+	go func() {
+		for {
+			if err := cg.Consume(ctx, topics, c); err != nil {
+				log.Printf("通知事件消费错误: %v，将在5秒后重试", err)
+				time.Sleep(time.Second * 5)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim 逐条处理事件，失败时重试，重试仍失败则丢弃
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		event, ok, err := c.decode(msg)
+		if err != nil {
+			log.Printf("解析通知事件失败, 主题: %s, %v", msg.Topic, err)
+		}
+		if !ok {
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		for i := 0; i < c.maxRetries; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			err := c.handler.HandleNotification(ctx, event)
+			cancel()
+			if err == nil {
+				break
+			}
+			log.Printf("保存通知失败, 用户ID: %d, 类型: %s, 第%d次: %v", event.UserID, event.Type, i+1, err)
+			time.Sleep(time.Millisecond * 100 * time.Duration(i+1))
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// decode 解析消息，不需要通知的 Feed 事件返回 false
+func (c *Consumer) decode(msg *sarama.ConsumerMessage) (Event, bool, error) {
+	if msg.Topic != feed.FeedTopic {
+		var event Event
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			return Event{}, false, err
+		}
+		return event, true, nil
+	}
+
+	var fe domain.FeedEvent
+	if err := json.Unmarshal(msg.Value, &fe); err != nil {
+		return Event{}, false, err
+	}
+	event, ok := fromFeedEvent(fe)
+	return event, ok, nil
+}
+
+// fromFeedEvent 把 Feed 事件转换为通知事件，JSON 中的数字解析为 float64
+func fromFeedEvent(fe domain.FeedEvent) (Event, bool) {
+	id := func(key string) int64 {
+		v, _ := fe.Content[key].(float64)
+		return int64(v)
+	}
+	event := Event{
+		ActorID: fe.UserID,
+		Ctime:   fe.Ctime.UnixMilli(),
+	}
+	switch fe.EventType {
+	case feed.EventTypeArticleLiked, feed.EventTypeArticleCollected:
+		event.Type = TypeLike
+		if fe.EventType == feed.EventTypeArticleCollected {
+			event.Type = TypeCollect
+		}
+		event.UserID = id("author_id")
+		event.Biz = "article"
+		event.BizID = id("article_id")
+		event.ArticleID = event.BizID
+	case feed.EventTypeArticleCommented:
+		event.Type = TypeComment
+		event.UserID = id("author_id")
+		event.Biz = "comment"
+		event.BizID = id("comment_id")
+		event.ArticleID = id("article_id")
+		event.Content, _ = fe.Content["comment_content"].(string)
+	case feed.EventTypeUserFollowed:
+		event.Type = TypeFollow
+		event.UserID = id("followee_id")
+		event.Biz = "user"
+		event.BizID = event.UserID
+	default:
+		// 文章发布只进入粉丝的 Feed，不产生通知
+		return Event{}, false
+	}
+	return event, true
+}
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/IBM/sarama"
+)
+
+const TopicNotification = "notification_events"
+
+// 通知事件类型，点赞、评论、收藏和关注由 Feed 事件转换而来
+const (
+	TypeLike    = domain.NotificationLike
+	TypeComment = domain.NotificationComment
+	TypeCollect = domain.NotificationCollect
+	TypeFollow  = domain.NotificationFollow
+	TypeMention = domain.NotificationMention
+	TypeReward  = domain.NotificationReward
+)
+
+// Event 需要直接通知某个用户、但不进入 Feed 的事件
+type Event struct {
+	Type      string
+	UserID    int64  // 接收通知的用户
+	ActorID   int64  // 触发通知的用户
+	Biz       string // 事件关联的对象，article、comment 或 user
+	BizID     int64
+	ArticleID int64  // 所属文章，评论中的提及为评论所在的文章
+	Content   string // 内容摘要
+	Amount    int64  // 打赏金额，单位为分
+	Ctime     int64  // 毫秒
+}
+
+type Producer interface {
+	ProduceEvent(ctx context.Context, event Event) error
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(pc sarama.SyncProducer) Producer {
+	return &KafkaProducer{
+		producer: pc,
+	}
+}
+
+func (kp *KafkaProducer) ProduceEvent(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// 同一个用户的通知落在同一个分区，保证顺序
+	_, _, err = kp.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicNotification,
+		Key:   sarama.StringEncoder(strconv.FormatInt(event.UserID, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
@@ -0,0 +1,150 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 所有实例订阅同一个频道，各自只推送给连接在本实例上的用户
+const channel = "push:messages"
+
+// 每个连接缓冲的消息数，客户端读取太慢时丢弃新消息，客户端可以重新拉取
+const connBufferSize = 16
+
+// 推送的消息类型，同时也是 SSE 的事件名
+const (
+	TypeNotification = "notification" // 新的站内通知
+	TypeFeed         = "feed"         // 收件箱中新的 Feed
+	TypeMessage      = "message"      // 新的私信
+)
+
+// Message 推送给某个用户的消息
+type Message struct {
+	UserID int64           `json:"user_id"`
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// NewMessage 把 data 序列化为 JSON 生成消息
+func NewMessage(uid int64, typ string, data any) (Message, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{UserID: uid, Type: typ, Data: raw}, nil
+}
+
+// Publisher 向在线用户推送消息，用户不在线时消息直接丢弃
+type Publisher interface {
+	Publish(ctx context.Context, msgs ...Message) error
+}
+
+// Subscriber 订阅推送给某个用户的消息，用户可以同时有多个连接
+type Subscriber interface {
+	// Subscribe 返回消息通道和取消订阅的函数，连接断开时必须取消订阅
+	Subscribe(uid int64) (<-chan Message, func())
+}
+
+// Hub 通过 Redis 发布订阅在多个实例之间转发消息
+type Hub struct {
+	client redis.UniversalClient
+
+	mu    sync.RWMutex
+	conns map[int64]map[chan Message]struct{}
+}
+
+func NewHub(client redis.UniversalClient) *Hub {
+	return &Hub{
+		client: client,
+		conns:  make(map[int64]map[chan Message]struct{}),
+	}
+}
+
+// Publish 把消息发布到 Redis，多条消息使用一次 pipeline 发送
+func (h *Hub) Publish(ctx context.Context, msgs ...Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	_, err := h.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, msg := range msgs {
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return err
+			}
+			pipe.Publish(ctx, channel, data)
+		}
+		return nil
+	})
+	return err
+}
+
+func (h *Hub) Subscribe(uid int64) (<-chan Message, func()) {
+	ch := make(chan Message, connBufferSize)
+	h.mu.Lock()
+	if h.conns[uid] == nil {
+		h.conns[uid] = make(map[chan Message]struct{})
+	}
+	h.conns[uid][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.conns[uid], ch)
+			if len(h.conns[uid]) == 0 {
+				delete(h.conns, uid)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// Start 订阅 Redis 频道，把消息转发给本实例上的连接
+// 和 Kafka 消费者一样随应用启动，连接断开后 go-redis 会自动重新订阅
+func (h *Hub) Start(ctx context.Context) error {
+	ps := h.client.Subscribe(ctx, channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return err
+	}
+
+	go func() {
+		defer ps.Close()
+		ch := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-ch:
+				if !ok {
+					return
+				}
+				var msg Message
+				if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+					log.Println("解析推送消息失败:", err)
+					continue
+				}
+				h.dispatch(msg)
+			}
+		}
+	}()
+	return nil
+}
+
+// dispatch 把消息交给用户在本实例上的所有连接，连接的缓冲区满了就丢弃
+func (h *Hub) dispatch(msg Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.conns[msg.UserID] {
+		select {
+		case ch <- msg:
+		default:
+			log.Printf("推送消息被丢弃, 用户ID: %d, 类型: %s", msg.UserID, msg.Type)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/service"
+)
+
+// ScheduledPublishJob 定时发布文章任务
+type ScheduledPublishJob struct {
+	svc     service.ArticleServiceInterface
+	timeout time.Duration
+	batch   int // 每次最多发布的文章数量
+}
+
+func NewScheduledPublishJob(svc service.ArticleServiceInterface) *ScheduledPublishJob {
+	return &ScheduledPublishJob{
+		svc:     svc,
+		timeout: time.Minute,
+		batch:   100,
+	}
+}
+
+func (s *ScheduledPublishJob) Name() string {
+	return "Scheduled Publish Job"
+}
+
+func (s *ScheduledPublishJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	// 发布所有发布时间早于当前时间的文章，多实例下由服务层保证每篇文章只发布一次
+	published, err := s.svc.PublishScheduled(ctx, startTime, s.batch)
+	duration := time.Since(startTime)
+	if err != nil {
+		println("【定时任务】定时发布任务执行失败 -", err.Error(), "- 耗时:", duration.String())
+		return err
+	}
+
+	if published > 0 {
+		println("【定时任务】定时发布任务执行成功 - 发布文章数:", published, "- 耗时:", duration.String())
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/service"
+)
+
+// UploadCleanupJob 清理长时间没有被文章引用的上传文件，以及过期的附件分片上传任务
+type UploadCleanupJob struct {
+	svc           service.OSSServiceInterface
+	attachmentSvc service.AttachmentServiceInterface
+	grace         time.Duration // 上传或解除引用之后保留的时间，给作者留出编辑文章的时间
+	timeout       time.Duration
+	batch         int // 每次最多检查的文件数量
+}
+
+func NewUploadCleanupJob(svc service.OSSServiceInterface, attachmentSvc service.AttachmentServiceInterface,
+	grace time.Duration) *UploadCleanupJob {
+	return &UploadCleanupJob{
+		svc:           svc,
+		attachmentSvc: attachmentSvc,
+		grace:         grace,
+		timeout:       5 * time.Minute,
+		batch:         500,
+	}
+}
+
+func (u *UploadCleanupJob) Name() string {
+	return "Upload Cleanup Job"
+}
+
+func (u *UploadCleanupJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), u.timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	deleted, err := u.svc.CleanupOrphans(ctx, u.grace, u.batch)
+	duration := time.Since(startTime)
+	if err != nil {
+		println("【定时任务】上传文件清理任务执行失败 -", err.Error(), "- 耗时:", duration.String())
+		return err
+	}
+
+	if deleted > 0 {
+		println("【定时任务】上传文件清理任务执行成功 - 删除文件数:", deleted, "- 耗时:", duration.String())
+	}
+
+	startTime = time.Now()
+	expired, err := u.attachmentSvc.CleanupExpired(ctx, startTime, u.batch)
+	duration = time.Since(startTime)
+	if err != nil {
+		println("【定时任务】分片上传清理任务执行失败 -", err.Error(), "- 耗时:", duration.String())
+		return err
+	}
+	if expired > 0 {
+		println("【定时任务】分片上传清理任务执行成功 - 清理任务数:", expired, "- 耗时:", duration.String())
+	}
+	return nil
+}
@@ -11,6 +11,13 @@ import (
 	"github.com/Fairy-nn/inspora/internal/repository/dao"
 )
 
+var (
+	// ErrArticleNotFound 文章不存在
+	ErrArticleNotFound = dao.ErrNotFound
+	// ErrArticleVersionConflict 文章已被其他人修改
+	ErrArticleVersionConflict = dao.ErrVersionConflict
+)
+
 type ArticleRepository interface {
 	// Create 创建文章
 	Create(ctx context.Context, article domain.Article) (int64, error)
@@ -20,14 +27,38 @@ type ArticleRepository interface {
 	Sync(ctx context.Context, article domain.Article) (int64, error)
 	// SyncStatus 同步文章状态
 	SyncStatus(ctx context.Context, articleID, authorID int64, status domain.ArticleStatus) error
-	// List 获取文章列表
-	List(ctx context.Context, userID int64, limit int, offset int) ([]domain.Article, error)
+	// SetPublishedStatus 只修改线上库中文章的状态，返回 false 表示文章当前的状态不是 from
+	SetPublishedStatus(ctx context.Context, articleID, authorID int64, from, to domain.ArticleStatus) (bool, error)
+	// List 获取作者的文章列表，按更新时间倒序，从游标之后开始
+	List(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
 	// FindById 根据ID获取文章
 	FindById(ctx context.Context, id, uid int64) (domain.Article, error)
+	// GetByID 根据ID获取制作库中的文章，不校验作者
+	GetByID(ctx context.Context, id int64) (domain.Article, error)
+	// ListByCollaborator 获取用户参与协作的文章列表，按更新时间倒序，从游标之后开始
+	ListByCollaborator(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
 	// FindPublicArticleById 根据ID获取公开文章
 	FindPublicArticleById(ctx context.Context, id int64) (domain.Article, error)
-	// ListPublic 获取公开文章列表
-	ListPublic(ctx context.Context, startTime time.Time, offset, limit int) ([]domain.Article, error)
+	// ListPublic 获取已发布的文章列表，按更新时间倒序，从游标之后开始
+	ListPublic(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+	// FindDueScheduled 获取已到发布时间的定时发布文章
+	FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error)
+	// SyncScheduled 发布仍处于定时发布状态的文章，返回 false 表示文章已被其他实例发布或者已被作者修改
+	SyncScheduled(ctx context.Context, article domain.Article) (bool, error)
+	// ListPublicByAuthor 获取作者信息和作者已发布的文章列表，第一页有缓存
+	ListPublicByAuthor(ctx context.Context, authorID int64, cursor domain.ArticleCursor, limit int) (domain.Author, []domain.Article, error)
+	// ListPublicByTag 根据标签获取已发布的文章列表
+	ListPublicByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, error)
+	// FindPublicByIds 批量获取已发布的文章，不保证返回顺序
+	FindPublicByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	// Delete 彻底删除作者的文章，线上库中的文章先标记为已删除
+	Delete(ctx context.Context, articleID, authorID int64) error
+	// DeletePublished 删除线上库中的文章以及文章相关的缓存
+	DeletePublished(ctx context.Context, articleID, authorID int64) error
+	// ClearCache 删除文章详情和作者文章列表的缓存
+	ClearCache(ctx context.Context, articleID, authorID int64) error
+	// ImgUrlInUse 判断除 excludeID 外是否还有文章引用了该图片
+	ImgUrlInUse(ctx context.Context, url string, excludeID int64) (bool, error)
 }
 
 type CachedArticleRepository struct {
@@ -42,7 +73,26 @@ func NewCachedArticleRepository(dao dao.ArticleDaoInterface, cache cache.Article
 
 // SyncStatus 同步文章状态
 func (c *CachedArticleRepository) SyncStatus(ctx context.Context, articleID, authorID int64, status domain.ArticleStatus) error {
-	return c.dao.SyncStatus(ctx, articleID, authorID, status.ToUint8())
+	err := c.dao.SyncStatus(ctx, articleID, authorID, status.ToUint8())
+	if err == nil {
+		// 撤回或删除后文章详情和作者主页中都不再展示这篇文章
+		if err := c.ClearCache(ctx, articleID, authorID); err != nil {
+			fmt.Println("删除文章缓存失败", err)
+		}
+	}
+	return err
+}
+
+// SetPublishedStatus 只修改线上库中文章的状态，修改成功后删除文章详情和作者文章列表的缓存
+func (c *CachedArticleRepository) SetPublishedStatus(ctx context.Context, articleID, authorID int64, from, to domain.ArticleStatus) (bool, error) {
+	ok, err := c.dao.SetPublishedStatus(ctx, articleID, authorID, from.ToUint8(), to.ToUint8())
+	if err != nil || !ok {
+		return ok, err
+	}
+	if err := c.ClearCache(ctx, articleID, authorID); err != nil {
+		fmt.Println("删除文章缓存失败", err)
+	}
+	return true, nil
 }
 
 // Create 创建文章
@@ -61,11 +111,15 @@ func (c *CachedArticleRepository) Create(ctx context.Context, article domain.Art
 	}()
 
 	return c.dao.Insert(ctx, &dao.Article{
-		Title:    article.Title,
-		Content:  article.Content,
-		AuthorID: article.Author.ID,
-		Status:   article.Status.ToUint8(),
-		ImgUrls:  string(imageURLsJSON),
+		Title:       article.Title,
+		Content:     article.Content,
+		Format:      article.Format.ToUint8(),
+		ContentHTML: article.HTML,
+		AuthorID:    article.Author.ID,
+		Status:      article.Status.ToUint8(),
+		ImgUrls:     string(imageURLsJSON),
+		PublishAt:   toPublishAt(article.PublishAt),
+		Tags:        toTagsJSON(article.Tags),
 	})
 }
 
@@ -76,26 +130,28 @@ func (c *CachedArticleRepository) Update(ctx context.Context, article domain.Art
 	if err != nil {
 		return fmt.Errorf("转换图片地址失败: %w", err)
 	}
-	defer func() {
-		err := c.cache.DelFirstPage(ctx, article.Author.ID)
-		if err != nil {
-			fmt.Println("删除缓存失败", err)
-		}
-
-		err = c.cache.SetPub(ctx, article)
-		if err != nil {
-			fmt.Println("设置公共缓存失败", err)
-		}
-	}()
-
-	return c.dao.Update(ctx, &dao.Article{
-		ID:       article.ID,
-		Title:    article.Title,
-		Content:  article.Content,
-		AuthorID: article.Author.ID,
-		Status:   article.Status.ToUint8(),
-		ImgUrls:  string(imageURLsJSON), // 图片地址
+	err = c.dao.Update(ctx, &dao.Article{
+		ID:          article.ID,
+		Title:       article.Title,
+		Content:     article.Content,
+		Format:      article.Format.ToUint8(),
+		ContentHTML: article.HTML,
+		AuthorID:    article.Author.ID,
+		Status:      article.Status.ToUint8(),
+		ImgUrls:     string(imageURLsJSON), // 图片地址
+		PublishAt:   toPublishAt(article.PublishAt),
+		Tags:        toTagsJSON(article.Tags), // 标签
+		Version:     article.Version,
 	})
+	if err != nil {
+		// 版本冲突等失败的修改没有写入，缓存保持不变
+		return err
+	}
+	// 这里修改的是制作库，线上文章的缓存只在发布时更新
+	if err := c.cache.DelFirstPage(ctx, article.Author.ID); err != nil {
+		fmt.Println("删除缓存失败", err)
+	}
+	return nil
 }
 
 // Sync 同步文章
@@ -104,25 +160,36 @@ func (c *CachedArticleRepository) Sync(ctx context.Context, article domain.Artic
 
 	// 文章发布成功后，删除缓存
 	if err == nil {
-		// 删除缓存
-		err := c.cache.DelFirstPage(ctx, article.Author.ID)
-		if err != nil {
-			fmt.Println("删除缓存失败", err)
-		}
-
-		err = c.cache.SetPub(ctx, article) // 异步设置公共缓存
-		if err != nil {
-			fmt.Println("设置公共缓存失败", err)
-		}
+		c.refreshPubCache(ctx, article)
 	}
 	return id, err
 }
 
+// refreshPubCache 文章同步到线上库后更新缓存
+func (c *CachedArticleRepository) refreshPubCache(ctx context.Context, article domain.Article) {
+	// 删除缓存
+	err := c.cache.DelFirstPage(ctx, article.Author.ID)
+	if err != nil {
+		fmt.Println("删除缓存失败", err)
+	}
+
+	err = c.cache.SetPub(ctx, article) // 异步设置公共缓存
+	if err != nil {
+		fmt.Println("设置公共缓存失败", err)
+	}
+
+	err = c.cache.DelPubFirstPage(ctx, article.Author.ID)
+	if err != nil {
+		fmt.Println("删除作者主页缓存失败", err)
+	}
+}
+
 // List 获取文章列表
-func (c *CachedArticleRepository) List(ctx context.Context, userID int64, limit int, offset int) ([]domain.Article, error) {
+func (c *CachedArticleRepository) List(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
 	// 核心是先查缓存，再查询数据库
 	// 只缓存了第一页
-	if offset == 0 && limit <= 100 {
+	firstPage := cursor.IsZero() && limit <= 100
+	if firstPage {
 		// 直接从缓存中获取
 		articles, err := c.cache.GetFirstPage(ctx, userID)
 		if err != nil {
@@ -139,12 +206,15 @@ func (c *CachedArticleRepository) List(ctx context.Context, userID int64, limit
 	}
 
 	// 从数据库中获取文章列表
-	res, err := c.dao.FindByAuthor(ctx, userID, offset, limit)
+	res, err := c.dao.FindByAuthor(ctx, userID, cursorMilli(cursor), cursor.ID, limit)
 	if err != nil {
 		return nil, err
 	}
 
 	articles := c.toDomainList(res)
+	if !firstPage {
+		return articles, nil
+	}
 
 	// 异步回写缓存
 	go func() {
@@ -167,10 +237,17 @@ func (c *CachedArticleRepository) toDomainList(articles []dao.Article) []domain.
 			ID:      a.ID,
 			Title:   a.Title,
 			Content: a.Content,
+			Format:  domain.ContentFormat(a.Format),
+			HTML:    a.ContentHTML,
 			Author:  domain.Author{ID: a.AuthorID},
 			Status:  domain.ArticleStatus(a.Status),
 			Ctime:   time.UnixMilli(a.Ctime),
 			Utime:   time.UnixMilli(a.Utime),
+			Tags:    parseTags(a.Tags),
+			Version: a.Version,
+		}
+		if a.PublishAt > 0 {
+			article.PublishAt = time.UnixMilli(a.PublishAt)
 		}
 		if a.ImgUrls != "" {
 			// 解析图片地址
@@ -206,6 +283,24 @@ func (c *CachedArticleRepository) FindById(ctx context.Context, id, uid int64) (
 	return c.toDomain(article), nil
 }
 
+// GetByID 根据ID获取制作库中的文章，不校验作者
+func (c *CachedArticleRepository) GetByID(ctx context.Context, id int64) (domain.Article, error) {
+	article, err := c.dao.GetByID(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return c.toDomain(article), nil
+}
+
+// ListByCollaborator 获取用户已接受邀请参与协作的文章列表
+func (c *CachedArticleRepository) ListByCollaborator(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	res, err := c.dao.FindByCollaborator(ctx, userID, uint8(domain.CollaboratorStatusAccepted), cursorMilli(cursor), cursor.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.toDomainList(res), nil
+}
+
 // FindPublicArticleById 根据ID获取公开文章
 func (c *CachedArticleRepository) FindPublicArticleById(ctx context.Context, id int64) (domain.Article, error) {
 	// 先从缓存中获取文章
@@ -230,10 +325,13 @@ func (c *CachedArticleRepository) FindPublicArticleById(ctx context.Context, id
 		ID:      article.ID,
 		Title:   article.Title,
 		Content: article.Content,
+		Format:  domain.ContentFormat(article.Format),
+		HTML:    article.ContentHTML,
 		Author:  domain.Author{ID: user.ID, Name: user.Name},
 		Status:  domain.ArticleStatus(article.Status),
 		Ctime:   time.UnixMilli(article.Ctime),
 		Utime:   time.UnixMilli(article.Utime),
+		Tags:    parseTags(article.Tags),
 	}
 
 	// 开启一个异步将文章信息存入缓存
@@ -247,13 +345,61 @@ func (c *CachedArticleRepository) FindPublicArticleById(ctx context.Context, id
 	return res, nil
 }
 
-// ListPublic 获取公开文章列表
-func (c *CachedArticleRepository) ListPublic(ctx context.Context, startTime time.Time, offset, limit int) ([]domain.Article, error) {
-	res, err := c.dao.ListPublic(ctx, startTime, offset, limit)
+// 作者主页第一页缓存的文章数量，limit 不超过这个值的第一页请求共用一份缓存
+const pubFirstPageSize = 50
+
+// ListPublicByAuthor 获取作者信息和作者已发布的文章列表
+func (c *CachedArticleRepository) ListPublicByAuthor(ctx context.Context, authorID int64, cursor domain.ArticleCursor, limit int) (domain.Author, []domain.Article, error) {
+	user, err := c.userRepo.GetByID(ctx, authorID)
+	if err != nil {
+		return domain.Author{}, nil, err
+	}
+	author := domain.Author{ID: user.ID, Name: user.Name}
+
+	firstPage := cursor.IsZero() && limit <= pubFirstPageSize
+	if firstPage {
+		cached, err := c.cache.GetPubFirstPage(ctx, authorID)
+		if err == nil && cached != nil {
+			return author, withAuthor(headArticles(cached, limit), author), nil
+		}
+	}
+
+	size := limit
+	if firstPage {
+		size = pubFirstPageSize
+	}
+	res, err := c.dao.ListPublicByAuthor(ctx, authorID, domain.ArticleStatusPublished.ToUint8(), cursorMilli(cursor), cursor.ID, size)
+	if err != nil {
+		return domain.Author{}, nil, err
+	}
+	articles := make([]domain.Article, 0, len(res))
+	for _, pub := range res {
+		articles = append(articles, c.toDomain(pub.Article))
+	}
+
+	if firstPage {
+		// 缓存时正文会被替换为摘要，这里缓存一份拷贝
+		page := make([]domain.Article, len(articles))
+		copy(page, articles)
+		if err := c.cache.SetPubFirstPage(ctx, authorID, page); err != nil {
+			fmt.Println("回写作者主页缓存失败", err)
+		}
+		articles = headArticles(articles, limit)
+	}
+	return author, withAuthor(articles, author), nil
+}
+
+// ListPublic 获取已发布的文章列表
+func (c *CachedArticleRepository) ListPublic(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListPublic(ctx, domain.ArticleStatusPublished.ToUint8(), cursorMilli(cursor), cursor.ID, limit)
 	if err != nil {
 		return nil, err
 	}
-	return c.toDomainList(res), nil
+	articles := make([]dao.Article, 0, len(res))
+	for _, pub := range res {
+		articles = append(articles, pub.Article)
+	}
+	return c.toDomainList(articles), nil
 }
 
 func (c *CachedArticleRepository) toDomain(a dao.Article) domain.Article {
@@ -261,10 +407,17 @@ func (c *CachedArticleRepository) toDomain(a dao.Article) domain.Article {
 		ID:      a.ID,
 		Title:   a.Title,
 		Content: a.Content,
+		Format:  domain.ContentFormat(a.Format),
+		HTML:    a.ContentHTML,
 		Author:  domain.Author{ID: a.AuthorID},
 		Status:  domain.ArticleStatus(a.Status),
 		Ctime:   time.UnixMilli(a.Ctime),
 		Utime:   time.UnixMilli(a.Utime),
+		Tags:    parseTags(a.Tags),
+		Version: a.Version,
+	}
+	if a.PublishAt > 0 {
+		article.PublishAt = time.UnixMilli(a.PublishAt)
 	}
 
 	// 解析图片地址
@@ -282,11 +435,148 @@ func (c *CachedArticleRepository) toEntity(a domain.Article) *dao.Article {
 	// 将图片地址转换为字符串
 	imageURLsJSON, _ := json.Marshal(a.ImgUrls)
 	return &dao.Article{
-		ID:       a.ID,
-		Title:    a.Title,
-		Content:  a.Content,
-		AuthorID: a.Author.ID,
-		Status:   a.Status.ToUint8(),
-		ImgUrls:  string(imageURLsJSON),
+		ID:          a.ID,
+		Title:       a.Title,
+		Content:     a.Content,
+		Format:      a.Format.ToUint8(),
+		ContentHTML: a.HTML,
+		AuthorID:    a.Author.ID,
+		Status:      a.Status.ToUint8(),
+		ImgUrls:     string(imageURLsJSON),
+		PublishAt:   toPublishAt(a.PublishAt),
+		Tags:        toTagsJSON(a.Tags),
+		Version:     a.Version,
+	}
+}
+
+// FindDueScheduled 获取已到发布时间的定时发布文章
+func (c *CachedArticleRepository) FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error) {
+	res, err := c.dao.FindDueScheduled(ctx, domain.ArticleStatusScheduled.ToUint8(), now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.toDomainList(res), nil
+}
+
+// SyncScheduled 发布仍处于定时发布状态的文章
+func (c *CachedArticleRepository) SyncScheduled(ctx context.Context, article domain.Article) (bool, error) {
+	ok, err := c.dao.SyncScheduled(ctx, c.toEntity(article), domain.ArticleStatusScheduled.ToUint8())
+	if ok {
+		c.refreshPubCache(ctx, article)
+	}
+	return ok, err
+}
+
+// ListPublicByTag 根据标签获取已发布的文章列表
+func (c *CachedArticleRepository) ListPublicByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListPublicByTag(ctx, tag, domain.ArticleStatusPublished.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	articles := make([]dao.Article, 0, len(res))
+	for _, pub := range res {
+		articles = append(articles, pub.Article)
+	}
+	return c.toDomainList(articles), nil
+}
+
+// FindPublicByIds 批量获取已发布的文章，不保证返回顺序
+func (c *CachedArticleRepository) FindPublicByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	res, err := c.dao.FindPublicByIds(ctx, ids, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return nil, err
+	}
+	articles := make([]dao.Article, 0, len(res))
+	for _, pub := range res {
+		articles = append(articles, pub.Article)
+	}
+	return c.toDomainList(articles), nil
+}
+
+// Delete 彻底删除作者的文章，线上库中的文章先标记为已删除
+func (c *CachedArticleRepository) Delete(ctx context.Context, articleID, authorID int64) error {
+	err := c.dao.Delete(ctx, articleID, authorID, domain.ArticleStatusDeleted.ToUint8())
+	if err == nil {
+		if err := c.cache.DelPubFirstPage(ctx, authorID); err != nil {
+			fmt.Println("删除作者主页缓存失败", err)
+		}
+	}
+	return err
+}
+
+// DeletePublished 删除线上库中的文章以及文章相关的缓存
+func (c *CachedArticleRepository) DeletePublished(ctx context.Context, articleID, authorID int64) error {
+	if err := c.dao.DeletePublished(ctx, articleID); err != nil {
+		return err
+	}
+	return c.ClearCache(ctx, articleID, authorID)
+}
+
+// ClearCache 删除文章详情和作者文章列表的缓存
+func (c *CachedArticleRepository) ClearCache(ctx context.Context, articleID, authorID int64) error {
+	if err := c.cache.DelPub(ctx, articleID); err != nil {
+		return err
+	}
+	if err := c.cache.DelPubFirstPage(ctx, authorID); err != nil {
+		return err
+	}
+	return c.cache.DelFirstPage(ctx, authorID)
+}
+
+// ImgUrlInUse 判断除 excludeID 外是否还有文章引用了该图片
+func (c *CachedArticleRepository) ImgUrlInUse(ctx context.Context, url string, excludeID int64) (bool, error) {
+	return c.dao.ImgUrlInUse(ctx, url, excludeID)
+}
+
+// toTagsJSON 将标签转换为 JSON 字符串存储
+func toTagsJSON(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	val, _ := json.Marshal(tags)
+	return string(val)
+}
+
+// parseTags 解析存储的标签
+func parseTags(val string) []string {
+	if val == "" {
+		return nil
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(val), &tags); err != nil {
+		fmt.Println("解析标签失败", err)
+	}
+	return tags
+}
+
+// toPublishAt 零值时间表示没有设置定时发布，存储为 0
+func toPublishAt(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// headArticles 返回前 limit 篇文章
+func headArticles(articles []domain.Article, limit int) []domain.Article {
+	if len(articles) > limit {
+		return articles[:limit]
+	}
+	return articles
+}
+
+// withAuthor 填充文章的作者信息
+func withAuthor(articles []domain.Article, author domain.Author) []domain.Article {
+	for i := range articles {
+		articles[i].Author = author
+	}
+	return articles
+}
+
+// cursorMilli 游标中的更新时间，零值游标返回 0
+func cursorMilli(cursor domain.ArticleCursor) int64 {
+	if cursor.IsZero() {
+		return 0
 	}
+	return cursor.Utime.UnixMilli()
 }
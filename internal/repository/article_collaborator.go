@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+// ErrCollaboratorNotFound 协作记录不存在
+var ErrCollaboratorNotFound = dao.ErrNotFound
+
+type CollaboratorRepository interface {
+	// Invite 邀请用户协作，已经是协作者时只更新角色
+	Invite(ctx context.Context, c domain.Collaborator) error
+	// Find 获取用户在文章中的协作记录
+	Find(ctx context.Context, articleID, userID int64) (domain.Collaborator, error)
+	// ListByArticle 获取文章的所有协作者，包括还没有接受邀请的
+	ListByArticle(ctx context.Context, articleID int64) ([]domain.Collaborator, error)
+	// ListInvitations 获取用户还没有处理的邀请
+	ListInvitations(ctx context.Context, userID int64) ([]domain.Collaborator, error)
+	// Accept 接受邀请
+	Accept(ctx context.Context, articleID, userID int64) error
+	// Remove 删除协作记录，拒绝邀请、退出协作和移除协作者都是删除记录
+	Remove(ctx context.Context, articleID, userID int64) error
+	// RemoveByArticle 删除文章的所有协作记录
+	RemoveByArticle(ctx context.Context, articleID int64) error
+}
+
+type CollaboratorRepositoryImpl struct {
+	dao dao.ArticleCollaboratorDAO
+}
+
+func NewCollaboratorRepository(dao dao.ArticleCollaboratorDAO) CollaboratorRepository {
+	return &CollaboratorRepositoryImpl{
+		dao: dao,
+	}
+}
+
+// Invite 邀请用户协作
+func (r *CollaboratorRepositoryImpl) Invite(ctx context.Context, c domain.Collaborator) error {
+	return r.dao.Upsert(ctx, dao.ArticleCollaborator{
+		ArticleID: c.ArticleID,
+		UserID:    c.UserID,
+		Role:      c.Role.ToUint8(),
+		Status:    uint8(domain.CollaboratorStatusPending),
+		InviterID: c.InviterID,
+	})
+}
+
+// Find 获取用户在文章中的协作记录
+func (r *CollaboratorRepositoryImpl) Find(ctx context.Context, articleID, userID int64) (domain.Collaborator, error) {
+	c, err := r.dao.Find(ctx, articleID, userID)
+	if err != nil {
+		return domain.Collaborator{}, err
+	}
+	return r.toDomain(c), nil
+}
+
+// ListByArticle 获取文章的所有协作者
+func (r *CollaboratorRepositoryImpl) ListByArticle(ctx context.Context, articleID int64) ([]domain.Collaborator, error) {
+	cs, err := r.dao.FindByArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainList(cs), nil
+}
+
+// ListInvitations 获取用户还没有处理的邀请
+func (r *CollaboratorRepositoryImpl) ListInvitations(ctx context.Context, userID int64) ([]domain.Collaborator, error) {
+	cs, err := r.dao.FindByUser(ctx, userID, uint8(domain.CollaboratorStatusPending))
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainList(cs), nil
+}
+
+// Accept 接受邀请
+func (r *CollaboratorRepositoryImpl) Accept(ctx context.Context, articleID, userID int64) error {
+	return r.dao.UpdateStatus(ctx, articleID, userID,
+		uint8(domain.CollaboratorStatusPending), uint8(domain.CollaboratorStatusAccepted))
+}
+
+// Remove 删除协作记录
+func (r *CollaboratorRepositoryImpl) Remove(ctx context.Context, articleID, userID int64) error {
+	return r.dao.Delete(ctx, articleID, userID)
+}
+
+// RemoveByArticle 删除文章的所有协作记录
+func (r *CollaboratorRepositoryImpl) RemoveByArticle(ctx context.Context, articleID int64) error {
+	return r.dao.DeleteByArticle(ctx, articleID)
+}
+
+func (r *CollaboratorRepositoryImpl) toDomainList(cs []dao.ArticleCollaborator) []domain.Collaborator {
+	res := make([]domain.Collaborator, 0, len(cs))
+	for _, c := range cs {
+		res = append(res, r.toDomain(c))
+	}
+	return res
+}
+
+func (r *CollaboratorRepositoryImpl) toDomain(c dao.ArticleCollaborator) domain.Collaborator {
+	return domain.Collaborator{
+		ArticleID: c.ArticleID,
+		UserID:    c.UserID,
+		Role:      domain.CollaboratorRole(c.Role),
+		Status:    domain.CollaboratorStatus(c.Status),
+		InviterID: c.InviterID,
+		Ctime:     time.UnixMilli(c.Ctime),
+		Utime:     time.UnixMilli(c.Utime),
+	}
+}
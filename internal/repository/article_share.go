@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+// ErrArticleShareNotFound 分享链接不存在
+var ErrArticleShareNotFound = dao.ErrNotFound
+
+type ArticleShareRepository interface {
+	// Create 创建分享链接
+	Create(ctx context.Context, s domain.ArticleShare) (int64, error)
+	// FindByToken 根据令牌获取分享链接
+	FindByToken(ctx context.Context, token string) (domain.ArticleShare, error)
+	// ListByArticle 获取作者为文章创建的所有分享链接
+	ListByArticle(ctx context.Context, articleID, authorID int64) ([]domain.ArticleShare, error)
+	// Revoke 撤销作者的分享链接
+	Revoke(ctx context.Context, id, authorID int64) error
+}
+
+type ArticleShareRepositoryImpl struct {
+	dao dao.ArticleShareDAO
+}
+
+func NewArticleShareRepository(dao dao.ArticleShareDAO) ArticleShareRepository {
+	return &ArticleShareRepositoryImpl{
+		dao: dao,
+	}
+}
+
+// Create 创建分享链接
+func (r *ArticleShareRepositoryImpl) Create(ctx context.Context, s domain.ArticleShare) (int64, error) {
+	return r.dao.Insert(ctx, dao.ArticleShare{
+		ArticleID: s.ArticleID,
+		AuthorID:  s.AuthorID,
+		Token:     s.Token,
+		ExpireAt:  s.ExpireAt.UnixMilli(),
+	})
+}
+
+// FindByToken 根据令牌获取分享链接
+func (r *ArticleShareRepositoryImpl) FindByToken(ctx context.Context, token string) (domain.ArticleShare, error) {
+	s, err := r.dao.FindByToken(ctx, token)
+	if err != nil {
+		return domain.ArticleShare{}, err
+	}
+	return r.toDomain(s), nil
+}
+
+// ListByArticle 获取作者为文章创建的所有分享链接
+func (r *ArticleShareRepositoryImpl) ListByArticle(ctx context.Context, articleID, authorID int64) ([]domain.ArticleShare, error) {
+	shares, err := r.dao.FindByArticle(ctx, articleID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.ArticleShare, 0, len(shares))
+	for _, s := range shares {
+		res = append(res, r.toDomain(s))
+	}
+	return res, nil
+}
+
+// Revoke 撤销作者的分享链接
+func (r *ArticleShareRepositoryImpl) Revoke(ctx context.Context, id, authorID int64) error {
+	return r.dao.Delete(ctx, id, authorID)
+}
+
+func (r *ArticleShareRepositoryImpl) toDomain(s dao.ArticleShare) domain.ArticleShare {
+	return domain.ArticleShare{
+		ID:        s.ID,
+		ArticleID: s.ArticleID,
+		AuthorID:  s.AuthorID,
+		Token:     s.Token,
+		ExpireAt:  time.UnixMilli(s.ExpireAt),
+		Ctime:     time.UnixMilli(s.Ctime),
+	}
+}
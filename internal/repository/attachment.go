@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/cache"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+var (
+	// ErrAttachmentNotFound 附件不存在
+	ErrAttachmentNotFound = dao.ErrNotFound
+	// ErrChunkUploadNotFound 分片上传任务不存在或者已过期
+	ErrChunkUploadNotFound = cache.ErrKeyNotExist
+)
+
+// AttachmentRepository 附件保存在数据库中，上传过程中的分片任务保存在 Redis 中
+type AttachmentRepository interface {
+	// Create 新增附件
+	Create(ctx context.Context, a domain.Attachment) (int64, error)
+	// FindByID 根据ID获取附件
+	FindByID(ctx context.Context, id int64) (domain.Attachment, error)
+	// FindByArticle 获取文章的所有附件
+	FindByArticle(ctx context.Context, articleID int64) ([]domain.Attachment, error)
+	// Delete 删除附件
+	Delete(ctx context.Context, id int64) error
+
+	// CreateUpload 保存分片上传任务
+	CreateUpload(ctx context.Context, u domain.ChunkUpload) error
+	// GetUpload 获取分片上传任务和已上传的分片
+	GetUpload(ctx context.Context, id string) (domain.ChunkUpload, error)
+	// SetPart 记录已上传的分片
+	SetPart(ctx context.Context, u domain.ChunkUpload, part int, checksum string) error
+	// LockUpload 合并分片时加锁，返回是否拿到锁，token 用来标识锁的持有者
+	LockUpload(ctx context.Context, id, token string, ttl time.Duration) (bool, error)
+	// UnlockUpload 释放自己持有的合并分片的锁
+	UnlockUpload(ctx context.Context, id, token string) error
+	// DeleteUpload 删除分片上传任务
+	DeleteUpload(ctx context.Context, id string) error
+	// ExpiredUploads 获取已经过期的分片上传任务ID
+	ExpiredUploads(ctx context.Context, now time.Time, limit int) ([]string, error)
+}
+
+type AttachmentRepositoryImpl struct {
+	dao   dao.AttachmentDAO
+	cache cache.ChunkUploadCache
+}
+
+func NewAttachmentRepository(dao dao.AttachmentDAO, cache cache.ChunkUploadCache) AttachmentRepository {
+	return &AttachmentRepositoryImpl{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+// Create 新增附件
+func (r *AttachmentRepositoryImpl) Create(ctx context.Context, a domain.Attachment) (int64, error) {
+	return r.dao.Insert(ctx, dao.Attachment{
+		ArticleID:  a.ArticleID,
+		UploaderID: a.UploaderID,
+		Filename:   a.Filename,
+		ObjectKey:  a.ObjectKey,
+		Size:       a.Size,
+		MimeType:   a.MimeType,
+		SHA256:     a.SHA256,
+	})
+}
+
+// FindByID 根据ID获取附件
+func (r *AttachmentRepositoryImpl) FindByID(ctx context.Context, id int64) (domain.Attachment, error) {
+	a, err := r.dao.FindByID(ctx, id)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	return r.toDomain(a), nil
+}
+
+// FindByArticle 获取文章的所有附件
+func (r *AttachmentRepositoryImpl) FindByArticle(ctx context.Context, articleID int64) ([]domain.Attachment, error) {
+	list, err := r.dao.FindByArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Attachment, 0, len(list))
+	for _, a := range list {
+		res = append(res, r.toDomain(a))
+	}
+	return res, nil
+}
+
+// Delete 删除附件
+func (r *AttachmentRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	return r.dao.Delete(ctx, id)
+}
+
+// CreateUpload 保存分片上传任务
+func (r *AttachmentRepositoryImpl) CreateUpload(ctx context.Context, u domain.ChunkUpload) error {
+	return r.cache.Create(ctx, u)
+}
+
+// GetUpload 获取分片上传任务和已上传的分片
+func (r *AttachmentRepositoryImpl) GetUpload(ctx context.Context, id string) (domain.ChunkUpload, error) {
+	return r.cache.Get(ctx, id)
+}
+
+// SetPart 记录已上传的分片
+func (r *AttachmentRepositoryImpl) SetPart(ctx context.Context, u domain.ChunkUpload, part int, checksum string) error {
+	return r.cache.SetPart(ctx, u, part, checksum)
+}
+
+// LockUpload 合并分片时加锁
+func (r *AttachmentRepositoryImpl) LockUpload(ctx context.Context, id, token string, ttl time.Duration) (bool, error) {
+	return r.cache.Lock(ctx, id, token, ttl)
+}
+
+// UnlockUpload 释放合并分片的锁
+func (r *AttachmentRepositoryImpl) UnlockUpload(ctx context.Context, id, token string) error {
+	return r.cache.Unlock(ctx, id, token)
+}
+
+// DeleteUpload 删除分片上传任务
+func (r *AttachmentRepositoryImpl) DeleteUpload(ctx context.Context, id string) error {
+	return r.cache.Delete(ctx, id)
+}
+
+// ExpiredUploads 获取已经过期的分片上传任务ID
+func (r *AttachmentRepositoryImpl) ExpiredUploads(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	return r.cache.Expired(ctx, now, limit)
+}
+
+func (r *AttachmentRepositoryImpl) toDomain(a dao.Attachment) domain.Attachment {
+	return domain.Attachment{
+		ID:         a.ID,
+		ArticleID:  a.ArticleID,
+		UploaderID: a.UploaderID,
+		Filename:   a.Filename,
+		ObjectKey:  a.ObjectKey,
+		Size:       a.Size,
+		MimeType:   a.MimeType,
+		SHA256:     a.SHA256,
+		Ctime:      time.UnixMilli(a.Ctime),
+	}
+}
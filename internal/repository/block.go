@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+type BlockRepository interface {
+	// Block 拉黑用户
+	Block(ctx context.Context, uid, blockedID int64) error
+	// Unblock 取消拉黑
+	Unblock(ctx context.Context, uid, blockedID int64) error
+	// IsBlockedEither 两个用户之间是否有任意一方拉黑了另一方
+	IsBlockedEither(ctx context.Context, a, b int64) (bool, error)
+	// List 获取用户拉黑的人
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.UserBlock, error)
+	// Mute 屏蔽用户
+	Mute(ctx context.Context, uid, mutedID int64) error
+	// Unmute 取消屏蔽
+	Unmute(ctx context.Context, uid, mutedID int64) error
+	// ListMuted 获取用户屏蔽的人
+	ListMuted(ctx context.Context, uid int64, offset, limit int) ([]domain.UserMute, error)
+	// HiddenIDs 获取用户拉黑和屏蔽的所有人
+	HiddenIDs(ctx context.Context, uid int64) ([]int64, error)
+}
+
+type blockRepository struct {
+	dao dao.BlockDAO
+}
+
+func NewBlockRepository(dao dao.BlockDAO) BlockRepository {
+	return &blockRepository{
+		dao: dao,
+	}
+}
+
+func (r *blockRepository) Block(ctx context.Context, uid, blockedID int64) error {
+	return r.dao.Insert(ctx, dao.UserBlock{
+		UserID:    uid,
+		BlockedID: blockedID,
+		Ctime:     time.Now().UnixMilli(),
+	})
+}
+
+func (r *blockRepository) Unblock(ctx context.Context, uid, blockedID int64) error {
+	return r.dao.Delete(ctx, uid, blockedID)
+}
+
+func (r *blockRepository) IsBlockedEither(ctx context.Context, a, b int64) (bool, error) {
+	return r.dao.ExistsEither(ctx, a, b)
+}
+
+func (r *blockRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.UserBlock, error) {
+	blocks, err := r.dao.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.UserBlock, 0, len(blocks))
+	for _, b := range blocks {
+		res = append(res, domain.UserBlock{
+			UserID:    b.UserID,
+			BlockedID: b.BlockedID,
+			Ctime:     b.Ctime,
+		})
+	}
+	return res, nil
+}
+
+func (r *blockRepository) Mute(ctx context.Context, uid, mutedID int64) error {
+	return r.dao.InsertMute(ctx, dao.UserMute{
+		UserID:  uid,
+		MutedID: mutedID,
+		Ctime:   time.Now().UnixMilli(),
+	})
+}
+
+func (r *blockRepository) Unmute(ctx context.Context, uid, mutedID int64) error {
+	return r.dao.DeleteMute(ctx, uid, mutedID)
+}
+
+func (r *blockRepository) ListMuted(ctx context.Context, uid int64, offset, limit int) ([]domain.UserMute, error) {
+	mutes, err := r.dao.ListMuted(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.UserMute, 0, len(mutes))
+	for _, m := range mutes {
+		res = append(res, domain.UserMute{
+			UserID:  m.UserID,
+			MutedID: m.MutedID,
+			Ctime:   m.Ctime,
+		})
+	}
+	return res, nil
+}
+
+func (r *blockRepository) HiddenIDs(ctx context.Context, uid int64) ([]int64, error) {
+	return r.dao.HiddenIDs(ctx, uid)
+}
@@ -23,6 +23,14 @@ type ArticleCache interface {
 	SetPub(ctx context.Context, article domain.Article) error
 	// GetPub 获取发布文章的缓存
 	GetPub(ctx context.Context, id int64) (domain.Article, error)
+	// DelPub 删除发布文章的缓存
+	DelPub(ctx context.Context, id int64) error
+	// GetPubFirstPage 获取作者已发布文章第一页的缓存
+	GetPubFirstPage(ctx context.Context, authorID int64) ([]domain.Article, error)
+	// SetPubFirstPage 缓存作者已发布文章的第一页
+	SetPubFirstPage(ctx context.Context, authorID int64, articles []domain.Article) error
+	// DelPubFirstPage 删除作者已发布文章第一页的缓存
+	DelPubFirstPage(ctx context.Context, authorID int64) error
 }
 
 type RedisArticleCache struct {
@@ -58,6 +66,8 @@ func (a *RedisArticleCache) SetFirstPage(ctx context.Context, uid int64, article
 	// 将文章内容替换成摘要
 	for i := 0; i < len(articles); i++ {
 		articles[i].Content = articles[i].GenerateAbstract()
+		articles[i].Format = domain.ContentFormatPlain
+		articles[i].HTML = ""
 	}
 
 	// Redis 存储的数据通常是字节切片，Redis 存储的数据通常是字节切片
@@ -76,6 +86,11 @@ func (r *RedisArticleCache) KeyArticle(uid int64) string {
 func (r *RedisArticleCache) KeyList(uid int64) string {
 	return fmt.Sprintf("article:first_page:%d", uid)
 }
+// KeyPubList 生成作者已发布文章列表缓存的key
+func (r *RedisArticleCache) KeyPubList(authorID int64) string {
+	return fmt.Sprintf("article:pub_first_page:%d", authorID)
+}
+
 // keyArticlePub 生成发布文章缓存的key
 func (r *RedisArticleCache) KeyArticlePub(id int64) string {
 	return fmt.Sprintf("article:pub:%d", id)
@@ -128,4 +143,46 @@ func (a *RedisArticleCache) GetPub(ctx context.Context, id int64) (domain.Articl
 	}
 
 	return article, nil
-}
\ No newline at end of file
+}
+
+// DelPub 删除发布文章的缓存
+func (a *RedisArticleCache) DelPub(ctx context.Context, id int64) error {
+	return a.client.Del(ctx, a.KeyArticlePub(id)).Err()
+}
+
+// GetPubFirstPage 获取作者已发布文章第一页的缓存，未命中时返回 nil
+func (a *RedisArticleCache) GetPubFirstPage(ctx context.Context, authorID int64) ([]domain.Article, error) {
+	data, err := a.client.Get(ctx, a.KeyPubList(authorID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var articles []domain.Article
+	if err := json.Unmarshal(data, &articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// SetPubFirstPage 缓存作者已发布文章的第一页，和 SetFirstPage 一样只缓存摘要
+func (a *RedisArticleCache) SetPubFirstPage(ctx context.Context, authorID int64, articles []domain.Article) error {
+	for i := 0; i < len(articles); i++ {
+		articles[i].Content = articles[i].GenerateAbstract()
+		articles[i].Format = domain.ContentFormatPlain
+		articles[i].HTML = ""
+	}
+
+	data, err := json.Marshal(articles)
+	if err != nil {
+		return err
+	}
+	return a.client.Set(ctx, a.KeyPubList(authorID), data, time.Minute*10).Err()
+}
+
+// DelPubFirstPage 删除作者已发布文章第一页的缓存
+func (a *RedisArticleCache) DelPubFirstPage(ctx context.Context, authorID int64) error {
+	return a.client.Del(ctx, a.KeyPubList(authorID)).Err()
+}
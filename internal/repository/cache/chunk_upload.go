@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/unlock.lua
+var luaUnlock string // lua脚本，校验 token 之后释放锁
+
+const (
+	// 分片上传任务过期的有序集合，score 为过期时间，供清理任务扫描
+	chunkUploadExpiringKey = "upload:chunk:expiring"
+	// 任务过期之后多保留一段时间，清理任务需要根据任务信息删除已上传的分片
+	chunkUploadRetention = time.Hour
+)
+
+type ChunkUploadCache interface {
+	// Create 保存新的分片上传任务
+	Create(ctx context.Context, u domain.ChunkUpload) error
+	// Get 获取分片上传任务和已上传的分片，任务不存在时返回 ErrKeyNotExist
+	Get(ctx context.Context, id string) (domain.ChunkUpload, error)
+	// SetPart 记录已上传的分片和它的 SHA-256
+	SetPart(ctx context.Context, u domain.ChunkUpload, part int, checksum string) error
+	// Lock 合并分片时加锁，避免重复合并，返回是否拿到锁，token 用来标识锁的持有者
+	Lock(ctx context.Context, id, token string, ttl time.Duration) (bool, error)
+	// Unlock 释放合并分片的锁，只有 token 和加锁时一致才会删除
+	Unlock(ctx context.Context, id, token string) error
+	// Delete 删除分片上传任务
+	Delete(ctx context.Context, id string) error
+	// Expired 获取 now 之前已经过期的任务ID
+	Expired(ctx context.Context, now time.Time, limit int) ([]string, error)
+}
+
+type RedisChunkUploadCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisChunkUploadCache(client redis.Cmdable) ChunkUploadCache {
+	return &RedisChunkUploadCache{
+		client: client,
+	}
+}
+
+// Create 保存任务信息，分片列表单独存放在哈希中
+func (r *RedisChunkUploadCache) Create(ctx context.Context, u domain.ChunkUpload) error {
+	u.Parts = nil
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.key(u.ID), data, time.Until(u.ExpireAt)+chunkUploadRetention)
+	pipe.ZAdd(ctx, chunkUploadExpiringKey, redis.Z{
+		Score:  float64(u.ExpireAt.UnixMilli()),
+		Member: u.ID,
+	})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Get 获取任务信息和已上传的分片
+func (r *RedisChunkUploadCache) Get(ctx context.Context, id string) (domain.ChunkUpload, error) {
+	data, err := r.client.Get(ctx, r.key(id)).Bytes()
+	if err != nil {
+		return domain.ChunkUpload{}, err
+	}
+	var u domain.ChunkUpload
+	if err = json.Unmarshal(data, &u); err != nil {
+		return domain.ChunkUpload{}, err
+	}
+	parts, err := r.client.HGetAll(ctx, r.partsKey(id)).Result()
+	if err != nil {
+		return domain.ChunkUpload{}, err
+	}
+	u.Parts = make(map[int]string, len(parts))
+	for k, v := range parts {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		u.Parts[n] = v
+	}
+	return u, nil
+}
+
+// SetPart 记录已上传的分片，重复上传同一个分片时覆盖
+func (r *RedisChunkUploadCache) SetPart(ctx context.Context, u domain.ChunkUpload, part int, checksum string) error {
+	key := r.partsKey(u.ID)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, key, strconv.Itoa(part), checksum)
+	pipe.ExpireAt(ctx, key, u.ExpireAt.Add(chunkUploadRetention))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Lock 合并分片时加锁，锁的值是持有者的 token
+func (r *RedisChunkUploadCache) Lock(ctx context.Context, id, token string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.lockKey(id), token, ttl).Result()
+}
+
+// Unlock 释放合并分片的锁
+// 合并超过锁的有效期时锁可能已经被别的请求拿到，所以先比较 token 再删除
+func (r *RedisChunkUploadCache) Unlock(ctx context.Context, id, token string) error {
+	return r.client.Eval(ctx, luaUnlock, []string{r.lockKey(id)}, token).Err()
+}
+
+// Delete 删除任务的所有数据
+func (r *RedisChunkUploadCache) Delete(ctx context.Context, id string) error {
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, r.key(id), r.partsKey(id), r.lockKey(id))
+	pipe.ZRem(ctx, chunkUploadExpiringKey, id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Expired 获取已经过期的任务ID
+func (r *RedisChunkUploadCache) Expired(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	return r.client.ZRangeByScore(ctx, chunkUploadExpiringKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+}
+
+func (r *RedisChunkUploadCache) key(id string) string {
+	return fmt.Sprintf("upload:chunk:%s", id)
+}
+
+func (r *RedisChunkUploadCache) partsKey(id string) string {
+	return fmt.Sprintf("upload:chunk:%s:parts", id)
+}
+
+func (r *RedisChunkUploadCache) lockKey(id string) string {
+	return fmt.Sprintf("upload:chunk:%s:lock", id)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/comment_rank_incr.lua
+var luaCommentRankIncr string
+
+const (
+	// 热门评论排行前缀，zset 保存根评论的热度分数，:cnt 的 hash 保存计算分数用的点赞数、回复数和创建时间
+	commentRankKeyPrefix = "comment:rank:"
+	commentRankTTL       = 7 * 24 * time.Hour
+	// 占位成员，保证没有评论的业务对象也有排行榜，避免每次读取都回源重建
+	commentRankPlaceholder = "0"
+)
+
+// CommentRankCache 按业务对象维护根评论的热度排行
+type CommentRankCache interface {
+	// Top 返回热度最高的 limit 条根评论ID，排行榜不存在时返回 ErrKeyNotExist
+	Top(ctx context.Context, biz string, bizID int64, limit int) ([]int64, error)
+	// Rebuild 用数据库中的互动数据重建排行榜
+	Rebuild(ctx context.Context, biz string, bizID int64, items []dao.CommentEngagement) error
+	// Add 把新的根评论加入排行榜，排行榜不存在时忽略
+	Add(ctx context.Context, biz string, bizID, id, ctime int64) error
+	// Incr 调整根评论的点赞数和回复数并更新分数，排行榜不存在时忽略
+	Incr(ctx context.Context, biz string, bizID, id, likeDelta, replyDelta int64) error
+	// Remove 把根评论移出排行榜
+	Remove(ctx context.Context, biz string, bizID, id int64) error
+	// Del 删除整个排行榜
+	Del(ctx context.Context, biz string, bizID int64) error
+}
+
+type RedisCommentRankCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisCommentRankCache(client redis.Cmdable) CommentRankCache {
+	return &RedisCommentRankCache{
+		client: client,
+	}
+}
+
+func (r *RedisCommentRankCache) rankKey(biz string, bizID int64) string {
+	return fmt.Sprintf("%s%s:%d", commentRankKeyPrefix, biz, bizID)
+}
+
+func (r *RedisCommentRankCache) cntKey(biz string, bizID int64) string {
+	return r.rankKey(biz, bizID) + ":cnt"
+}
+
+// Top 返回热度最高的 limit 条根评论ID
+func (r *RedisCommentRankCache) Top(ctx context.Context, biz string, bizID int64, limit int) ([]int64, error) {
+	// 占位成员的分数是负无穷，多取一个保证能拿满 limit 条
+	members, err := r.client.ZRevRange(ctx, r.rankKey(biz, bizID), 0, int64(limit)).Result()
+	if err != nil {
+		return nil, err
+	}
+	// 存在的排行榜至少有占位成员
+	if len(members) == 0 {
+		return nil, ErrKeyNotExist
+	}
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		if m == commentRankPlaceholder {
+			continue
+		}
+		id, err := strconv.ParseInt(m, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+		if len(ids) == limit {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// Rebuild 用数据库中的互动数据重建排行榜
+func (r *RedisCommentRankCache) Rebuild(ctx context.Context, biz string, bizID int64, items []dao.CommentEngagement) error {
+	rankKey, cntKey := r.rankKey(biz, bizID), r.cntKey(biz, bizID)
+	members := make([]redis.Z, 0, len(items)+1)
+	members = append(members, redis.Z{Score: math.Inf(-1), Member: commentRankPlaceholder})
+	fields := make([]any, 0, len(items)*6)
+	for _, item := range items {
+		id := strconv.FormatInt(item.ID, 10)
+		members = append(members, redis.Z{
+			Score:  domain.CommentHotScore(item.Likes, item.Replies, item.Ctime),
+			Member: id,
+		})
+		fields = append(fields, "t:"+id, item.Ctime, "l:"+id, item.Likes, "r:"+id, item.Replies)
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, rankKey, cntKey)
+		pipe.ZAdd(ctx, rankKey, members...)
+		if len(fields) > 0 {
+			pipe.HSet(ctx, cntKey, fields...)
+			pipe.Expire(ctx, cntKey, commentRankTTL)
+		}
+		pipe.Expire(ctx, rankKey, commentRankTTL)
+		return nil
+	})
+	return err
+}
+
+// Add 把新的根评论加入排行榜
+func (r *RedisCommentRankCache) Add(ctx context.Context, biz string, bizID, id, ctime int64) error {
+	return r.eval(ctx, biz, bizID, id, 0, 0, ctime)
+}
+
+// Incr 调整根评论的点赞数和回复数并更新分数
+func (r *RedisCommentRankCache) Incr(ctx context.Context, biz string, bizID, id, likeDelta, replyDelta int64) error {
+	return r.eval(ctx, biz, bizID, id, likeDelta, replyDelta, 0)
+}
+
+func (r *RedisCommentRankCache) eval(ctx context.Context, biz string, bizID, id, likeDelta, replyDelta, ctime int64) error {
+	return r.client.Eval(ctx, luaCommentRankIncr, []string{r.rankKey(biz, bizID), r.cntKey(biz, bizID)},
+		id, likeDelta, replyDelta, ctime,
+		domain.CommentHotReplyWeight, domain.CommentHotDecay, int64(commentRankTTL.Seconds())).Err()
+}
+
+// Remove 把根评论移出排行榜
+func (r *RedisCommentRankCache) Remove(ctx context.Context, biz string, bizID, id int64) error {
+	member := strconv.FormatInt(id, 10)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, r.rankKey(biz, bizID), member)
+		pipe.HDel(ctx, r.cntKey(biz, bizID), "t:"+member, "l:"+member, "r:"+member)
+		return nil
+	})
+	return err
+}
+
+// Del 删除整个排行榜
+func (r *RedisCommentRankCache) Del(ctx context.Context, biz string, bizID int64) error {
+	return r.client.Del(ctx, r.rankKey(biz, bizID), r.cntKey(biz, bizID)).Err()
+}
@@ -27,6 +27,7 @@ type FeedCache interface {
 	AddToOutbox(ctx context.Context, userID int64, item domain.UserFeedItem) error
 	GetOutboxForUser(ctx context.Context, userID int64, offset, limit int) ([]domain.UserFeedItem, error)
 	GetFeedEventsSince(ctx context.Context, since time.Time, offset, limit int) ([]domain.FeedEvent, error)
+	RemoveArticleItems(ctx context.Context, userID, articleID int64) error
 	GetClient() redis.Cmdable
 }
 
@@ -211,6 +212,49 @@ func (r *RedisFeedCache) GetFeedEventsSince(ctx context.Context, since time.Time
 	return events, nil
 }
 
+// RemoveArticleItems 从用户的收件箱和发件箱中移除与文章相关的所有 Feed 项
+// 包括文章发布以及文章的点赞、评论、收藏
+func (r *RedisFeedCache) RemoveArticleItems(ctx context.Context, userID, articleID int64) error {
+	for _, prefix := range []string{userInboxKeyPrefix, userOutboxKeyPrefix} {
+		key := fmt.Sprintf("%s%d", prefix, userID)
+		values, err := r.client.ZRange(ctx, key, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+
+		members := make([]interface{}, 0)
+		for _, value := range values {
+			if feedItemOfArticle(value, articleID) {
+				members = append(members, value)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		if err := r.client.ZRem(ctx, key, members...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// feedItemOfArticle 判断 Feed 项是否与指定文章相关
+func feedItemOfArticle(value string, articleID int64) bool {
+	var item domain.UserFeedItem
+	if err := json.Unmarshal([]byte(value), &item); err != nil {
+		return false
+	}
+	if item.ItemID == "article:"+strconv.FormatInt(articleID, 10) {
+		return true
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal([]byte(item.Content), &content); err != nil {
+		return false
+	}
+	id, ok := content["article_id"].(float64)
+	return ok && int64(id) == articleID
+}
+
 // GetClient 返回 Redis 客户端，供 repository 层使用
 func (r *RedisFeedCache) GetClient() redis.Cmdable {
 	return r.client
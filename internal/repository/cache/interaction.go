@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	_ "embed"
 
@@ -14,22 +15,46 @@ import (
 //go:embed lua/interaction_incr.lua
 var luaIncrCnt string
 
+//go:embed lua/interaction_incr_pending.lua
+var luaIncrPendingCnt string // lua脚本，增加点赞量、收藏量并记录未写入数据库的增量
+
+//go:embed lua/interaction_seed.lua
+var luaSeedCnt string // lua脚本，用数据库中的计数和未写入数据库的增量初始化缓存
+
+//go:embed lua/interaction_settle.lua
+var luaSettleCnt string // lua脚本，扣除已经写入数据库的增量
+
 const (
-	fieldViewCount    = "view_count"
-	fieldLikeCount    = "like_count"
-	fieldCollectCount = "collect_count"
+	fieldViewCount       = "view_count"
+	fieldUniqueViewCount = "unique_view_count"
+	fieldLikeCount       = "like_count"
+	fieldCollectCount    = "collect_count"
+	// 缓存中的计数实时更新，数据库中的点赞量和收藏量批量写入，会有短暂的延迟
+	interactionExpiration = time.Minute * 15
+	// 未写入数据库的增量每次变化时续期，正常情况下几秒内就会写入数据库
+	interactionPendingExpiration = time.Hour
+	// 独立浏览的去重窗口，同一访客在一个窗口内多次阅读只算一次
+	uniqueViewWindow = time.Hour * 24
 )
 
 type InteractionCacheInterface interface {
 	// IncrViewCntIfPresent increments the view count if the interaction exists
 	IncrViewCntIfPresent(ctx context.Context, biz string, bizId int64) error
-	IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
-	IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
-	DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	// IncrLikeCnt 和 IncrCollectCnt 调整点赞量、收藏量，同时记录为未写入数据库的增量，
+	// 返回缓存中是否有计数，没有时需要调用 Seed 初始化
+	IncrLikeCnt(ctx context.Context, biz string, bizId int64, delta int64) (bool, error)
+	IncrCollectCnt(ctx context.Context, biz string, bizId int64, delta int64) (bool, error)
+	// Seed 缓存中没有计数时用数据库中的计数初始化，点赞量和收藏量会加上未写入数据库的增量
+	Seed(ctx context.Context, biz string, bizId int64, interaction domain.Interaction) error
+	// SettlePending 增量写入数据库之后，从未写入数据库的增量中扣除
+	SettlePending(ctx context.Context, deltas []domain.InteractionDelta) error
 	Get(ctx context.Context, biz string, bizId int64) (domain.Interaction, error)
 	Set(ctx context.Context, biz string, bizId int64, interaction domain.Interaction) error
-	DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
-	BatchIncrViewCntIfPresent(ctx context.Context, biz []string, bizIds []int64) error
+	// BatchIncrViewCntIfPresent 批量增加浏览量，unique 中为 true 的同时增加独立浏览量
+	BatchIncrViewCntIfPresent(ctx context.Context, biz []string, bizIds []int64, unique []bool) error
+	// AddViewers 记录访客阅读了业务对象，返回每个访客是否是当前去重窗口内的新访客
+	AddViewers(ctx context.Context, biz []string, bizIds []int64, visitors []string) ([]bool, error)
+	Del(ctx context.Context, biz string, bizId int64) error
 }
 
 type RedisInteractionCache struct {
@@ -50,24 +75,48 @@ func (r *RedisInteractionCache) IncrViewCntIfPresent(ctx context.Context, biz st
 	return r.client.Eval(ctx, luaIncrCnt, []string{r.Key(biz, bizId)}, fieldViewCount, 1).Err()
 }
 
-// 增加点赞量
-func (r *RedisInteractionCache) IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.Key(biz, bizId)}, fieldLikeCount, 1).Err()
+// 调整点赞量
+func (r *RedisInteractionCache) IncrLikeCnt(ctx context.Context, biz string, bizId int64, delta int64) (bool, error) {
+	return r.incrPending(ctx, biz, bizId, fieldLikeCount, delta)
+}
+
+// 调整收藏量
+func (r *RedisInteractionCache) IncrCollectCnt(ctx context.Context, biz string, bizId int64, delta int64) (bool, error) {
+	return r.incrPending(ctx, biz, bizId, fieldCollectCount, delta)
+}
+
+func (r *RedisInteractionCache) incrPending(ctx context.Context, biz string, bizId int64, field string, delta int64) (bool, error) {
+	res, err := r.client.Eval(ctx, luaIncrPendingCnt, []string{r.Key(biz, bizId), r.pendingKey(biz, bizId)},
+		field, delta, int64(interactionPendingExpiration/time.Second)).Int()
+	return res == 1, err
 }
 
-// 增加收藏量
-func (r *RedisInteractionCache) IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.Key(biz, bizId)}, fieldCollectCount, 1).Err()
+// Seed 初始化缓存，浏览量没有延迟写入，直接使用数据库中的值
+func (r *RedisInteractionCache) Seed(ctx context.Context, biz string, bizId int64, interaction domain.Interaction) error {
+	return r.client.Eval(ctx, luaSeedCnt, []string{r.Key(biz, bizId), r.pendingKey(biz, bizId)},
+		int64(interactionExpiration/time.Second),
+		fieldCollectCount, interaction.CollectCnt,
+		fieldLikeCount, interaction.LikeCnt,
+		fieldViewCount, interaction.ViewCnt,
+		fieldUniqueViewCount, interaction.UniqueViewCnt).Err()
 }
 
-// 减少点赞量
-func (r *RedisInteractionCache) DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.Key(biz, bizId)}, fieldLikeCount, -1).Err()
+// SettlePending 批量扣除已经写入数据库的增量
+func (r *RedisInteractionCache) SettlePending(ctx context.Context, deltas []domain.InteractionDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	pipeline := r.client.Pipeline()
+	for _, d := range deltas {
+		pipeline.Eval(ctx, luaSettleCnt, []string{r.pendingKey(d.Biz, d.BizID)},
+			fieldLikeCount, d.LikeCnt, fieldCollectCount, d.CollectCnt)
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
 }
 
-// 减少收藏量
-func (r *RedisInteractionCache) DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.Key(biz, bizId)}, fieldCollectCount, -1).Err()
+func (r *RedisInteractionCache) pendingKey(biz string, bizId int64) string {
+	return "interaction:pending:" + biz + ":" + strconv.FormatInt(bizId, 10)
 }
 
 func (r *RedisInteractionCache) Get(ctx context.Context, biz string, bizId int64) (domain.Interaction, error) {
@@ -90,12 +139,14 @@ func (r *RedisInteractionCache) Get(ctx context.Context, biz string, bizId int64
 	collectCount, _ := strconv.ParseInt(data[fieldCollectCount], 10, 64)
 	likeCount, _ := strconv.ParseInt(data[fieldLikeCount], 10, 64)
 	viewCount, _ := strconv.ParseInt(data[fieldViewCount], 10, 64)
+	uniqueViewCount, _ := strconv.ParseInt(data[fieldUniqueViewCount], 10, 64)
 
 	// 将 Redis 中的字段转换为 Interaction 对象
 	interaction := domain.Interaction{
-		CollectCnt: collectCount,
-		LikeCnt:    likeCount,
-		ViewCnt:    viewCount,
+		CollectCnt:    collectCount,
+		LikeCnt:       likeCount,
+		ViewCnt:       viewCount,
+		UniqueViewCnt: uniqueViewCount,
 	}
 	return interaction, nil
 }
@@ -104,21 +155,22 @@ func (r *RedisInteractionCache) Get(ctx context.Context, biz string, bizId int64
 func (r *RedisInteractionCache) Set(ctx context.Context, biz string, bizId int64, interaction domain.Interaction) error {
 	// 哈希表的字段包括收藏计数、点赞计数和浏览计数，对应的值从 interaction 结构体中获取。
 	err := r.client.HSet(ctx, r.Key(biz, bizId), map[string]any{
-		fieldCollectCount: interaction.CollectCnt,
-		fieldLikeCount:    interaction.LikeCnt,
-		fieldViewCount:    interaction.ViewCnt,
+		fieldCollectCount:    interaction.CollectCnt,
+		fieldLikeCount:       interaction.LikeCnt,
+		fieldViewCount:       interaction.ViewCnt,
+		fieldUniqueViewCount: interaction.UniqueViewCnt,
 	}).Err()
 
 	if err != nil {
 
 		return err
 	}
-	return r.client.Expire(ctx, r.Key(biz, bizId), 0).Err()
+	return r.client.Expire(ctx, r.Key(biz, bizId), interactionExpiration).Err()
 }
 
 // 批量增加浏览量
-func (r *RedisInteractionCache) BatchIncrViewCntIfPresent(ctx context.Context, biz []string, bizIds []int64) error {
-	if len(biz) != len(bizIds) {
+func (r *RedisInteractionCache) BatchIncrViewCntIfPresent(ctx context.Context, biz []string, bizIds []int64, unique []bool) error {
+	if len(biz) != len(bizIds) || len(biz) != len(unique) {
 		return fmt.Errorf("biz and bizIds length mismatch")
 	}
 
@@ -131,8 +183,49 @@ func (r *RedisInteractionCache) BatchIncrViewCntIfPresent(ctx context.Context, b
 	for i := 0; i < len(biz); i++ {
 		// 使用 Eval 命令执行 Lua 脚本，增加浏览量
 		pipeline.Eval(ctx, luaIncrCnt, []string{r.Key(biz[i], bizIds[i])}, fieldViewCount, 1)
+		if unique[i] {
+			pipeline.Eval(ctx, luaIncrCnt, []string{r.Key(biz[i], bizIds[i])}, fieldUniqueViewCount, 1)
+		}
 	}
 	// 执行管道中所有排队的命令，将结果一次性返回
 	_, err := pipeline.Exec(ctx)
 	return err
-}
\ No newline at end of file
+}
+
+// AddViewers 用 HyperLogLog 记录每个窗口内的访客，PFADD 返回 1 说明是新访客
+// HyperLogLog 占用的内存固定，代价是有很小的概率把新访客误判为重复访客
+func (r *RedisInteractionCache) AddViewers(ctx context.Context, biz []string, bizIds []int64, visitors []string) ([]bool, error) {
+	if len(biz) != len(bizIds) || len(biz) != len(visitors) {
+		return nil, fmt.Errorf("biz, bizIds and visitors length mismatch")
+	}
+	if len(biz) == 0 {
+		return nil, nil
+	}
+
+	window := time.Now().Unix() / int64(uniqueViewWindow/time.Second)
+	pipeline := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(biz))
+	for i := range biz {
+		key := r.viewersKey(biz[i], bizIds[i], window)
+		cmds[i] = pipeline.PFAdd(ctx, key, visitors[i])
+		// 窗口结束后的键不再使用，多保留一个窗口后过期
+		pipeline.Expire(ctx, key, uniqueViewWindow*2)
+	}
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, err
+	}
+	res := make([]bool, len(cmds))
+	for i, cmd := range cmds {
+		res[i] = cmd.Val() == 1
+	}
+	return res, nil
+}
+
+func (r *RedisInteractionCache) viewersKey(biz string, bizId int64, window int64) string {
+	return "interaction:viewers:" + biz + ":" + strconv.FormatInt(bizId, 10) + ":" + strconv.FormatInt(window, 10)
+}
+
+// 删除交互信息缓存
+func (r *RedisInteractionCache) Del(ctx context.Context, biz string, bizId int64) error {
+	return r.client.Del(ctx, r.Key(biz, bizId)).Err()
+}
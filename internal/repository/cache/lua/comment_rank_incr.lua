@@ -0,0 +1,33 @@
+-- 增量更新一条根评论的热度，排行榜不存在时什么都不做，等待下次读取时重建
+local rankKey = KEYS[1] -- 排行榜 zset
+local cntKey = KEYS[2] -- 评论计数 hash
+local id = ARGV[1]
+local likeDelta = tonumber(ARGV[2])
+local replyDelta = tonumber(ARGV[3])
+local ctime = tonumber(ARGV[4]) -- 新评论的创建时间，更新已有评论时为 0
+local replyWeight = tonumber(ARGV[5])
+local decay = tonumber(ARGV[6])
+local ttl = tonumber(ARGV[7])
+
+if redis.call('EXISTS', rankKey) == 0 then
+    return 0
+end
+
+if ctime > 0 then
+    redis.call('HSET', cntKey, 't:' .. id, ctime)
+else
+    ctime = tonumber(redis.call('HGET', cntKey, 't:' .. id))
+    if ctime == nil then
+        return 0 -- 重建排行榜时被截掉的旧评论不参与排序
+    end
+end
+
+local likes = redis.call('HINCRBY', cntKey, 'l:' .. id, likeDelta)
+local replies = redis.call('HINCRBY', cntKey, 'r:' .. id, replyDelta)
+local weighted = math.max(likes, 0) + replyWeight * math.max(replies, 0)
+local score = math.log10(math.max(weighted, 1)) + ctime / decay
+
+redis.call('ZADD', rankKey, score, id)
+redis.call('EXPIRE', rankKey, ttl)
+redis.call('EXPIRE', cntKey, ttl)
+return 1
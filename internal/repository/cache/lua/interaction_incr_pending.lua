@@ -0,0 +1,16 @@
+-- 点赞量、收藏量的实时增量，同时记录到还没有写入数据库的增量中
+-- 计数不存在时返回 0，由调用方用数据库中的计数加上未写入的增量初始化
+local key = KEYS[1] -- 计数 hash
+local pendingKey = KEYS[2] -- 未写入数据库的增量 hash
+local field = ARGV[1]
+local delta = tonumber(ARGV[2])
+local pendingTTL = tonumber(ARGV[3])
+
+redis.call('HINCRBY', pendingKey, field, delta)
+redis.call('EXPIRE', pendingKey, pendingTTL)
+
+if redis.call('EXISTS', key) == 1 then
+    redis.call('HINCRBY', key, field, delta)
+    return 1
+end
+return 0
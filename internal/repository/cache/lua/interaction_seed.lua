@@ -0,0 +1,18 @@
+-- 用数据库中的计数初始化缓存，点赞量和收藏量加上还没有写入数据库的增量
+-- 缓存已经存在时不覆盖，已有的计数比数据库中的新
+local key = KEYS[1] -- 计数 hash
+local pendingKey = KEYS[2] -- 未写入数据库的增量 hash
+local ttl = tonumber(ARGV[1])
+
+if redis.call('EXISTS', key) == 1 then
+    return 0
+end
+
+-- 之后的参数是字段和数据库中的计数
+for i = 2, #ARGV, 2 do
+    local field = ARGV[i]
+    local cnt = tonumber(ARGV[i + 1]) + tonumber(redis.call('HGET', pendingKey, field) or 0)
+    redis.call('HSET', key, field, cnt)
+end
+redis.call('EXPIRE', key, ttl)
+return 1
@@ -0,0 +1,13 @@
+-- 增量写入数据库之后，从未写入数据库的增量中扣除
+-- 增量已经过期时不再处理，避免留下一个负数的增量
+local pendingKey = KEYS[1]
+
+if redis.call('EXISTS', pendingKey) == 0 then
+    return 0
+end
+
+-- 参数是字段和已经写入数据库的增量
+for i = 1, #ARGV, 2 do
+    redis.call('HINCRBY', pendingKey, ARGV[i], -tonumber(ARGV[i + 1]))
+end
+return 1
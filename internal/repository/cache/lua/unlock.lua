@@ -0,0 +1,6 @@
+-- 只有锁的值还是自己的 token 时才删除，避免删掉过期后被别人拿到的锁
+if redis.call("get", KEYS[1]) == ARGV[1] then
+    return redis.call("del", KEYS[1])
+else
+    return 0
+end
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 未读通知数缓存前缀
+	notificationUnreadKeyPrefix = "notification:unread:"
+	// 未读通知数缓存时间，通知变化时直接删除缓存
+	notificationUnreadExpiration = time.Minute * 10
+)
+
+type NotificationCache interface {
+	// GetUnread 获取未读通知数，缓存不存在时返回 ErrKeyNotExist
+	GetUnread(ctx context.Context, uid int64) (int64, error)
+	// SetUnread 设置未读通知数
+	SetUnread(ctx context.Context, uid int64, cnt int64) error
+	// DelUnread 删除未读通知数
+	DelUnread(ctx context.Context, uid int64) error
+}
+
+type RedisNotificationCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisNotificationCache(client redis.Cmdable) NotificationCache {
+	return &RedisNotificationCache{
+		client: client,
+	}
+}
+
+func (r *RedisNotificationCache) GetUnread(ctx context.Context, uid int64) (int64, error) {
+	return r.client.Get(ctx, r.unreadKey(uid)).Int64()
+}
+
+func (r *RedisNotificationCache) SetUnread(ctx context.Context, uid int64, cnt int64) error {
+	return r.client.Set(ctx, r.unreadKey(uid), cnt, notificationUnreadExpiration).Err()
+}
+
+func (r *RedisNotificationCache) DelUnread(ctx context.Context, uid int64) error {
+	return r.client.Del(ctx, r.unreadKey(uid)).Err()
+}
+
+func (r *RedisNotificationCache) unreadKey(uid int64) string {
+	return fmt.Sprintf("%s%d", notificationUnreadKeyPrefix, uid)
+}
@@ -30,6 +30,7 @@ func NewRedisRankingCache(client redis.Cmdable) *RedisRankingCache {
 func (r *RedisRankingCache) Set(ctx context.Context, articles []domain.Article) error {
 	for i := 0; i < len(articles); i++ {
 		articles[i].Content = ""
+		articles[i].HTML = ""
 	}
 	val, err := json.Marshal(articles)
 	if err != nil {
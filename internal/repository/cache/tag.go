@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 热门标签缓存前缀
+	trendingTagCacheKeyPrefix = "tag:trending:"
+	// 热门标签缓存时间
+	trendingTagExpiration = time.Minute * 5
+)
+
+type TagCache interface {
+	// GetTrending 获取热门标签缓存
+	GetTrending(ctx context.Context, limit int) ([]domain.Tag, error)
+	// SetTrending 设置热门标签缓存
+	SetTrending(ctx context.Context, limit int, tags []domain.Tag) error
+}
+
+type RedisTagCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisTagCache(client redis.Cmdable) TagCache {
+	return &RedisTagCache{
+		client: client,
+	}
+}
+
+// GetTrending 获取热门标签缓存
+func (r *RedisTagCache) GetTrending(ctx context.Context, limit int) ([]domain.Tag, error) {
+	data, err := r.client.Get(ctx, r.trendingKey(limit)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Tag
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+// SetTrending 设置热门标签缓存
+func (r *RedisTagCache) SetTrending(ctx context.Context, limit int, tags []domain.Tag) error {
+	val, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.trendingKey(limit), val, trendingTagExpiration).Err()
+}
+
+func (r *RedisTagCache) trendingKey(limit int) string {
+	return fmt.Sprintf("%s%d", trendingTagCacheKeyPrefix, limit)
+}
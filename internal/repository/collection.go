@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+var ErrCollectionNotFound = dao.ErrNotFound
+
+type CollectionRepository interface {
+	// Create 创建收藏夹
+	Create(ctx context.Context, c domain.Collection) (int64, error)
+	// Update 更新收藏夹，收藏夹不属于 c.Uid 时返回 ErrCollectionNotFound
+	Update(ctx context.Context, c domain.Collection) error
+	// Delete 删除收藏夹，其中的收藏移到默认收藏夹
+	Delete(ctx context.Context, id, uid int64) error
+	// FindByID 获取收藏夹，包含收藏数量
+	FindByID(ctx context.Context, id int64) (domain.Collection, error)
+	// FindByUid 获取用户的收藏夹，包含收藏数量
+	FindByUid(ctx context.Context, uid int64, onlyPublic bool, offset, limit int) ([]domain.Collection, error)
+	// FindItems 按收藏时间倒序获取收藏夹中的收藏，不包含文章信息
+	FindItems(ctx context.Context, uid, cid, beforeID int64, limit int) ([]domain.CollectionItem, error)
+	// MoveItem 把用户的一条收藏移到另一个收藏夹
+	MoveItem(ctx context.Context, uid int64, biz string, bizID, cid int64) error
+}
+
+type CollectionRepositoryImpl struct {
+	dao dao.CollectionDAO
+}
+
+func NewCollectionRepository(dao dao.CollectionDAO) CollectionRepository {
+	return &CollectionRepositoryImpl{
+		dao: dao,
+	}
+}
+
+// Create 创建收藏夹
+func (r *CollectionRepositoryImpl) Create(ctx context.Context, c domain.Collection) (int64, error) {
+	return r.dao.Insert(ctx, r.toEntity(c))
+}
+
+// Update 更新收藏夹
+func (r *CollectionRepositoryImpl) Update(ctx context.Context, c domain.Collection) error {
+	return r.dao.Update(ctx, r.toEntity(c))
+}
+
+// Delete 删除收藏夹
+func (r *CollectionRepositoryImpl) Delete(ctx context.Context, id, uid int64) error {
+	return r.dao.Delete(ctx, id, uid)
+}
+
+// FindByID 获取收藏夹，包含收藏数量
+func (r *CollectionRepositoryImpl) FindByID(ctx context.Context, id int64) (domain.Collection, error) {
+	c, err := r.dao.FindByID(ctx, id)
+	if err != nil {
+		return domain.Collection{}, err
+	}
+	counts, err := r.dao.CountItems(ctx, []int64{id})
+	if err != nil {
+		return domain.Collection{}, err
+	}
+	res := r.toDomain(c)
+	res.ItemCnt = counts[id]
+	return res, nil
+}
+
+// FindByUid 获取用户的收藏夹，包含收藏数量
+func (r *CollectionRepositoryImpl) FindByUid(ctx context.Context, uid int64, onlyPublic bool, offset, limit int) ([]domain.Collection, error) {
+	collections, err := r.dao.FindByUid(ctx, uid, onlyPublic, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(collections))
+	for _, c := range collections {
+		ids = append(ids, c.ID)
+	}
+	counts, err := r.dao.CountItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Collection, 0, len(collections))
+	for _, c := range collections {
+		dc := r.toDomain(c)
+		dc.ItemCnt = counts[c.ID]
+		res = append(res, dc)
+	}
+	return res, nil
+}
+
+// FindItems 按收藏时间倒序获取收藏夹中的收藏
+func (r *CollectionRepositoryImpl) FindItems(ctx context.Context, uid, cid, beforeID int64, limit int) ([]domain.CollectionItem, error) {
+	items, err := r.dao.FindItems(ctx, uid, cid, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.CollectionItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, domain.CollectionItem{
+			ID:           item.ID,
+			CollectionID: item.CollectionID,
+			Biz:          item.Biz,
+			BizID:        item.BizID,
+			Ctime:        time.UnixMilli(item.Ctime),
+		})
+	}
+	return res, nil
+}
+
+// MoveItem 把用户的一条收藏移到另一个收藏夹
+func (r *CollectionRepositoryImpl) MoveItem(ctx context.Context, uid int64, biz string, bizID, cid int64) error {
+	return r.dao.MoveItem(ctx, uid, biz, bizID, cid)
+}
+
+func (r *CollectionRepositoryImpl) toEntity(c domain.Collection) dao.Collection {
+	return dao.Collection{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		Public:      c.Public,
+		Uid:         c.Uid,
+	}
+}
+
+func (r *CollectionRepositoryImpl) toDomain(c dao.Collection) domain.Collection {
+	return domain.Collection{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		Public:      c.Public,
+		Uid:         c.Uid,
+		Ctime:       time.UnixMilli(c.Ctime),
+		Utime:       time.UnixMilli(c.Utime),
+	}
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/Fairy-nn/inspora/internal/domain"
@@ -9,6 +11,8 @@ import (
 	"github.com/Fairy-nn/inspora/internal/repository/dao"
 )
 
+var ErrCommentNotFound = dao.ErrNotFound
+
 type CommentRepository interface {
 	// CreateComment 创建评论
 	CreateComment(ctx context.Context, comment domain.Comment) (int64, error)
@@ -18,25 +22,38 @@ type CommentRepository interface {
 	GetRootComments(ctx context.Context, biz string, bizID int64, minID int64, limit int) ([]domain.Comment, error)
 	// GetChildrenComments 获取子评论列表
 	GetChildrenComments(ctx context.Context, parentID int64, minID int64, limit int) ([]domain.Comment, error)
-	// DeleteComment 删除评论及其所有子评论
+	// DeleteComment 软删除评论，子评论保留
 	DeleteComment(ctx context.Context, id int64) error
-	// GetHotComments 获取热门评论（按子评论数量降序）
+	// GetHotComments 获取热门评论（按点赞数、回复数和发布时间综合排序）
 	GetHotComments(ctx context.Context, biz string, bizID int64, limit int) ([]domain.Comment, error)
-	// PreloadArticleComments 预加载文章前三条评论及其子评论
-	PreloadArticleComments(ctx context.Context, articleID int64) error
+	// AddHotComment 把新的根评论加入热门评论排行
+	AddHotComment(ctx context.Context, biz string, bizID, id, ctime int64) error
+	// IncrHotScore 调整根评论在热门评论排行中的点赞数和回复数
+	IncrHotScore(ctx context.Context, biz string, bizID, id, likeDelta, replyDelta int64) error
+	// EditComment 编辑评论内容并保存历史版本，返回编辑后的评论
+	EditComment(ctx context.Context, id int64, content string, utime int64) (domain.Comment, error)
+	// GetCommentHistory 获取评论的历史版本，按时间倒序
+	GetCommentHistory(ctx context.Context, id int64) ([]domain.CommentRevision, error)
 	// GetUserById 获取用户信息
 	GetUserById(ctx context.Context, userID int64) (domain.User, error)
+	// DeleteByBiz 删除业务对象下的所有评论
+	DeleteByBiz(ctx context.Context, biz string, bizID int64) error
 }
 
+// 重建热门评论排行时最多读取的根评论数量，更早的评论热度已经衰减，不再参与排序
+const hotRankRebuildLimit = 1000
+
 type CachedCommentRepository struct {
-	dao   dao.CommentDAO
-	cache cache.CommentCache
+	dao       dao.CommentDAO
+	cache     cache.CommentCache
+	rankCache cache.CommentRankCache
 }
 
-func NewCachedCommentRepository(dao dao.CommentDAO, cache cache.CommentCache) CommentRepository {
+func NewCachedCommentRepository(dao dao.CommentDAO, cache cache.CommentCache, rankCache cache.CommentRankCache) CommentRepository {
 	return &CachedCommentRepository{
-		dao:   dao,
-		cache: cache,
+		dao:       dao,
+		cache:     cache,
+		rankCache: rankCache,
 	}
 }
 
@@ -75,7 +92,7 @@ func (r *CachedCommentRepository) CreateComment(ctx context.Context, comment dom
 func (r *CachedCommentRepository) GetComment(ctx context.Context, id int64) (domain.Comment, error) {
 	//先查缓存
 	comment, err := r.cache.GetComment(ctx, id)
-	if err != nil {
+	if err == nil {
 		return r.convertToModel(comment), nil
 	}
 
@@ -128,7 +145,7 @@ func (r *CachedCommentRepository) GetChildrenComments(ctx context.Context, paren
 	return comments, nil
 }
 
-// DeleteComment 删除评论及其所有子评论
+// DeleteComment 软删除评论，子评论保留
 func (r *CachedCommentRepository) DeleteComment(ctx context.Context, id int64) error {
 	// 先获取评论，确认存在并获取相关信息
 	comment, err := r.dao.GetByID(ctx, id)
@@ -136,65 +153,144 @@ func (r *CachedCommentRepository) DeleteComment(ctx context.Context, id int64) e
 		return err
 	}
 
-	// 删除数据库中的评论及其子评论
-	if err := r.dao.DeleteByID(ctx, id); err != nil {
+	// 把数据库中的评论标记为已删除
+	deleted, err := r.dao.DeleteByID(ctx, id)
+	if err != nil {
 		return err
 	}
 
 	// 删除缓存
 	_ = r.cache.DelComment(ctx, id)
+	if !deleted {
+		// 评论之前已经被删除
+		return nil
+	}
 
-	// 如果是文章评论，清除热门评论缓存
-	if comment.Biz == "article" {
-		_ = r.cache.DelHotComments(ctx, comment.Biz, comment.BizID)
+	// 更新热门评论排行：根评论直接移出，子评论扣减根评论的回复数
+	if comment.ParentID <= 0 {
+		_ = r.rankCache.Remove(ctx, comment.Biz, comment.BizID, comment.ID)
+	} else {
+		_ = r.rankCache.Incr(ctx, comment.Biz, comment.BizID, comment.RootID, 0, -1)
 	}
 
 	return nil
 }
 
-// GetHotComments 获取热门评论（按子评论数量降序）
+// DeleteByBiz 删除业务对象下的所有评论
+func (r *CachedCommentRepository) DeleteByBiz(ctx context.Context, biz string, bizID int64) error {
+	ids, err := r.dao.DeleteByBiz(ctx, biz, bizID)
+	if err != nil {
+		return err
+	}
+
+	// 删除缓存
+	for _, id := range ids {
+		_ = r.cache.DelComment(ctx, id)
+	}
+	return r.rankCache.Del(ctx, biz, bizID)
+}
+
+// GetHotComments 获取热门评论
+// 排行榜保存在缓存中，由评论、回复和点赞事件增量更新，只有排行榜不存在时才从数据库重建
 func (r *CachedCommentRepository) GetHotComments(ctx context.Context, biz string, bizID int64, limit int) ([]domain.Comment, error) {
-	// 先查缓存
-	commentDAO, err := r.cache.GetHotComments(ctx, biz, bizID)
-	if err == nil && len(commentDAO) > 0 {
-		// 转换为领域模型
-		comments := make([]domain.Comment, 0, len(commentDAO))
-		for _, comment := range commentDAO {
-			comments = append(comments, r.convertToModel(comment))
+	ids, err := r.rankCache.Top(ctx, biz, bizID, limit)
+	if err != nil {
+		if !errors.Is(err, cache.ErrKeyNotExist) {
+			return nil, err
+		}
+		ids, err = r.rebuildHotRank(ctx, biz, bizID, limit)
+		if err != nil {
+			return nil, err
 		}
-		return comments, nil
 	}
-	// 如果缓存不存在，则查询数据库
-	commentsDAO, err := r.dao.GetHotComments(ctx, biz, bizID, limit)
+	if len(ids) == 0 {
+		return []domain.Comment{}, nil
+	}
+
+	commentsDAO, err := r.dao.GetByIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
-	// 更新缓存
-	_ = r.cache.SetHotComments(ctx, biz, bizID, commentsDAO, time.Minute*15)
-	// 转换为领域模型
-	comments := make([]domain.Comment, 0, len(commentsDAO))
-	for _, commentDAO := range commentsDAO {
-		comments = append(comments, r.convertToModel(commentDAO))
+	byID := make(map[int64]dao.Comment, len(commentsDAO))
+	for _, c := range commentsDAO {
+		byID[c.ID] = c
+	}
+	// 按排行榜的顺序返回，已经被删除的评论直接跳过
+	comments := make([]domain.Comment, 0, len(ids))
+	for _, id := range ids {
+		if c, ok := byID[id]; ok && c.Status != dao.CommentStatusDeleted {
+			comments = append(comments, r.convertToModel(c))
+		}
 	}
-
 	return comments, nil
 }
 
-// PreloadArticleComments 预加载文章前三条评论及其子评论
-func (r *CachedCommentRepository) PreloadArticleComments(ctx context.Context, articleID int64) error {
-	// 获取文章前三条热门评论
-	// 获取文章前三条热门评论
-	comments, err := r.dao.GetHotComments(ctx, "article", articleID, 3)
+// rebuildHotRank 从数据库重建排行榜，并直接返回前 limit 条根评论ID
+func (r *CachedCommentRepository) rebuildHotRank(ctx context.Context, biz string, bizID int64, limit int) ([]int64, error) {
+	items, err := r.dao.GetEngagements(ctx, biz, bizID, hotRankRebuildLimit)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// 缓存写入失败不影响本次返回结果
+	_ = r.rankCache.Rebuild(ctx, biz, bizID, items)
+
+	scores := make(map[int64]float64, len(items))
+	for _, item := range items {
+		scores[item.ID] = domain.CommentHotScore(item.Likes, item.Replies, item.Ctime)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return scores[items[i].ID] > scores[items[j].ID]
+	})
+	ids := make([]int64, 0, min(limit, len(items)))
+	for _, item := range items[:min(limit, len(items))] {
+		ids = append(ids, item.ID)
+	}
+	return ids, nil
+}
+
+// AddHotComment 把新的根评论加入热门评论排行
+func (r *CachedCommentRepository) AddHotComment(ctx context.Context, biz string, bizID, id, ctime int64) error {
+	return r.rankCache.Add(ctx, biz, bizID, id, ctime)
+}
+
+// IncrHotScore 调整根评论在热门评论排行中的点赞数和回复数
+func (r *CachedCommentRepository) IncrHotScore(ctx context.Context, biz string, bizID, id, likeDelta, replyDelta int64) error {
+	return r.rankCache.Incr(ctx, biz, bizID, id, likeDelta, replyDelta)
+}
+
+// EditComment 编辑评论内容并保存历史版本
+// 热门评论排行只保存评论ID，内容总是从数据库读取，所以只需要更新单条评论的缓存
+func (r *CachedCommentRepository) EditComment(ctx context.Context, id int64, content string, utime int64) (domain.Comment, error) {
+	comment, err := r.dao.UpdateContent(ctx, id, content, utime)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	if err := r.cache.SetComment(ctx, comment, time.Minute*15); err != nil {
+		// 写缓存失败时删除旧缓存，避免读到编辑前的内容
+		_ = r.cache.DelComment(ctx, id)
 	}
+	return r.convertToModel(comment), nil
+}
 
-	// 预加载到缓存
-	return r.cache.PreloadComments(ctx, "article", articleID, comments)
+// GetCommentHistory 获取评论的历史版本
+func (r *CachedCommentRepository) GetCommentHistory(ctx context.Context, id int64) ([]domain.CommentRevision, error) {
+	history, err := r.dao.GetHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.CommentRevision, 0, len(history))
+	for _, h := range history {
+		res = append(res, domain.CommentRevision{
+			Content: h.Content,
+			Ctime:   h.Ctime,
+		})
+	}
+	return res, nil
 }
 
+// convertToModel 转换为领域模型，已删除的评论隐藏内容和作者，只保留楼层位置
 func (r *CachedCommentRepository) convertToModel(comment dao.Comment) domain.Comment {
-	return domain.Comment{
+	res := domain.Comment{
 		ID:       comment.ID,
 		Content:  comment.Content,
 		ParentID: comment.ParentID,
@@ -203,9 +299,17 @@ func (r *CachedCommentRepository) convertToModel(comment dao.Comment) domain.Com
 		BizID:    comment.BizID,
 		UserID:   comment.UserID,
 		UserName: comment.UserName,
-		//Status:    domain.CommentStatus(comment.Status),
-		Ctime: comment.Ctime,
+		Status:   domain.CommentStatus(comment.Status),
+		Ctime:    comment.Ctime,
+		Utime:    comment.Utime,
+	}
+	if res.IsDeleted() {
+		res.Content = domain.CommentDeletedPlaceholder
+		res.UserID = 0
+		res.UserName = ""
+		res.Utime = 0
 	}
+	return res
 }
 
 // GetUserById 获取用户信息
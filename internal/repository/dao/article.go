@@ -2,35 +2,59 @@ package dao
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// ErrVersionConflict 文章已被其他人修改，提交的版本号不是最新的
+var ErrVersionConflict = errors.New("文章已被其他人修改，请刷新后重试")
+
 type ArticleDaoInterface interface {
 	Insert(ctx context.Context, article *Article) (int64, error)
 	Update(ctx context.Context, article *Article) error
 	Sync(ctx context.Context, article *Article) (int64, error)
 	Upsert(ctx context.Context, article PublishArticle) error
 	SyncStatus(ctx context.Context, articleID, authorID int64, status uint8) error
-	FindByAuthor(ctx context.Context, authorID int64, offset, limit int) ([]Article, error)
+	// SetPublishedStatus 只修改线上库中文章的状态，文章当前的状态不是 from 时不修改
+	SetPublishedStatus(ctx context.Context, articleID, authorID int64, from, to uint8) (bool, error)
+	FindByAuthor(ctx context.Context, authorID int64, utime, id int64, limit int) ([]Article, error)
 	FindById(ctx context.Context, id, uid int64) (Article, error)
+	GetByID(ctx context.Context, id int64) (Article, error)
+	FindByCollaborator(ctx context.Context, userID int64, status uint8, utime, id int64, limit int) ([]Article, error)
 	FindPublicArticleById(ctx context.Context, id int64) (PublishArticle, error)
-	ListPublic(ctx context.Context, startTime time.Time, offset, limit int) ([]Article, error)
+	ListPublic(ctx context.Context, status uint8, utime, id int64, limit int) ([]PublishArticle, error)
+	ListPublicByAuthor(ctx context.Context, authorID int64, status uint8, utime, id int64, limit int) ([]PublishArticle, error)
+	ListPublicByTag(ctx context.Context, tag string, status uint8, offset, limit int) ([]PublishArticle, error)
+	FindPublicByIds(ctx context.Context, ids []int64, status uint8) ([]PublishArticle, error)
+	FindDueScheduled(ctx context.Context, status uint8, now int64, limit int) ([]Article, error)
+	// SyncScheduled 只有制作库中的文章仍处于 scheduledStatus 时才发布，抢占和同步在同一个事务中完成
+	SyncScheduled(ctx context.Context, article *Article, scheduledStatus uint8) (bool, error)
+	Delete(ctx context.Context, articleID, authorID int64, pubStatus uint8) error
+	DeletePublished(ctx context.Context, articleID int64) error
+	ImgUrlInUse(ctx context.Context, url string, excludeID int64) (bool, error)
 }
 
 // 这是制作库的数据库表结构
 type Article struct {
-	ID       int64  `gorm:"primaryKey,autoIncrement" json:"id"` // 文章ID
-	Title    string `gorm:"type:varchar(1024)" json:"title"`    // 文章标题
-	Content  string `gorm:"type:BLOB" json:"content"`           // 文章内容
-	AuthorID int64  `gorm:"index:aid_ctime" json:"author_id"`   // 作者ID
-	Ctime    int64  `gorm:"index:aid_ctime" json:"ctime"`       // 创建时间
-	Utime    int64  `json:"utime"`
-	Status   uint8  `json:"status"`                    // 文章状态
-	ImgUrls  string `gorm:"type:text" json:"img_urls"` // 图片地址
+	ID          int64  `gorm:"primaryKey,autoIncrement" json:"id"`                       // 文章ID
+	Title       string `gorm:"type:varchar(1024)" json:"title"`                          // 文章标题
+	Content     string `gorm:"type:BLOB" json:"content"`                                 // 文章内容
+	Format      uint8  `json:"format"`                                                   // 内容格式
+	ContentHTML string `gorm:"type:MEDIUMTEXT" json:"content_html"`                      // 渲染并清洗后的 HTML
+	AuthorID    int64  `gorm:"index:aid_ctime;index:aid_utime" json:"author_id"`         // 作者ID
+	Ctime       int64  `gorm:"index:aid_ctime" json:"ctime"`                             // 创建时间
+	Utime       int64  `gorm:"index:aid_utime;index:status_utime" json:"utime"`          // 更新时间，和作者ID、状态组成联合索引用于游标翻页
+	Status      uint8  `gorm:"index:status_publish_at;index:status_utime" json:"status"` // 文章状态
+	ImgUrls     string `gorm:"type:text" json:"img_urls"`                                // 图片地址
+	PublishAt   int64  `gorm:"index:status_publish_at" json:"publish_at"`                // 定时发布时间，和状态组成联合索引方便扫描到期文章
+	Tags        string `gorm:"type:varchar(1024)" json:"tags"`                           // 标签，JSON 数组
+	Version     int64  `gorm:"not null;default:1" json:"version"`                        // 版本号，每次修改内容加一，用于乐观锁
 }
 
 type ArticleGORMDAO struct {
@@ -53,6 +77,7 @@ func (a *ArticleGORMDAO) Insert(ctx context.Context, article *Article) (int64, e
 	now := time.Now().UnixMilli()
 	article.Ctime = now
 	article.Utime = now
+	article.Version = 1
 	err := a.db.WithContext(ctx).Create(&article).Error
 	return article.ID, err
 }
@@ -61,16 +86,26 @@ func (a *ArticleGORMDAO) Insert(ctx context.Context, article *Article) (int64, e
 func (a *ArticleGORMDAO) Update(ctx context.Context, article *Article) error {
 	now := time.Now().UnixMilli()
 	article.Utime = now
+	if article.Version <= 0 {
+		// 没有携带版本号的修改无法判断是否基于最新的内容，按冲突处理
+		return ErrVersionConflict
+	}
 	// 为了避免攻击者假冒用户修改其他用户的文章
 	// 使用 GORM 的 Updates 方法来更新文章的字段
+	// 乐观锁，只有版本号没有变化时才能更新，避免多人同时编辑时互相覆盖
 	res := a.db.WithContext(ctx).Model(article).
-		Where("id = ? AND author_id = ?", article.ID, article.AuthorID).
+		Where("id = ? AND author_id = ? AND version = ?", article.ID, article.AuthorID, article.Version).
 		Updates(map[string]any{
-			"Title":   article.Title,
-			"Content": article.Content,
-			"Utime":   article.Utime,
-			"Status":  uint8(article.Status), // 文章状态
-			"ImgUrls": article.ImgUrls, // 图片地址
+			"Title":       article.Title,
+			"Content":     article.Content,
+			"Format":      article.Format,
+			"ContentHTML": article.ContentHTML,
+			"Utime":       article.Utime,
+			"Status":      uint8(article.Status), // 文章状态
+			"ImgUrls":     article.ImgUrls,       // 图片地址
+			"PublishAt":   article.PublishAt,     // 定时发布时间
+			"Tags":        article.Tags,          // 标签
+			"Version":     gorm.Expr("version + 1"),
 		})
 	if res.Error != nil {
 		return res.Error
@@ -79,6 +114,16 @@ func (a *ArticleGORMDAO) Update(ctx context.Context, article *Article) error {
 	// 如果更新的行数为 0，表示没有更新任何行
 	if res.RowsAffected == 0 {
 		fmt.Println("没有更新任何行")
+		// 文章存在说明是版本号不一致
+		var cnt int64
+		err := a.db.WithContext(ctx).Model(&Article{}).
+			Where("id = ? AND author_id = ?", article.ID, article.AuthorID).Count(&cnt).Error
+		if err != nil {
+			return err
+		}
+		if cnt > 0 {
+			return ErrVersionConflict
+		}
 		return fmt.Errorf("没有更新,article id: %d,author id :%d", article.ID, article.AuthorID)
 	}
 
@@ -126,11 +171,15 @@ func (a *ArticleGORMDAO) Upsert(ctx context.Context, article PublishArticle) err
 	// 使用 GORM 的 Clauses 方法来执行 UPSERT 操作
 	res := a.db.Clauses(clause.OnConflict{
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"title":   article.Title,
-			"content": article.Content,
-			"utime":   article.Utime,
-			"status":  article.Status,
-			"img_urls": article.ImgUrls, // 图片地址
+			"title":        article.Title,
+			"content":      article.Content,
+			"format":       article.Format,
+			"content_html": article.ContentHTML,
+			"utime":        article.Utime,
+			"status":       article.Status,
+			"img_urls":     article.ImgUrls, // 图片地址
+			"publish_at":   article.PublishAt,
+			"tags":         article.Tags,
 		}),
 	}).Create(&article)
 	// MYSQL最终的语句是 INSERT INTO article (title, content, ctime, updated_at) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE title = ?, content = ?, updated_at = ?
@@ -166,10 +215,23 @@ func (a *ArticleGORMDAO) SyncStatus(ctx context.Context, articleID, authorID int
 	})
 }
 
-// FindByAuthor 根据作者ID查找文章
-func (a *ArticleGORMDAO) FindByAuthor(ctx context.Context, authorID int64, offset, limit int) ([]Article, error) {
+// SetPublishedStatus 只修改线上库中文章的状态，制作库中未发布的修改不受影响
+func (a *ArticleGORMDAO) SetPublishedStatus(ctx context.Context, articleID, authorID int64, from, to uint8) (bool, error) {
+	res := a.db.WithContext(ctx).Model(&PublishArticle{}).
+		Where("id = ? AND author_id = ? AND status = ?", articleID, authorID, from).
+		Updates(map[string]any{
+			"status": to,
+			"utime":  time.Now().UnixMilli(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// FindByAuthor 根据作者ID查找文章，按 (utime, id) 倒序，从游标 (utime, id) 之后开始
+func (a *ArticleGORMDAO) FindByAuthor(ctx context.Context, authorID int64, utime, id int64, limit int) ([]Article, error) {
 	var articles []Article
-	res := a.db.WithContext(ctx).Where("author_id = ?", authorID).Offset(offset).Limit(limit).Order("utime DESC").Find(&articles)
+	res := a.db.WithContext(ctx).Where("author_id = ?", authorID).
+		Scopes(afterCursor(utime, id)).
+		Order("utime DESC, id DESC").Limit(limit).Find(&articles)
 	if res.Error != nil {
 		return nil, res.Error
 	}
@@ -187,6 +249,24 @@ func (a *ArticleGORMDAO) FindById(ctx context.Context, id, uid int64) (Article,
 	return article, nil
 }
 
+// GetByID 根据文章ID查找文章，不校验作者，调用方需要自行校验权限
+func (a *ArticleGORMDAO) GetByID(ctx context.Context, id int64) (Article, error) {
+	var article Article
+	err := a.db.WithContext(ctx).Where("id = ?", id).First(&article).Error
+	return article, err
+}
+
+// FindByCollaborator 查找用户参与协作的文章，按 (utime, id) 倒序，从游标 (utime, id) 之后开始
+func (a *ArticleGORMDAO) FindByCollaborator(ctx context.Context, userID int64, status uint8, utime, id int64, limit int) ([]Article, error) {
+	var result []Article
+	err := a.db.WithContext(ctx).
+		Where("id IN (?)", a.db.Model(&ArticleCollaborator{}).Select("article_id").
+			Where("user_id = ? AND status = ?", userID, status)).
+		Scopes(afterCursor(utime, id)).
+		Order("utime DESC, id DESC").Limit(limit).Find(&result).Error
+	return result, err
+}
+
 // FindPublicArticleById 根据文章ID查找公开文章
 func (a *ArticleGORMDAO) FindPublicArticleById(ctx context.Context, id int64) (PublishArticle, error) {
 	var pub PublishArticle
@@ -194,9 +274,132 @@ func (a *ArticleGORMDAO) FindPublicArticleById(ctx context.Context, id int64) (P
 	return pub, err
 }
 
-// ListPublic 根据时间获取文章列表
-func (a *ArticleGORMDAO) ListPublic(ctx context.Context, startTime time.Time, offset, limit int) ([]Article, error) {
+// ListPublic 获取线上库中指定状态的文章，按 (utime, id) 倒序，从游标 (utime, id) 之后开始
+func (a *ArticleGORMDAO) ListPublic(ctx context.Context, status uint8, utime, id int64, limit int) ([]PublishArticle, error) {
+	var result []PublishArticle
+	err := a.db.WithContext(ctx).Where("status = ?", status).
+		Scopes(afterCursor(utime, id)).
+		Order("utime DESC, id DESC").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// ListPublicByAuthor 获取线上库中作者指定状态的文章，按 (utime, id) 倒序，从游标 (utime, id) 之后开始
+func (a *ArticleGORMDAO) ListPublicByAuthor(ctx context.Context, authorID int64, status uint8, utime, id int64, limit int) ([]PublishArticle, error) {
+	var result []PublishArticle
+	err := a.db.WithContext(ctx).Where("author_id = ? AND status = ?", authorID, status).
+		Scopes(afterCursor(utime, id)).
+		Order("utime DESC, id DESC").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// afterCursor 游标翻页条件，utime 为 0 表示从第一页开始
+// 用 (utime, id) 而不是 OFFSET 翻页，翻页过程中文章被更新也不会重复或遗漏
+func afterCursor(utime, id int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if utime <= 0 {
+			return db
+		}
+		return db.Where("(utime < ? OR (utime = ? AND id < ?))", utime, utime, id)
+	}
+}
+
+// ListPublicByTag 根据标签获取线上库中指定状态的文章，按更新时间倒序
+func (a *ArticleGORMDAO) ListPublicByTag(ctx context.Context, tag string, status uint8, offset, limit int) ([]PublishArticle, error) {
+	var result []PublishArticle
+	err := a.db.WithContext(ctx).Model(&PublishArticle{}).
+		Select("publish_articles.*").
+		Joins("JOIN article_tags ON article_tags.article_id = publish_articles.id").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("tags.name = ? AND publish_articles.status = ?", tag, status).
+		Order("publish_articles.utime DESC").
+		Offset(offset).Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// FindPublicByIds 根据文章ID批量查找线上库中指定状态的文章
+func (a *ArticleGORMDAO) FindPublicByIds(ctx context.Context, ids []int64, status uint8) ([]PublishArticle, error) {
+	var result []PublishArticle
+	if len(ids) == 0 {
+		return result, nil
+	}
+	err := a.db.WithContext(ctx).Where("id IN ? AND status = ?", ids, status).Find(&result).Error
+	return result, err
+}
+
+// FindDueScheduled 查找已经到达发布时间的定时发布文章
+func (a *ArticleGORMDAO) FindDueScheduled(ctx context.Context, status uint8, now int64, limit int) ([]Article, error) {
 	var result []Article
-	err := a.db.WithContext(ctx).Where("utime < ?", startTime.UnixMilli()).Order("utime DESC").Offset(offset).Limit(limit).Find(&result)
-	return result, err.Error
+	err := a.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", status, now).
+		Order("publish_at ASC").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// SyncScheduled 发布一篇定时文章
+// 先把制作库中的状态从 scheduledStatus 切换为发布状态来抢占文章，多个实例同时执行时只有一个实例能抢占成功，
+// 抢占和同步到线上库在同一个事务中提交，进程中途退出时事务回滚，文章仍是定时发布状态，下次任务会重试
+func (a *ArticleGORMDAO) SyncScheduled(ctx context.Context, article *Article, scheduledStatus uint8) (bool, error) {
+	claimed := false
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Article{}).
+			Where("id = ? AND author_id = ? AND status = ?", article.ID, article.AuthorID, scheduledStatus).
+			Updates(map[string]any{
+				"status": article.Status,
+				"utime":  time.Now().UnixMilli(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 已被其他实例发布，或者作者已经撤回/修改了文章
+			return nil
+		}
+		claimed = true
+
+		txDao := NewArticleDAO(tx)
+		if err := txDao.Update(ctx, article); err != nil {
+			return err
+		}
+		return txDao.Upsert(ctx, PublishArticle{Article: *article})
+	})
+	return claimed && err == nil, err
+}
+
+// Delete 彻底删除制作库中的文章，同时把线上库中的文章标记为 pubStatus，
+// 线上库中的记录由删除事件的消费者清理
+func (a *ArticleGORMDAO) Delete(ctx context.Context, articleID, authorID int64, pubStatus uint8) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND author_id = ?", articleID, authorID).Delete(&Article{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Model(&PublishArticle{}).Where("id = ?", articleID).Updates(
+			map[string]any{
+				"status": pubStatus,
+				"utime":  time.Now().UnixMilli(),
+			}).Error
+	})
+}
+
+// DeletePublished 删除线上库中的文章
+func (a *ArticleGORMDAO) DeletePublished(ctx context.Context, articleID int64) error {
+	return a.db.WithContext(ctx).Where("id = ?", articleID).Delete(&PublishArticle{}).Error
+}
+
+// ImgUrlInUse 判断除 excludeID 外是否还有文章引用了该图片
+func (a *ArticleGORMDAO) ImgUrlInUse(ctx context.Context, url string, excludeID int64) (bool, error) {
+	// img_urls 存储的是 JSON 数组，按序列化后的字符串（带引号）匹配，避免前缀相同的地址被误判
+	quoted, err := json.Marshal(url)
+	if err != nil {
+		return false, err
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(string(quoted))
+	var cnt int64
+	err = a.db.WithContext(ctx).Model(&Article{}).
+		Where("id <> ? AND img_urls LIKE ?", excludeID, "%"+escaped+"%").
+		Count(&cnt).Error
+	return cnt > 0, err
 }
@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArticleCollaborator 文章协作者表，作者本人不在表中
+type ArticleCollaborator struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID int64 `gorm:"uniqueIndex:article_user"`
+	UserID    int64 `gorm:"uniqueIndex:article_user;index:user_status"`
+	Role      uint8
+	Status    uint8 `gorm:"index:user_status"` // 邀请状态，接受邀请之后角色才生效
+	InviterID int64
+	Ctime     int64
+	Utime     int64
+}
+
+type ArticleCollaboratorDAO interface {
+	// Upsert 邀请协作者，已经是协作者时只更新角色，不改变邀请状态
+	Upsert(ctx context.Context, c ArticleCollaborator) error
+	// Find 查找用户在文章中的协作记录
+	Find(ctx context.Context, articleID, userID int64) (ArticleCollaborator, error)
+	// FindByArticle 查找文章的所有协作者
+	FindByArticle(ctx context.Context, articleID int64) ([]ArticleCollaborator, error)
+	// FindByUser 查找用户指定状态的协作记录
+	FindByUser(ctx context.Context, userID int64, status uint8) ([]ArticleCollaborator, error)
+	// UpdateStatus 仅当协作记录处于 oldStatus 时才切换到 newStatus，记录不存在时返回 ErrNotFound
+	UpdateStatus(ctx context.Context, articleID, userID int64, oldStatus, newStatus uint8) error
+	// Delete 删除协作记录，记录不存在时返回 ErrNotFound
+	Delete(ctx context.Context, articleID, userID int64) error
+	// DeleteByArticle 删除文章的所有协作记录
+	DeleteByArticle(ctx context.Context, articleID int64) error
+}
+
+type GORMArticleCollaboratorDAO struct {
+	db *gorm.DB
+}
+
+func NewArticleCollaboratorDAO(db *gorm.DB) ArticleCollaboratorDAO {
+	return &GORMArticleCollaboratorDAO{
+		db: db,
+	}
+}
+
+// Upsert 邀请协作者
+func (d *GORMArticleCollaboratorDAO) Upsert(ctx context.Context, c ArticleCollaborator) error {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"role":       c.Role,
+			"inviter_id": c.InviterID,
+			"utime":      now,
+		}),
+	}).Create(&c).Error
+}
+
+// Find 查找用户在文章中的协作记录
+func (d *GORMArticleCollaboratorDAO) Find(ctx context.Context, articleID, userID int64) (ArticleCollaborator, error) {
+	var c ArticleCollaborator
+	err := d.db.WithContext(ctx).
+		Where("article_id = ? AND user_id = ?", articleID, userID).First(&c).Error
+	return c, err
+}
+
+// FindByArticle 查找文章的所有协作者，按邀请时间排序
+func (d *GORMArticleCollaboratorDAO) FindByArticle(ctx context.Context, articleID int64) ([]ArticleCollaborator, error) {
+	var result []ArticleCollaborator
+	err := d.db.WithContext(ctx).Where("article_id = ?", articleID).
+		Order("id ASC").Find(&result).Error
+	return result, err
+}
+
+// FindByUser 查找用户指定状态的协作记录，最新的在前
+func (d *GORMArticleCollaboratorDAO) FindByUser(ctx context.Context, userID int64, status uint8) ([]ArticleCollaborator, error) {
+	var result []ArticleCollaborator
+	err := d.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, status).
+		Order("id DESC").Find(&result).Error
+	return result, err
+}
+
+// UpdateStatus 仅当协作记录处于 oldStatus 时才切换到 newStatus
+func (d *GORMArticleCollaboratorDAO) UpdateStatus(ctx context.Context, articleID, userID int64, oldStatus, newStatus uint8) error {
+	res := d.db.WithContext(ctx).Model(&ArticleCollaborator{}).
+		Where("article_id = ? AND user_id = ? AND status = ?", articleID, userID, oldStatus).
+		Updates(map[string]any{
+			"status": newStatus,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete 删除协作记录
+func (d *GORMArticleCollaboratorDAO) Delete(ctx context.Context, articleID, userID int64) error {
+	res := d.db.WithContext(ctx).
+		Where("article_id = ? AND user_id = ?", articleID, userID).Delete(&ArticleCollaborator{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByArticle 删除文章的所有协作记录
+func (d *GORMArticleCollaboratorDAO) DeleteByArticle(ctx context.Context, articleID int64) error {
+	return d.db.WithContext(ctx).Where("article_id = ?", articleID).Delete(&ArticleCollaborator{}).Error
+}
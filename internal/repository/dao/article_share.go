@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ArticleShare 文章分享链接表，撤销分享时直接删除记录
+type ArticleShare struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID int64 `gorm:"index"`
+	AuthorID  int64
+	Token     string `gorm:"type:varchar(64);uniqueIndex"`
+	ExpireAt  int64
+	Ctime     int64
+}
+
+type ArticleShareDAO interface {
+	// Insert 创建分享链接
+	Insert(ctx context.Context, s ArticleShare) (int64, error)
+	// FindByToken 根据令牌查找分享链接
+	FindByToken(ctx context.Context, token string) (ArticleShare, error)
+	// FindByArticle 查找作者为文章创建的所有分享链接
+	FindByArticle(ctx context.Context, articleID, authorID int64) ([]ArticleShare, error)
+	// Delete 删除作者的分享链接，记录不存在时返回 ErrNotFound
+	Delete(ctx context.Context, id, authorID int64) error
+}
+
+type GORMArticleShareDAO struct {
+	db *gorm.DB
+}
+
+func NewArticleShareDAO(db *gorm.DB) ArticleShareDAO {
+	return &GORMArticleShareDAO{
+		db: db,
+	}
+}
+
+// Insert 创建分享链接
+func (d *GORMArticleShareDAO) Insert(ctx context.Context, s ArticleShare) (int64, error) {
+	s.Ctime = time.Now().UnixMilli()
+	err := d.db.WithContext(ctx).Create(&s).Error
+	return s.ID, err
+}
+
+// FindByToken 根据令牌查找分享链接
+func (d *GORMArticleShareDAO) FindByToken(ctx context.Context, token string) (ArticleShare, error) {
+	var s ArticleShare
+	err := d.db.WithContext(ctx).Where("token = ?", token).First(&s).Error
+	return s, err
+}
+
+// FindByArticle 查找作者为文章创建的所有分享链接，最新创建的在前
+func (d *GORMArticleShareDAO) FindByArticle(ctx context.Context, articleID, authorID int64) ([]ArticleShare, error) {
+	var result []ArticleShare
+	err := d.db.WithContext(ctx).
+		Where("article_id = ? AND author_id = ?", articleID, authorID).
+		Order("id DESC").Find(&result).Error
+	return result, err
+}
+
+// Delete 删除作者的分享链接
+func (d *GORMArticleShareDAO) Delete(ctx context.Context, id, authorID int64) error {
+	res := d.db.WithContext(ctx).Where("id = ? AND author_id = ?", id, authorID).Delete(&ArticleShare{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
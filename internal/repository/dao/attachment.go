@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attachment 文章附件表
+type Attachment struct {
+	ID         int64  `gorm:"primaryKey,autoIncrement"`
+	ArticleID  int64  `gorm:"index"`
+	UploaderID int64  `gorm:"index"`
+	Filename   string `gorm:"type:varchar(255)"`
+	ObjectKey  string `gorm:"type:varchar(255);uniqueIndex"`
+	Size       int64
+	MimeType   string `gorm:"type:varchar(128)"`
+	SHA256     string `gorm:"type:char(64)"`
+	Ctime      int64
+	Utime      int64
+}
+
+type AttachmentDAO interface {
+	// Insert 新增附件
+	Insert(ctx context.Context, a Attachment) (int64, error)
+	// FindByID 根据ID获取附件
+	FindByID(ctx context.Context, id int64) (Attachment, error)
+	// FindByArticle 获取文章的所有附件，按上传时间排列
+	FindByArticle(ctx context.Context, articleID int64) ([]Attachment, error)
+	// Delete 删除附件
+	Delete(ctx context.Context, id int64) error
+}
+
+type GORMAttachmentDAO struct {
+	db *gorm.DB
+}
+
+func NewAttachmentDAO(db *gorm.DB) AttachmentDAO {
+	return &GORMAttachmentDAO{
+		db: db,
+	}
+}
+
+// Insert 新增附件
+func (d *GORMAttachmentDAO) Insert(ctx context.Context, a Attachment) (int64, error) {
+	now := time.Now().UnixMilli()
+	a.Ctime = now
+	a.Utime = now
+	err := d.db.WithContext(ctx).Create(&a).Error
+	return a.ID, err
+}
+
+// FindByID 根据ID获取附件
+func (d *GORMAttachmentDAO) FindByID(ctx context.Context, id int64) (Attachment, error) {
+	var a Attachment
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&a).Error
+	return a, err
+}
+
+// FindByArticle 获取文章的所有附件
+func (d *GORMAttachmentDAO) FindByArticle(ctx context.Context, articleID int64) ([]Attachment, error) {
+	var result []Attachment
+	err := d.db.WithContext(ctx).Where("article_id = ?", articleID).
+		Order("id ASC").Find(&result).Error
+	return result, err
+}
+
+// Delete 删除附件
+func (d *GORMAttachmentDAO) Delete(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Where("id = ?", id).Delete(&Attachment{}).Error
+}
@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserBlock 拉黑关系
+type UserBlock struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	UserID    int64 `gorm:"uniqueIndex:idx_user_blocked"` // 拉黑的用户
+	BlockedID int64 `gorm:"uniqueIndex:idx_user_blocked;index"`
+	Ctime     int64
+}
+
+// UserMute 屏蔽关系
+type UserMute struct {
+	ID      int64 `gorm:"primaryKey,autoIncrement"`
+	UserID  int64 `gorm:"uniqueIndex:idx_user_muted"` // 屏蔽的用户
+	MutedID int64 `gorm:"uniqueIndex:idx_user_muted"`
+	Ctime   int64
+}
+
+type BlockDAO interface {
+	// Insert 拉黑用户，重复拉黑直接忽略
+	Insert(ctx context.Context, b UserBlock) error
+	// Delete 取消拉黑
+	Delete(ctx context.Context, uid, blockedID int64) error
+	// ExistsEither 两个用户之间是否有任意一方拉黑了另一方
+	ExistsEither(ctx context.Context, a, b int64) (bool, error)
+	// List 获取用户拉黑的人，最近拉黑的在前
+	List(ctx context.Context, uid int64, offset, limit int) ([]UserBlock, error)
+	// InsertMute 屏蔽用户，重复屏蔽直接忽略
+	InsertMute(ctx context.Context, m UserMute) error
+	// DeleteMute 取消屏蔽
+	DeleteMute(ctx context.Context, uid, mutedID int64) error
+	// ListMuted 获取用户屏蔽的人，最近屏蔽的在前
+	ListMuted(ctx context.Context, uid int64, offset, limit int) ([]UserMute, error)
+	// HiddenIDs 获取用户拉黑和屏蔽的所有人
+	HiddenIDs(ctx context.Context, uid int64) ([]int64, error)
+}
+
+type GORMBlockDAO struct {
+	db *gorm.DB
+}
+
+func NewBlockDAO(db *gorm.DB) BlockDAO {
+	return &GORMBlockDAO{
+		db: db,
+	}
+}
+
+func (d *GORMBlockDAO) Insert(ctx context.Context, b UserBlock) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&b).Error
+}
+
+func (d *GORMBlockDAO) Delete(ctx context.Context, uid, blockedID int64) error {
+	return d.db.WithContext(ctx).Where("user_id = ? AND blocked_id = ?", uid, blockedID).
+		Delete(&UserBlock{}).Error
+}
+
+func (d *GORMBlockDAO) ExistsEither(ctx context.Context, a, b int64) (bool, error) {
+	var cnt int64
+	err := d.db.WithContext(ctx).Model(&UserBlock{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (d *GORMBlockDAO) List(ctx context.Context, uid int64, offset, limit int) ([]UserBlock, error) {
+	var res []UserBlock
+	err := d.db.WithContext(ctx).Where("user_id = ?", uid).
+		Order("id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (d *GORMBlockDAO) InsertMute(ctx context.Context, m UserMute) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&m).Error
+}
+
+func (d *GORMBlockDAO) DeleteMute(ctx context.Context, uid, mutedID int64) error {
+	return d.db.WithContext(ctx).Where("user_id = ? AND muted_id = ?", uid, mutedID).
+		Delete(&UserMute{}).Error
+}
+
+func (d *GORMBlockDAO) ListMuted(ctx context.Context, uid int64, offset, limit int) ([]UserMute, error) {
+	var res []UserMute
+	err := d.db.WithContext(ctx).Where("user_id = ?", uid).
+		Order("id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (d *GORMBlockDAO) HiddenIDs(ctx context.Context, uid int64) ([]int64, error) {
+	var blocked, muted []int64
+	err := d.db.WithContext(ctx).Model(&UserBlock{}).Where("user_id = ?", uid).
+		Pluck("blocked_id", &blocked).Error
+	if err != nil {
+		return nil, err
+	}
+	err = d.db.WithContext(ctx).Model(&UserMute{}).Where("user_id = ?", uid).
+		Pluck("muted_id", &muted).Error
+	if err != nil {
+		return nil, err
+	}
+	return append(blocked, muted...), nil
+}
@@ -0,0 +1,153 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CollectionDAO interface {
+	// Insert 创建收藏夹
+	Insert(ctx context.Context, c Collection) (int64, error)
+	// Update 更新收藏夹的名称、描述和公开状态，收藏夹不属于 c.Uid 时返回 ErrNotFound
+	Update(ctx context.Context, c Collection) error
+	// Delete 删除收藏夹，其中的收藏移到默认收藏夹
+	Delete(ctx context.Context, id, uid int64) error
+	// FindByID 根据ID查找收藏夹
+	FindByID(ctx context.Context, id int64) (Collection, error)
+	// FindByUid 查找用户的收藏夹，onlyPublic 为 true 时只返回公开的收藏夹
+	FindByUid(ctx context.Context, uid int64, onlyPublic bool, offset, limit int) ([]Collection, error)
+	// CountItems 统计收藏夹中的收藏数量
+	CountItems(ctx context.Context, ids []int64) (map[int64]int64, error)
+	// FindItems 按收藏时间倒序查找收藏夹中的收藏，beforeID 为 0 时从最新的开始
+	FindItems(ctx context.Context, uid, cid, beforeID int64, limit int) ([]UserCollectionBiz, error)
+	// MoveItem 把用户的一条收藏移到另一个收藏夹，没有收藏时返回 ErrNotFound
+	MoveItem(ctx context.Context, uid int64, biz string, bizID, cid int64) error
+}
+
+type GORMCollectionDAO struct {
+	db *gorm.DB
+}
+
+func NewCollectionDAO(db *gorm.DB) CollectionDAO {
+	return &GORMCollectionDAO{
+		db: db,
+	}
+}
+
+// Insert 创建收藏夹
+func (d *GORMCollectionDAO) Insert(ctx context.Context, c Collection) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := d.db.WithContext(ctx).Create(&c).Error
+	return c.ID, err
+}
+
+// Update 更新收藏夹的名称、描述和公开状态
+func (d *GORMCollectionDAO) Update(ctx context.Context, c Collection) error {
+	res := d.db.WithContext(ctx).Model(&Collection{}).
+		Where("id = ? AND uid = ?", c.ID, c.Uid).
+		Updates(map[string]any{
+			"name":        c.Name,
+			"description": c.Description,
+			"public":      c.Public,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete 删除收藏夹，其中的收藏移到默认收藏夹
+func (d *GORMCollectionDAO) Delete(ctx context.Context, id, uid int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND uid = ?", id, uid).Delete(&Collection{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Model(&UserCollectionBiz{}).
+			Where("collection_id = ? AND uid = ?", id, uid).
+			Updates(map[string]any{
+				"collection_id": 0,
+				"utime":         time.Now().UnixMilli(),
+			}).Error
+	})
+}
+
+// FindByID 根据ID查找收藏夹
+func (d *GORMCollectionDAO) FindByID(ctx context.Context, id int64) (Collection, error) {
+	var c Collection
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+// FindByUid 查找用户的收藏夹，最近创建的在前
+func (d *GORMCollectionDAO) FindByUid(ctx context.Context, uid int64, onlyPublic bool, offset, limit int) ([]Collection, error) {
+	var res []Collection
+	query := d.db.WithContext(ctx).Where("uid = ?", uid)
+	if onlyPublic {
+		query = query.Where("public = ?", true)
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+// CountItems 统计收藏夹中的收藏数量
+func (d *GORMCollectionDAO) CountItems(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		CollectionID int64
+		Cnt          int64
+	}
+	err := d.db.WithContext(ctx).Model(&UserCollectionBiz{}).
+		Select("collection_id, COUNT(*) AS cnt").
+		Where("collection_id IN ?", ids).
+		Group("collection_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		res[r.CollectionID] = r.Cnt
+	}
+	return res, nil
+}
+
+// FindItems 按收藏时间倒序查找收藏夹中的收藏
+func (d *GORMCollectionDAO) FindItems(ctx context.Context, uid, cid, beforeID int64, limit int) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	query := d.db.WithContext(ctx).Where("uid = ? AND collection_id = ?", uid, cid)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+// MoveItem 把用户的一条收藏移到另一个收藏夹
+func (d *GORMCollectionDAO) MoveItem(ctx context.Context, uid int64, biz string, bizID, cid int64) error {
+	res := d.db.WithContext(ctx).Model(&UserCollectionBiz{}).
+		Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizID).
+		Updates(map[string]any{
+			"collection_id": cid,
+			"utime":         time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Comment struct {
@@ -21,8 +22,31 @@ type Comment struct {
 	RootID int64 `gorm:"index;default:0"` // 根评论ID
 	Status uint8 `gorm:"default:1"`       // 评论状态
 	Ctime  int64 `gorm:"autoCreateTime"`  // 创建时间
+	Utime  int64 `gorm:"default:0"`       // 最后一次编辑的时间，0 表示没有编辑过
 }
 
+// CommentHistory 评论的历史版本，每次编辑前保存旧内容
+type CommentHistory struct {
+	ID        int64  `gorm:"primaryKey,autoIncrement"`
+	CommentID int64  `gorm:"index;not null"`
+	Content   string `gorm:"type:text;not null"`
+	Ctime     int64  // 这一版内容的发布时间，即评论的创建时间或上一次编辑的时间
+}
+
+// CommentEngagement 根评论的互动数据
+type CommentEngagement struct {
+	ID      int64
+	Ctime   int64
+	Likes   int64
+	Replies int64
+}
+
+// 评论状态，和 domain.CommentStatus 保持一致
+const (
+	CommentStatusNormal  uint8 = 1
+	CommentStatusDeleted uint8 = 2
+)
+
 type CommentDAO interface {
 	// Insert 插入评论
 	Insert(ctx context.Context, comment Comment) (int64, error)
@@ -34,12 +58,20 @@ type CommentDAO interface {
 	GetChildrenComments(ctx context.Context, parentID int64, minID int64, limit int) ([]Comment, error)
 	// CountChildrenComments 统计子评论数量
 	CountChildrenComments(ctx context.Context, parentID int64) (int64, error)
-	// DeleteByID 删除评论及其所有子评论
-	DeleteByID(ctx context.Context, id int64) error
-	// GetHotComments 获取热门评论（按子评论数量降序）
-	GetHotComments(ctx context.Context, biz string, bizID int64, limit int) ([]Comment, error)
+	// DeleteByID 软删除评论，子评论保留，返回评论是否由正常变为已删除
+	DeleteByID(ctx context.Context, id int64) (bool, error)
+	// GetByIDs 批量获取评论
+	GetByIDs(ctx context.Context, ids []int64) ([]Comment, error)
+	// GetEngagements 获取业务对象下最新 limit 条根评论的点赞数和回复数，用于重建热门评论排行
+	GetEngagements(ctx context.Context, biz string, bizID int64, limit int) ([]CommentEngagement, error)
+	// UpdateContent 编辑评论内容，旧内容保存为历史版本，返回编辑后的评论
+	UpdateContent(ctx context.Context, id int64, content string, utime int64) (Comment, error)
+	// GetHistory 获取评论的历史版本，按时间倒序
+	GetHistory(ctx context.Context, commentID int64) ([]CommentHistory, error)
 	// GetUserById 获取用户信息
 	GetUserById(ctx context.Context, userID int64) (User, error)
+	// DeleteByBiz 删除业务对象下的所有评论，返回被删除的评论ID
+	DeleteByBiz(ctx context.Context, biz string, bizID int64) ([]int64, error)
 }
 
 type CommentGORMDAO struct {
@@ -91,7 +123,9 @@ OFFSET + LIMIT 的问题：
 // GetRootComments 获取根评论列表
 func (c *CommentGORMDAO) GetRootComments(ctx context.Context, biz string, bizID int64, minID int64, limit int) ([]Comment, error) {
 	var comments []Comment
-	query := c.db.WithContext(ctx).Where("biz = ? AND biz_id = ? AND parent_id <= 0", biz, bizID)
+	query := c.db.WithContext(ctx).Where("biz = ? AND biz_id = ? AND parent_id <= 0", biz, bizID).
+		// 已删除且没有回复的评论不再展示，有回复的保留占位
+		Where("status = ? OR EXISTS (SELECT 1 FROM comments r WHERE r.root_id = comments.id)", CommentStatusNormal)
 	// 使用 minID 作为分页条件
 	if minID > 0 {
 		query = query.Where("id < ?", minID)
@@ -107,7 +141,8 @@ func (c *CommentGORMDAO) GetRootComments(ctx context.Context, biz string, bizID
 func (c *CommentGORMDAO) GetChildrenComments(ctx context.Context, parentID int64, minID int64, limit int) ([]Comment, error) {
 	var comments []Comment
 	// 找parentID对应的评论
-	query := c.db.WithContext(ctx).Where("parent_id = ?", parentID)
+	query := c.db.WithContext(ctx).Where("parent_id = ?", parentID).
+		Where("status = ? OR EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = comments.id)", CommentStatusNormal)
 	if minID > 0 {
 		// 使用 minID 作为分页条件
 		query = query.Where("id < ?", minID)
@@ -124,49 +159,120 @@ func (c *CommentGORMDAO) CountChildrenComments(ctx context.Context, parentID int
 	return count, err
 }
 
-// DeleteByID 删除评论及其所有子评论
-func (c *CommentGORMDAO) DeleteByID(ctx context.Context, id int64) error {
-	// 为了避免失败，这里使用事务，然后递归的删除子评论
-	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 递归删除子评论
-		return c.recursiveDelete(tx, id)
+// DeleteByID 软删除评论，只修改评论状态，子评论和楼层结构保持不变
+func (c *CommentGORMDAO) DeleteByID(ctx context.Context, id int64) (bool, error) {
+	res := c.db.WithContext(ctx).Model(&Comment{}).
+		Where("id = ? AND status = ?", id, CommentStatusNormal).
+		Update("status", CommentStatusDeleted)
+	return res.RowsAffected > 0, res.Error
+}
+
+// DeleteByBiz 删除业务对象下的所有评论，返回被删除的评论ID，方便上层清理缓存
+func (c *CommentGORMDAO) DeleteByBiz(ctx context.Context, biz string, bizID int64) ([]int64, error) {
+	var ids []int64
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Comment{}).Where("biz = ? AND biz_id = ?", biz, bizID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			if err := tx.Where("comment_id IN ?", ids).Delete(&CommentHistory{}).Error; err != nil {
+				return err
+			}
+		}
+		// 子评论和根评论属于同一个业务对象，一次删除即可
+		return tx.Where("biz = ? AND biz_id = ?", biz, bizID).Delete(&Comment{}).Error
 	})
+	return ids, err
 }
-func (c *CommentGORMDAO) recursiveDelete(tx *gorm.DB, id int64) error {
-	var childIDS []int64
-	if err := tx.Model(&Comment{}).Where("parent_id = ?", id).Pluck("id", &childIDS).Error; err != nil {
-		return err
-	}
-	// 递归删除子评论
-	for _, childID := range childIDS {
-		if err := c.recursiveDelete(tx, childID); err != nil {
+
+// UpdateContent 编辑评论内容，锁住评论行，避免并发编辑时丢失历史版本
+func (c *CommentGORMDAO) UpdateContent(ctx context.Context, id int64, content string, utime int64) (Comment, error) {
+	var comment Comment
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", id, CommentStatusNormal).First(&comment).Error
+		if err != nil {
 			return err
 		}
-	}
-	// 删除当前评论
-	return tx.Where("id = ?", id).Delete(&Comment{}).Error
+		version := comment.Ctime
+		if comment.Utime > 0 {
+			version = comment.Utime
+		}
+		err = tx.Create(&CommentHistory{
+			CommentID: id,
+			Content:   comment.Content,
+			Ctime:     version,
+		}).Error
+		if err != nil {
+			return err
+		}
+		comment.Content = content
+		comment.Utime = utime
+		return tx.Model(&Comment{}).Where("id = ?", id).Updates(map[string]any{
+			"content": content,
+			"utime":   utime,
+		}).Error
+	})
+	return comment, err
 }
 
-// GetHotComments 获取热门评论（按子评论数量降序）
-func (c *CommentGORMDAO) GetHotComments(ctx context.Context, biz string, bizID int64, limit int) ([]Comment, error) {
-	// 热门评论的定义是：子评论数量最多的评论
-	var comments []Comment
-	subQuery := c.db.WithContext(ctx).Model(&Comment{}).
-		Select("parent_id, COUNT(*) as child_count").
-		Where("parent_id > 0").
-		Group("parent_id")
-
-	err := c.db.WithContext(ctx).
-		Table("comments c").
-		Joins("LEFT JOIN (?) AS cc ON c.id = cc.parent_id", subQuery).
-		Where("c.biz = ? AND c.biz_id = ? AND c.parent_id <= 0", biz, bizID).
-		Order("COALESCE(cc.child_count, 0) DESC, c.id DESC").
-		Limit(limit).
-		Find(&comments).Error
+// GetHistory 获取评论的历史版本，按时间倒序
+func (c *CommentGORMDAO) GetHistory(ctx context.Context, commentID int64) ([]CommentHistory, error) {
+	var res []CommentHistory
+	err := c.db.WithContext(ctx).Where("comment_id = ?", commentID).Order("id DESC").Find(&res).Error
+	return res, err
+}
 
+// GetByIDs 批量获取评论，不保证返回顺序
+func (c *CommentGORMDAO) GetByIDs(ctx context.Context, ids []int64) ([]Comment, error) {
+	var comments []Comment
+	err := c.db.WithContext(ctx).Where("id IN ?", ids).Find(&comments).Error
 	return comments, err
 }
 
+// GetEngagements 获取业务对象下最新 limit 条根评论的点赞数和回复数
+func (c *CommentGORMDAO) GetEngagements(ctx context.Context, biz string, bizID int64, limit int) ([]CommentEngagement, error) {
+	var res []CommentEngagement
+	err := c.db.WithContext(ctx).Model(&Comment{}).Select("id, ctime").
+		Where("biz = ? AND biz_id = ? AND parent_id <= 0 AND status = ?", biz, bizID, CommentStatusNormal).
+		Order("id DESC").Limit(limit).Scan(&res).Error
+	if err != nil || len(res) == 0 {
+		return res, err
+	}
+	ids := make([]int64, 0, len(res))
+	index := make(map[int64]int, len(res))
+	for i, e := range res {
+		ids = append(ids, e.ID)
+		index[e.ID] = i
+	}
+
+	// 回复数：根评论下所有层级的子评论
+	var replies []struct {
+		RootID int64
+		Cnt    int64
+	}
+	err = c.db.WithContext(ctx).Model(&Comment{}).Select("root_id, COUNT(*) AS cnt").
+		Where("root_id IN ? AND status = ?", ids, CommentStatusNormal).Group("root_id").Scan(&replies).Error
+	if err != nil {
+		return nil, err
+	}
+	// 点赞数：评论的点赞走互动模块，biz 为 comment
+	var likes []InteractionDao
+	err = c.db.WithContext(ctx).Select("biz_id, like_count").
+		Where("biz = ? AND biz_id IN ?", "comment", ids).Find(&likes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range replies {
+		res[index[r.RootID]].Replies = r.Cnt
+	}
+	for _, l := range likes {
+		res[index[l.BizID]].Likes = l.LikeCount
+	}
+	return res, nil
+}
+
 // GetUserById 获取用户信息
 func (c *CommentGORMDAO) GetUserById(ctx context.Context, userID int64) (User, error) {
 	var user User
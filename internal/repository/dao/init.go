@@ -5,8 +5,17 @@ import (
 )
 
 func InitDB(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, &Article{}, &PublishArticle{},
+	err := db.AutoMigrate(&User{}, &Article{}, &PublishArticle{},
 		&InteractionDao{}, &UserLikeBiz{}, &Collection{},
 		&UserCollectionBiz{}, &Payment{}, &Reward{},
-		&Comment{}, &FollowRelation{}, &FollowStatistics{}, &FeedEvent{})
+		&Comment{}, &CommentHistory{}, &FollowRelation{}, &FollowStatistics{}, &FeedEvent{},
+		&Tag{}, &ArticleTag{}, &Series{}, &SeriesArticle{},
+		&ArticleShare{}, &ArticleCollaborator{}, &Upload{}, &Attachment{},
+		&Mention{}, &Notification{}, &NotificationActor{}, &NotificationMute{},
+		&UserBlock{}, &UserMute{}, &DirectMessage{}, &Conversation{}, &MessageSetting{})
+	if err != nil {
+		return err
+	}
+	// 版本号字段加入之前的文章版本号为 0，修改文章必须携带版本号，这里统一从 1 开始
+	return db.Model(&Article{}).Where("version = ?", 0).Update("version", 1).Error
 }
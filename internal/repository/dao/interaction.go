@@ -2,24 +2,31 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-var ErrNotFound = gorm.ErrRecordNotFound
+var (
+	ErrNotFound = gorm.ErrRecordNotFound
+	// ErrCollectedInOtherCollection 一篇文章只能收藏在一个收藏夹中，已经收藏在其他收藏夹
+	ErrCollectedInOtherCollection = errors.New("已经收藏在其他收藏夹中")
+)
 
 type InteractionDao struct {
-	ID           int64  `gorm:"primaryKey;autoIncrement" json:"id"`       // 文章ID
-	BizID        int64  `gorm:"uniqueIndex:idx_biz_id" json:"biz_id"`     // 业务ID
-	Biz          string `gorm:"uniqueIndex:idx_biz_id;type:varchar(255)"` // 业务线
-	ViewCount    int64  // 浏览量
-	LikeCount    int64  // 点赞量
-	CollectCount int64  // 收藏量
-	Ctime        int64  // 创建时间
-	Utime        int64  // 更新时间
+	ID              int64  `gorm:"primaryKey;autoIncrement" json:"id"`       // 文章ID
+	BizID           int64  `gorm:"uniqueIndex:idx_biz_id" json:"biz_id"`     // 业务ID
+	Biz             string `gorm:"uniqueIndex:idx_biz_id;type:varchar(255)"` // 业务线
+	ViewCount       int64  // 浏览量
+	UniqueViewCount int64  // 独立浏览量，同一访客在去重窗口内只算一次
+	LikeCount       int64  // 点赞量
+	CollectCount    int64  // 收藏量
+	Ctime           int64  // 创建时间
+	Utime           int64  // 更新时间
 }
 
 // 用户点赞表，用户点赞的某个文章
@@ -34,13 +41,15 @@ type UserLikeBiz struct {
 	Status uint8 // 交互状态
 }
 
-// 用户收藏表
+// 用户收藏夹表
 type Collection struct {
-	ID    int64  `gorm:"primaryKey,autoIncrement"`
-	Name  string `gorm:"type:varchar(255)"`
-	Uid   int64
-	Ctime int64
-	Utime int64
+	ID          int64  `gorm:"primaryKey,autoIncrement"`
+	Name        string `gorm:"type:varchar(255)"`
+	Description string `gorm:"type:varchar(1024)"`
+	Public      bool   // 公开的收藏夹其他用户也可以查看
+	Uid         int64  `gorm:"index"`
+	Ctime       int64
+	Utime       int64
 }
 
 // 用户收藏表
@@ -56,15 +65,25 @@ type UserCollectionBiz struct {
 
 type InteractionDaoInterface interface {
 	IncrViewCount(ctx context.Context, biz string, bizId int64) error
-	InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) error
+	// InsertLikeInfo 记录用户点赞，返回点赞状态是否发生了变化，点赞量由 BatchIncrCounts 批量更新
+	InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) (bool, error)
 	GetLikeInfo(ctx context.Context, biz string, bizId, uid int64) (UserLikeBiz, error)
-	DeleteLikeInfo(ctx context.Context, biz string, bizId, uid int64) error
+	// DeleteLikeInfo 取消用户点赞，返回点赞状态是否发生了变化
+	DeleteLikeInfo(ctx context.Context, biz string, bizId, uid int64) (bool, error)
 	Get(ctx context.Context, biz string, bizId int64) (InteractionDao, error)
-	InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) error
+	// InsertCollectionBiz 记录用户收藏，返回是否新增了收藏，收藏量由 BatchIncrCounts 批量更新
+	// 已经收藏在其他收藏夹时返回 ErrCollectedInOtherCollection
+	InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) (bool, error)
 	GetCollectionInfo(ctx context.Context, biz string, bizId, uid int64) (UserCollectionBiz, error)
-	DeleteCollectionInfo(ctx context.Context, biz string, bizId, uid int64) error
-	BatchIncrReadCnt(ctx context.Context, biz []string, bizIds []int64) error
+	// DeleteCollectionInfo 删除用户收藏，返回是否删除了收藏
+	DeleteCollectionInfo(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+	// BatchIncrCounts 批量累加点赞量和收藏量，deltas 中的计数是增量
+	BatchIncrCounts(ctx context.Context, deltas []InteractionDao) error
+	// BatchIncrReadCnt 批量增加浏览量，unique 中为 true 的同时增加独立浏览量
+	BatchIncrReadCnt(ctx context.Context, biz []string, bizIds []int64, unique []bool) error
 	GetByIds(ctx context.Context, biz string, ids []int64) ([]InteractionDao, error)
+	GetLikedBizIds(ctx context.Context, biz string, ids []int64, uid int64) ([]int64, error)
+	DeleteByBiz(ctx context.Context, biz string, bizId int64) error
 }
 
 type GormInteractionDAO struct {
@@ -79,6 +98,11 @@ func NewGormInteractionDAO(db *gorm.DB) InteractionDaoInterface {
 
 // IncrViewCount 增加浏览量
 func (i *GormInteractionDAO) IncrViewCount(ctx context.Context, biz string, bizId int64) error {
+	return i.incrViewCount(ctx, biz, bizId, 0)
+}
+
+// incrViewCount 浏览量加 1，独立浏览量加 unique
+func (i *GormInteractionDAO) incrViewCount(ctx context.Context, biz string, bizId int64, unique int64) error {
 	// 更新时间
 	now := time.Now().UnixMilli()
 	// 先查询是否存在记录，如果不存在则插入一条新记录
@@ -86,63 +110,56 @@ func (i *GormInteractionDAO) IncrViewCount(ctx context.Context, biz string, bizI
 		// 若记录已存在，则更新浏览量和更新时间
 		DoUpdates: clause.Assignments(map[string]any{
 			// 当发生冲突时，将 view_count 字段的值加 1
-			"view_count": gorm.Expr("view_count + 1"),
+			"view_count":        gorm.Expr("view_count + 1"),
+			"unique_view_count": gorm.Expr("unique_view_count + ?", unique),
 			// 当发生冲突时，将 updated_at 字段的值更新为当前时间的毫秒级时间戳
 			"utime": now,
 		}),
 	}).Create(&InteractionDao{ //没有主键冲突时，插入新记录
-		BizID:     bizId,
-		Biz:       biz,
-		ViewCount: 1,
-		Utime:     now,
-		Ctime:     now,
+		BizID:           bizId,
+		Biz:             biz,
+		ViewCount:       1,
+		UniqueViewCount: unique,
+		Utime:           now,
+		Ctime:           now,
 	}).Error
 }
 
 // InsertLikeInfo 插入点赞信息
-func (i *GormInteractionDAO) InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) error {
+// 这里只记录用户的点赞状态，不再在同一个事务里更新点赞量，热门文章的点赞不会在计数行上排队
+func (i *GormInteractionDAO) InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
 	now := time.Now().UnixMilli()
-	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.Clauses(clause.OnConflict{
-			// 如果记录已存在，则更新点赞量和更新时间
-			DoUpdates: clause.Assignments(map[string]any{
-				"status": 1, // 1代表点赞
-				"utime":  now,
-			}),
-		}).Create(&UserLikeBiz{
-			Biz:    biz,
-			BizID:  bizId,
-			Uid:    uid,
-			Utime:  now,
-			Status: 1,
-			Ctime:  now,
-		}).Error
-		if err != nil {
-			return err
-		}
+	// 之前取消过点赞的，恢复点赞状态
+	res := i.db.WithContext(ctx).Model(&UserLikeBiz{}).
+		Where("biz = ? AND biz_id = ? AND uid = ? AND status = ?", biz, bizId, uid, 0).
+		Updates(map[string]any{
+			"status": 1, // 1代表点赞
+			"utime":  now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
 
-		// 更新点赞量
-		return tx.Clauses(clause.OnConflict{
-			// 如果记录已存在，则更新点赞量和更新时间
-			DoUpdates: clause.Assignments(map[string]any{
-				"like_count": gorm.Expr("like_count + 1"),
-				"utime":      now,
-			}),
-		}).Create(&InteractionDao{
-			BizID:     bizId,
-			Biz:       biz,
-			LikeCount: 1,
-			Utime:     now,
-			Ctime:     now,
-		}).Error
+	// 第一次点赞时插入记录，已经点赞过的不做任何修改
+	res = i.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&UserLikeBiz{
+		Biz:    biz,
+		BizID:  bizId,
+		Uid:    uid,
+		Utime:  now,
+		Status: 1,
+		Ctime:  now,
 	})
+	return res.RowsAffected > 0, res.Error
 }
 
 // GetLikeInfo 获取点赞信息
 func (i *GormInteractionDAO) GetLikeInfo(ctx context.Context, biz string, bizId, uid int64) (UserLikeBiz, error) {
 	var likeInfo UserLikeBiz
 	// 查询点赞信息
-	err := i.db.WithContext(ctx).Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).First(&likeInfo).Error
+	err := i.db.WithContext(ctx).Where("biz = ? AND biz_id = ? AND uid = ? AND status = ?", biz, bizId, uid, 1).First(&likeInfo).Error
 	if err != nil {
 		return UserLikeBiz{}, err
 	}
@@ -151,29 +168,17 @@ func (i *GormInteractionDAO) GetLikeInfo(ctx context.Context, biz string, bizId,
 
 // DeleteLikeInfo 删除点赞信息
 // 这里的“删除”是软删除，即只是修改状态而不是物理删除记录
-func (i *GormInteractionDAO) DeleteLikeInfo(ctx context.Context, biz string, bizId, uid int64) error {
-	now := time.Now().UnixMilli()
-	// 这里使用事务来确保操作的原子性
-	// 先将点赞状态设置为 0，表示取消点赞,然后更新点赞量
-	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.Model(&UserLikeBiz{}).Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).Updates(
-			map[string]any{
-				// 将点赞状态设置为 0，表示取消点赞。
-				"status": 0,
-				// 更新记录的更新时间为当前时间
-				"updated_at": now,
-			}).Error
-		if err != nil {
-			return err
-		}
-
-		// 更新点赞量
-		return tx.Model(&InteractionDao{}).Where("biz = ? AND biz_id = ?", biz, bizId).Updates(
-			map[string]any{
-				"like_count": gorm.Expr("like_count - 1"),
-				"utime":      time.Now().UnixMilli(),
-			}).Error
-	})
+func (i *GormInteractionDAO) DeleteLikeInfo(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
+	// 只修改处于点赞状态的记录，重复取消不会让点赞量多减
+	res := i.db.WithContext(ctx).Model(&UserLikeBiz{}).
+		Where("biz = ? AND biz_id = ? AND uid = ? AND status = ?", biz, bizId, uid, 1).
+		Updates(map[string]any{
+			// 将点赞状态设置为 0，表示取消点赞。
+			"status": 0,
+			// 更新记录的更新时间为当前时间
+			"utime": time.Now().UnixMilli(),
+		})
+	return res.RowsAffected > 0, res.Error
 }
 
 // Get 获取交互信息
@@ -186,34 +191,24 @@ func (i *GormInteractionDAO) Get(ctx context.Context, biz string, bizId int64) (
 	return interaction, nil
 }
 
-// InsertCollectionBiz 插入收藏信息
-func (i *GormInteractionDAO) InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) error {
+// InsertCollectionBiz 插入收藏信息，已经收藏过的不做任何修改
+// 已经收藏在其他收藏夹时不会移动，返回的错误中带有原来的收藏夹ID，移动收藏使用 MoveItem
+func (i *GormInteractionDAO) InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) (bool, error) {
 	now := time.Now().UnixMilli()
 	cb.Utime = now
 	cb.Ctime = now
-	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 插入收藏信息到数据库
-		err := i.db.WithContext(ctx).Create(&cb).Error
-		if err != nil {
-			// 这里认为前端已经判断好了，不会重复插入
-			return err
-		}
-
-		// 更新收藏量
-		return tx.Clauses(clause.OnConflict{
-			// 如果记录已存在，则更新收藏量和更新时间
-			DoUpdates: clause.Assignments(map[string]any{
-				"collect_count": gorm.Expr("collect_count + 1"),
-				"utime":         now,
-			}),
-		}).Create(&InteractionDao{
-			BizID:        cb.BizID,
-			Biz:          cb.Biz,
-			CollectCount: 1,
-			Utime:        now,
-			Ctime:        now,
-		}).Error
-	})
+	res := i.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&cb)
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.RowsAffected > 0, res.Error
+	}
+	existing, err := i.GetCollectionInfo(ctx, cb.Biz, cb.BizID, cb.Uid)
+	if err != nil {
+		return false, err
+	}
+	if existing.CollectionID != cb.CollectionID {
+		return false, fmt.Errorf("%w: %d", ErrCollectedInOtherCollection, existing.CollectionID)
+	}
+	return false, nil
 }
 
 // GetCollectionInfo 获取收藏信息
@@ -232,31 +227,53 @@ func (i *GormInteractionDAO) GetCollectionInfo(ctx context.Context, biz string,
 }
 
 // DeleteCollectionInfo 删除收藏信息
-func (i *GormInteractionDAO) DeleteCollectionInfo(ctx context.Context, biz string, bizId, uid int64) error {
-	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 删除收藏信息
-		err := tx.Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).Delete(&UserCollectionBiz{}).Error
-		if err != nil {
-			return err
-		}
+func (i *GormInteractionDAO) DeleteCollectionInfo(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
+	res := i.db.WithContext(ctx).Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).Delete(&UserCollectionBiz{})
+	return res.RowsAffected > 0, res.Error
+}
 
-		// 更新收藏量
-		return tx.Model(&InteractionDao{}).Where("biz = ? AND biz_id = ?", biz, bizId).Updates(
-			map[string]any{
-				"collect_count": gorm.Expr("collect_count - 1"),
-				"utime":         time.Now().UnixMilli(),
-			}).Error
+// BatchIncrCounts 用一条多行 upsert 累加点赞量和收藏量
+// 按业务对象排序后写入，多个消费者同时刷新时加锁顺序一致，避免死锁
+func (i *GormInteractionDAO) BatchIncrCounts(ctx context.Context, deltas []InteractionDao) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	rows := make([]InteractionDao, len(deltas))
+	copy(rows, deltas)
+	sort.Slice(rows, func(a, b int) bool {
+		if rows[a].Biz != rows[b].Biz {
+			return rows[a].Biz < rows[b].Biz
+		}
+		return rows[a].BizID < rows[b].BizID
 	})
+	for idx := range rows {
+		rows[idx].ID = 0
+		rows[idx].Ctime = now
+		rows[idx].Utime = now
+	}
+	return i.db.WithContext(ctx).Clauses(clause.OnConflict{
+		// 记录已存在时在原来的计数上累加增量
+		DoUpdates: clause.Assignments(map[string]any{
+			"like_count":    gorm.Expr("like_count + VALUES(like_count)"),
+			"collect_count": gorm.Expr("collect_count + VALUES(collect_count)"),
+			"utime":         now,
+		}),
+	}).Create(&rows).Error
 }
 
 // 批量增加指定业务类型和业务 ID 对应的浏览计数
-func (i *GormInteractionDAO) BatchIncrReadCnt(ctx context.Context, biz []string, bizIds []int64) error {
+func (i *GormInteractionDAO) BatchIncrReadCnt(ctx context.Context, biz []string, bizIds []int64, unique []bool) error {
 	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		exDAO := NewGormInteractionDAO(tx)
+		exDAO := &GormInteractionDAO{db: tx}
 
 		for i, b := range biz {
-			// 调用 IncrViewCount 方法增加浏览量
-			err := exDAO.IncrViewCount(ctx, b, bizIds[i])
+			var uv int64
+			if unique[i] {
+				uv = 1
+			}
+			// 增加浏览量，去重后是新访客的同时增加独立浏览量
+			err := exDAO.incrViewCount(ctx, b, bizIds[i], uv)
 			if err != nil {
 				//return err
 				fmt.Println("增加浏览量失败:", err)
@@ -279,3 +296,25 @@ func (dao *GormInteractionDAO) GetByIds(ctx context.Context, biz string, ids []i
 	}
 	return interactions, nil
 }
+
+// GetLikedBizIds 返回 ids 中用户已点赞的业务ID
+func (dao *GormInteractionDAO) GetLikedBizIds(ctx context.Context, biz string, ids []int64, uid int64) ([]int64, error) {
+	var liked []int64
+	err := dao.db.WithContext(ctx).Model(&UserLikeBiz{}).
+		Where("biz = ? AND biz_id IN ? AND uid = ? AND status = ?", biz, ids, uid, 1).
+		Pluck("biz_id", &liked).Error
+	return liked, err
+}
+
+// DeleteByBiz 删除某个业务对象的计数以及所有用户的点赞、收藏记录
+func (dao *GormInteractionDAO) DeleteByBiz(ctx context.Context, biz string, bizId int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("biz = ? AND biz_id = ?", biz, bizId).Delete(&UserLikeBiz{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("biz = ? AND biz_id = ?", biz, bizId).Delete(&UserCollectionBiz{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("biz = ? AND biz_id = ?", biz, bizId).Delete(&InteractionDao{}).Error
+	})
+}
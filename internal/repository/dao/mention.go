@@ -0,0 +1,93 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Mention 内容中的 @ 提及，biz 为 article 或 comment
+type Mention struct {
+	ID          int64  `gorm:"primaryKey,autoIncrement"`
+	Biz         string `gorm:"type:varchar(20);uniqueIndex:idx_biz_user"`
+	BizID       int64  `gorm:"uniqueIndex:idx_biz_user"`
+	UserID      int64  `gorm:"uniqueIndex:idx_biz_user;index"` // 被提及的用户
+	Username    string `gorm:"type:varchar(100)"`              // 提及时使用的用户名
+	MentionerID int64  // 发出提及的用户
+	Ctime       int64
+}
+
+type MentionDAO interface {
+	// Replace 用 mentions 替换内容原有的提及，返回新增的提及
+	Replace(ctx context.Context, biz string, bizID int64, mentions []Mention) ([]Mention, error)
+	// FindByBiz 批量获取内容的提及
+	FindByBiz(ctx context.Context, biz string, bizIDs []int64) ([]Mention, error)
+	// DeleteByBiz 删除内容的所有提及
+	DeleteByBiz(ctx context.Context, biz string, bizIDs []int64) error
+}
+
+type GORMMentionDAO struct {
+	db *gorm.DB
+}
+
+func NewMentionDAO(db *gorm.DB) MentionDAO {
+	return &GORMMentionDAO{
+		db: db,
+	}
+}
+
+// Replace 用 mentions 替换内容原有的提及，返回新增的提及
+// 编辑内容时已经提及过的用户保持不变，不会重复通知
+func (d *GORMMentionDAO) Replace(ctx context.Context, biz string, bizID int64, mentions []Mention) ([]Mention, error) {
+	var added []Mention
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []Mention
+		if err := tx.Where("biz = ? AND biz_id = ?", biz, bizID).Find(&existing).Error; err != nil {
+			return err
+		}
+		keep := make(map[int64]bool, len(mentions))
+		for _, m := range mentions {
+			keep[m.UserID] = true
+		}
+		old := make(map[int64]bool, len(existing))
+		var removed []int64
+		for _, m := range existing {
+			old[m.UserID] = true
+			if !keep[m.UserID] {
+				removed = append(removed, m.ID)
+			}
+		}
+		if len(removed) > 0 {
+			if err := tx.Where("id IN ?", removed).Delete(&Mention{}).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UnixMilli()
+		for _, m := range mentions {
+			if old[m.UserID] {
+				continue
+			}
+			m.Biz, m.BizID, m.Ctime = biz, bizID, now
+			added = append(added, m)
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		return tx.Create(&added).Error
+	})
+	return added, err
+}
+
+// FindByBiz 批量获取内容的提及
+func (d *GORMMentionDAO) FindByBiz(ctx context.Context, biz string, bizIDs []int64) ([]Mention, error) {
+	var res []Mention
+	err := d.db.WithContext(ctx).Where("biz = ? AND biz_id IN ?", biz, bizIDs).Order("id").Find(&res).Error
+	return res, err
+}
+
+// DeleteByBiz 删除内容的所有提及
+func (d *GORMMentionDAO) DeleteByBiz(ctx context.Context, biz string, bizIDs []int64) error {
+	return d.db.WithContext(ctx).Where("biz = ? AND biz_id IN ?", biz, bizIDs).Delete(&Mention{}).Error
+}
@@ -0,0 +1,170 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DirectMessage 私信
+type DirectMessage struct {
+	ID         int64  `gorm:"primaryKey,autoIncrement"`
+	ConvKey    string `gorm:"type:varchar(64);index"` // 会话标识，按照 ID 自增的顺序翻页
+	SenderID   int64
+	ReceiverID int64
+	Content    string `gorm:"type:text"`
+	Ctime      int64
+}
+
+// Conversation 用户视角的会话，一条私信会同时更新双方的会话
+type Conversation struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	UserID    int64 `gorm:"uniqueIndex:idx_user_peer;index:idx_user_utime"`
+	PeerID    int64 `gorm:"uniqueIndex:idx_user_peer"`
+	LastMsgID int64
+	Unread    int64 // 对方发来的未读私信数
+	Ctime     int64
+	Utime     int64 `gorm:"index:idx_user_utime"`
+}
+
+// MessageSetting 用户的私信设置，没有记录时使用默认设置
+type MessageSetting struct {
+	ID     int64 `gorm:"primaryKey,autoIncrement"`
+	UserID int64 `gorm:"uniqueIndex"`
+	Policy uint8
+	Utime  int64
+}
+
+// ConvKey 两个用户之间的会话标识，和发送方向无关
+func ConvKey(a, b int64) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
+
+type MessageDAO interface {
+	// Insert 保存私信并更新双方的会话，接收方的未读数加一
+	Insert(ctx context.Context, msg DirectMessage) (int64, error)
+	// History 获取会话中 ID 小于 beforeID 的私信，beforeID 为 0 时从最新的开始
+	History(ctx context.Context, convKey string, beforeID int64, limit int) ([]DirectMessage, error)
+	// FindByIDs 批量获取私信
+	FindByIDs(ctx context.Context, ids []int64) ([]DirectMessage, error)
+	// ListConversations 按最近私信时间倒序获取用户的会话
+	ListConversations(ctx context.Context, uid int64, offset, limit int) ([]Conversation, error)
+	// MarkRead 清空会话的未读数
+	MarkRead(ctx context.Context, uid, peerID int64) error
+	// TotalUnread 统计用户所有会话的未读数
+	TotalUnread(ctx context.Context, uid int64) (int64, error)
+	// GetSetting 获取用户的私信设置，没有设置时返回 ErrNotFound
+	GetSetting(ctx context.Context, uid int64) (MessageSetting, error)
+	// UpsertSetting 保存用户的私信设置
+	UpsertSetting(ctx context.Context, s MessageSetting) error
+}
+
+type GORMMessageDAO struct {
+	db *gorm.DB
+}
+
+func NewMessageDAO(db *gorm.DB) MessageDAO {
+	return &GORMMessageDAO{
+		db: db,
+	}
+}
+
+func (d *GORMMessageDAO) Insert(ctx context.Context, msg DirectMessage) (int64, error) {
+	msg.ConvKey = ConvKey(msg.SenderID, msg.ReceiverID)
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&msg).Error; err != nil {
+			return err
+		}
+		// 发送方的会话只更新最近一条私信
+		err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"last_msg_id": msg.ID,
+				"utime":       msg.Ctime,
+			}),
+		}).Create(&Conversation{
+			UserID:    msg.SenderID,
+			PeerID:    msg.ReceiverID,
+			LastMsgID: msg.ID,
+			Ctime:     msg.Ctime,
+			Utime:     msg.Ctime,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"last_msg_id": msg.ID,
+				"unread":      gorm.Expr("unread + 1"),
+				"utime":       msg.Ctime,
+			}),
+		}).Create(&Conversation{
+			UserID:    msg.ReceiverID,
+			PeerID:    msg.SenderID,
+			LastMsgID: msg.ID,
+			Unread:    1,
+			Ctime:     msg.Ctime,
+			Utime:     msg.Ctime,
+		}).Error
+	})
+	return msg.ID, err
+}
+
+func (d *GORMMessageDAO) History(ctx context.Context, convKey string, beforeID int64, limit int) ([]DirectMessage, error) {
+	var res []DirectMessage
+	query := d.db.WithContext(ctx).Where("conv_key = ?", convKey)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (d *GORMMessageDAO) FindByIDs(ctx context.Context, ids []int64) ([]DirectMessage, error) {
+	var res []DirectMessage
+	if len(ids) == 0 {
+		return res, nil
+	}
+	err := d.db.WithContext(ctx).Where("id IN ?", ids).Find(&res).Error
+	return res, err
+}
+
+func (d *GORMMessageDAO) ListConversations(ctx context.Context, uid int64, offset, limit int) ([]Conversation, error) {
+	var res []Conversation
+	err := d.db.WithContext(ctx).Where("user_id = ?", uid).
+		Order("utime DESC, id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (d *GORMMessageDAO) MarkRead(ctx context.Context, uid, peerID int64) error {
+	return d.db.WithContext(ctx).Model(&Conversation{}).
+		Where("user_id = ? AND peer_id = ? AND unread > 0", uid, peerID).
+		Update("unread", 0).Error
+}
+
+func (d *GORMMessageDAO) TotalUnread(ctx context.Context, uid int64) (int64, error) {
+	var total int64
+	err := d.db.WithContext(ctx).Model(&Conversation{}).
+		Where("user_id = ? AND unread > 0", uid).
+		Select("COALESCE(SUM(unread), 0)").Scan(&total).Error
+	return total, err
+}
+
+func (d *GORMMessageDAO) GetSetting(ctx context.Context, uid int64) (MessageSetting, error) {
+	var s MessageSetting
+	err := d.db.WithContext(ctx).Where("user_id = ?", uid).First(&s).Error
+	return s, err
+}
+
+func (d *GORMMessageDAO) UpsertSetting(ctx context.Context, s MessageSetting) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"policy": s.Policy,
+			"utime":  s.Utime,
+		}),
+	}).Create(&s).Error
+}
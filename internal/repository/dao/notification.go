@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Notification 站内通知
+type Notification struct {
+	ID     int64 `gorm:"primaryKey,autoIncrement"`
+	UserID int64 `gorm:"uniqueIndex:idx_user_group;index:idx_user_read;index:idx_user_utime"`
+	// 未读的可聚合通知才有分组，标记已读后置为 NULL，之后的事件会生成新的通知
+	// MySQL 唯一索引允许多个 NULL，不参与聚合的通知互不冲突
+	GroupKey  *string `gorm:"type:varchar(128);uniqueIndex:idx_user_group"`
+	Type      string  `gorm:"type:varchar(20)"`
+	Biz       string  `gorm:"type:varchar(20)"`
+	BizID     int64
+	ArticleID int64
+	ActorID   int64 // 最近一次触发通知的用户
+	ActorCnt  int64
+	Content   string `gorm:"type:varchar(1024)"`
+	Amount    int64
+	ReadTime  int64 `gorm:"index:idx_user_read"` // 0 表示未读
+	Ctime     int64
+	Utime     int64 `gorm:"index:idx_user_utime"`
+}
+
+// NotificationActor 聚合通知的触发用户，同一个用户在一条通知里只计数一次
+type NotificationActor struct {
+	ID             int64 `gorm:"primaryKey,autoIncrement"`
+	NotificationID int64 `gorm:"uniqueIndex:idx_notification_actor"`
+	ActorID        int64 `gorm:"uniqueIndex:idx_notification_actor"`
+	Ctime          int64
+}
+
+// NotificationMute 用户关闭的通知类型
+type NotificationMute struct {
+	ID     int64  `gorm:"primaryKey,autoIncrement"`
+	UserID int64  `gorm:"uniqueIndex:idx_user_type"`
+	Type   string `gorm:"type:varchar(20);uniqueIndex:idx_user_type"`
+	Ctime  int64
+}
+
+type NotificationDAO interface {
+	// Insert 插入通知，有分组的通知和同组未读的通知合并，返回是否新增了一条未读通知
+	Insert(ctx context.Context, n Notification) (bool, error)
+	// List 按最近触发时间倒序获取用户的通知
+	List(ctx context.Context, uid int64, offset, limit int) ([]Notification, error)
+	// CountUnread 统计用户的未读通知数
+	CountUnread(ctx context.Context, uid int64) (int64, error)
+	// MarkRead 标记通知为已读，返回通知之前是否未读
+	MarkRead(ctx context.Context, uid, id, now int64) (bool, error)
+	// MarkAllRead 标记用户的所有通知为已读
+	MarkAllRead(ctx context.Context, uid, now int64) error
+	// GetMutedTypes 获取用户关闭的通知类型
+	GetMutedTypes(ctx context.Context, uid int64) ([]string, error)
+	// SetMuted 关闭或打开某类通知
+	SetMuted(ctx context.Context, uid int64, typ string, muted bool, now int64) error
+}
+
+type GORMNotificationDAO struct {
+	db *gorm.DB
+}
+
+func NewNotificationDAO(db *gorm.DB) NotificationDAO {
+	return &GORMNotificationDAO{
+		db: db,
+	}
+}
+
+func (d *GORMNotificationDAO) Insert(ctx context.Context, n Notification) (bool, error) {
+	if n.GroupKey == nil {
+		err := d.db.WithContext(ctx).Create(&n).Error
+		return err == nil, err
+	}
+	var created bool
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 同组的未读通知已存在时只更新最近的触发用户和时间，人数根据触发用户表累加
+		res := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"actor_id", "utime"}),
+		}).Create(&n)
+		if res.Error != nil {
+			return res.Error
+		}
+		// 插入新记录时影响行数为 1，更新已有记录时为 2
+		created = res.RowsAffected == 1
+		if !created {
+			// 更新已有记录时拿不到自增ID，在同一个事务里按分组查出来，行锁保证分组不会被并发修改
+			var existing Notification
+			err := tx.Select("id").
+				Where("user_id = ? AND group_key = ?", n.UserID, *n.GroupKey).
+				Take(&existing).Error
+			if err != nil {
+				return err
+			}
+			n.ID = existing.ID
+		}
+		// 触发用户已经记录过的不再计数，只有新的触发用户才累加人数
+		res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&NotificationActor{
+			NotificationID: n.ID,
+			ActorID:        n.ActorID,
+			Ctime:          n.Utime,
+		})
+		if res.Error != nil || created || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&Notification{}).Where("id = ?", n.ID).
+			Update("actor_cnt", gorm.Expr("actor_cnt + 1")).Error
+	})
+	return created, err
+}
+
+func (d *GORMNotificationDAO) List(ctx context.Context, uid int64, offset, limit int) ([]Notification, error) {
+	var res []Notification
+	err := d.db.WithContext(ctx).Where("user_id = ?", uid).
+		Order("utime DESC, id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (d *GORMNotificationDAO) CountUnread(ctx context.Context, uid int64) (int64, error) {
+	var cnt int64
+	err := d.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_time = 0", uid).Count(&cnt).Error
+	return cnt, err
+}
+
+func (d *GORMNotificationDAO) MarkRead(ctx context.Context, uid, id, now int64) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&Notification{}).
+		Where("id = ? AND user_id = ? AND read_time = 0", id, uid).
+		Updates(map[string]any{
+			"read_time": now,
+			"group_key": nil,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (d *GORMNotificationDAO) MarkAllRead(ctx context.Context, uid, now int64) error {
+	return d.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_time = 0", uid).
+		Updates(map[string]any{
+			"read_time": now,
+			"group_key": nil,
+		}).Error
+}
+
+func (d *GORMNotificationDAO) GetMutedTypes(ctx context.Context, uid int64) ([]string, error) {
+	var types []string
+	err := d.db.WithContext(ctx).Model(&NotificationMute{}).
+		Where("user_id = ?", uid).Pluck("type", &types).Error
+	return types, err
+}
+
+func (d *GORMNotificationDAO) SetMuted(ctx context.Context, uid int64, typ string, muted bool, now int64) error {
+	if !muted {
+		return d.db.WithContext(ctx).Where("user_id = ? AND type = ?", uid, typ).
+			Delete(&NotificationMute{}).Error
+	}
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&NotificationMute{UserID: uid, Type: typ, Ctime: now}).Error
+}
@@ -0,0 +1,178 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSeriesArticleExists  = errors.New("文章已经属于某个系列")
+	ErrSeriesArticleInvalid = errors.New("排序的文章和系列中的文章不一致")
+)
+
+// Series 文章系列表
+type Series struct {
+	ID          int64  `gorm:"primaryKey,autoIncrement"`
+	Title       string `gorm:"type:varchar(256)"`
+	Description string `gorm:"type:varchar(1024)"`
+	AuthorID    int64  `gorm:"index"`
+	Ctime       int64
+	Utime       int64
+}
+
+// SeriesArticle 系列和文章的关联表，一篇文章最多属于一个系列
+type SeriesArticle struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	SeriesID  int64 `gorm:"index:series_position"`
+	ArticleID int64 `gorm:"uniqueIndex"`
+	Position  int   `gorm:"index:series_position"` // 文章在系列中的位置，从小到大排列
+	Ctime     int64
+}
+
+type SeriesDAO interface {
+	// Insert 创建系列
+	Insert(ctx context.Context, s Series) (int64, error)
+	// FindByID 根据ID查找系列
+	FindByID(ctx context.Context, id int64) (Series, error)
+	// FindByAuthor 查找作者的所有系列
+	FindByAuthor(ctx context.Context, authorID int64) ([]Series, error)
+	// FindArticles 按顺序查找系列中的文章
+	FindArticles(ctx context.Context, seriesID int64) ([]SeriesArticle, error)
+	// FindByArticleID 查找文章所在的系列关联
+	FindByArticleID(ctx context.Context, articleID int64) (SeriesArticle, error)
+	// AddArticle 将文章追加到系列末尾
+	AddArticle(ctx context.Context, seriesID, articleID int64) error
+	// RemoveArticle 从系列中移除文章
+	RemoveArticle(ctx context.Context, seriesID, articleID int64) error
+	// Reorder 按 articleIDs 的顺序重新排列系列中的文章
+	Reorder(ctx context.Context, seriesID int64, articleIDs []int64) error
+}
+
+type GORMSeriesDAO struct {
+	db *gorm.DB
+}
+
+func NewSeriesDAO(db *gorm.DB) SeriesDAO {
+	return &GORMSeriesDAO{
+		db: db,
+	}
+}
+
+// Insert 创建系列
+func (d *GORMSeriesDAO) Insert(ctx context.Context, s Series) (int64, error) {
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	err := d.db.WithContext(ctx).Create(&s).Error
+	return s.ID, err
+}
+
+// FindByID 根据ID查找系列
+func (d *GORMSeriesDAO) FindByID(ctx context.Context, id int64) (Series, error) {
+	var s Series
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&s).Error
+	return s, err
+}
+
+// FindByAuthor 查找作者的所有系列
+func (d *GORMSeriesDAO) FindByAuthor(ctx context.Context, authorID int64) ([]Series, error) {
+	var res []Series
+	err := d.db.WithContext(ctx).Where("author_id = ?", authorID).Order("utime DESC").Find(&res).Error
+	return res, err
+}
+
+// FindArticles 按顺序查找系列中的文章
+func (d *GORMSeriesDAO) FindArticles(ctx context.Context, seriesID int64) ([]SeriesArticle, error) {
+	var res []SeriesArticle
+	err := d.db.WithContext(ctx).Where("series_id = ?", seriesID).Order("position ASC").Find(&res).Error
+	return res, err
+}
+
+// FindByArticleID 查找文章所在的系列关联
+func (d *GORMSeriesDAO) FindByArticleID(ctx context.Context, articleID int64) (SeriesArticle, error) {
+	var res SeriesArticle
+	err := d.db.WithContext(ctx).Where("article_id = ?", articleID).First(&res).Error
+	return res, err
+}
+
+// AddArticle 将文章追加到系列末尾
+func (d *GORMSeriesDAO) AddArticle(ctx context.Context, seriesID, articleID int64) error {
+	now := time.Now().UnixMilli()
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cnt int64
+		if err := tx.Model(&SeriesArticle{}).Where("article_id = ?", articleID).Count(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt > 0 {
+			return ErrSeriesArticleExists
+		}
+
+		// 追加到末尾
+		var maxPos int
+		err := tx.Model(&SeriesArticle{}).Where("series_id = ?", seriesID).
+			Select("COALESCE(MAX(position), 0)").Scan(&maxPos).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&SeriesArticle{
+			SeriesID:  seriesID,
+			ArticleID: articleID,
+			Position:  maxPos + 1,
+			Ctime:     now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return d.touch(tx, seriesID, now)
+	})
+}
+
+// RemoveArticle 从系列中移除文章
+func (d *GORMSeriesDAO) RemoveArticle(ctx context.Context, seriesID, articleID int64) error {
+	now := time.Now().UnixMilli()
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("series_id = ? AND article_id = ?", seriesID, articleID).Delete(&SeriesArticle{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return d.touch(tx, seriesID, now)
+	})
+}
+
+// Reorder 按 articleIDs 的顺序重新排列系列中的文章
+func (d *GORMSeriesDAO) Reorder(ctx context.Context, seriesID int64, articleIDs []int64) error {
+	now := time.Now().UnixMilli()
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []int64
+		err := tx.Model(&SeriesArticle{}).Where("series_id = ?", seriesID).Pluck("article_id", &existing).Error
+		if err != nil {
+			return err
+		}
+		// 新的顺序必须恰好包含系列中的所有文章
+		added, removed := diffIDs(existing, articleIDs)
+		if len(added) > 0 || len(removed) > 0 || len(existing) != len(articleIDs) {
+			return ErrSeriesArticleInvalid
+		}
+
+		for i, articleID := range articleIDs {
+			err = tx.Model(&SeriesArticle{}).
+				Where("series_id = ? AND article_id = ?", seriesID, articleID).
+				Update("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return d.touch(tx, seriesID, now)
+	})
+}
+
+// touch 更新系列的更新时间
+func (d *GORMSeriesDAO) touch(tx *gorm.DB, seriesID int64, now int64) error {
+	return tx.Model(&Series{}).Where("id = ?", seriesID).Update("utime", now).Error
+}
@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tag 标签表
+type Tag struct {
+	ID         int64  `gorm:"primaryKey,autoIncrement"`
+	Name       string `gorm:"type:varchar(64);uniqueIndex"` // 标准化之后的标签名
+	ArticleCnt int64  `gorm:"index"`                        // 使用该标签的已发布文章数量
+	Ctime      int64
+	Utime      int64
+}
+
+// ArticleTag 文章和标签的关联表，只记录已发布的文章
+type ArticleTag struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID int64 `gorm:"uniqueIndex:article_tag"`
+	TagID     int64 `gorm:"uniqueIndex:article_tag;index:tag_ctime"`
+	Ctime     int64 `gorm:"index:tag_ctime"`
+}
+
+type TagDAO interface {
+	// SetArticleTags 将文章的标签替换为 names，并维护标签的文章数量
+	SetArticleTags(ctx context.Context, articleID int64, names []string) error
+	// FindTrending 查找 since 之后被使用最多的标签
+	FindTrending(ctx context.Context, since int64, limit int) ([]Tag, error)
+	// FindByPrefix 根据前缀查找标签，用于自动补全
+	FindByPrefix(ctx context.Context, prefix string, limit int) ([]Tag, error)
+}
+
+type GORMTagDAO struct {
+	db *gorm.DB
+}
+
+func NewTagDAO(db *gorm.DB) TagDAO {
+	return &GORMTagDAO{
+		db: db,
+	}
+}
+
+// SetArticleTags 将文章的标签替换为 names，并维护标签的文章数量
+func (d *GORMTagDAO) SetArticleTags(ctx context.Context, articleID int64, names []string) error {
+	now := time.Now().UnixMilli()
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 文章当前关联的标签
+		var oldTagIDs []int64
+		err := tx.Model(&ArticleTag{}).Where("article_id = ?", articleID).Pluck("tag_id", &oldTagIDs).Error
+		if err != nil {
+			return err
+		}
+
+		// 确保新标签都存在，已存在的标签忽略
+		newTagIDs := make([]int64, 0, len(names))
+		for _, name := range names {
+			err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&Tag{Name: name, Ctime: now, Utime: now}).Error
+			if err != nil {
+				return err
+			}
+			var tag Tag
+			if err = tx.Where("name = ?", name).First(&tag).Error; err != nil {
+				return err
+			}
+			newTagIDs = append(newTagIDs, tag.ID)
+		}
+
+		added, removed := diffIDs(oldTagIDs, newTagIDs)
+		if len(removed) > 0 {
+			err = tx.Where("article_id = ? AND tag_id IN ?", articleID, removed).Delete(&ArticleTag{}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&Tag{}).Where("id IN ? AND article_cnt > 0", removed).Updates(map[string]any{
+				"article_cnt": gorm.Expr("article_cnt - 1"),
+				"utime":       now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			rows := make([]ArticleTag, 0, len(added))
+			for _, tagID := range added {
+				rows = append(rows, ArticleTag{ArticleID: articleID, TagID: tagID, Ctime: now})
+			}
+			if err = tx.Create(&rows).Error; err != nil {
+				return err
+			}
+			return tx.Model(&Tag{}).Where("id IN ?", added).Updates(map[string]any{
+				"article_cnt": gorm.Expr("article_cnt + 1"),
+				"utime":       now,
+			}).Error
+		}
+		return nil
+	})
+}
+
+// FindTrending 查找 since 之后被使用最多的标签
+func (d *GORMTagDAO) FindTrending(ctx context.Context, since int64, limit int) ([]Tag, error) {
+	var res []Tag
+	err := d.db.WithContext(ctx).Model(&Tag{}).
+		Select("tags.*").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Where("article_tags.ctime >= ?", since).
+		Group("tags.id").
+		Order("COUNT(*) DESC, tags.article_cnt DESC").
+		Limit(limit).Find(&res).Error
+	return res, err
+}
+
+// FindByPrefix 根据前缀查找标签，用于自动补全
+func (d *GORMTagDAO) FindByPrefix(ctx context.Context, prefix string, limit int) ([]Tag, error) {
+	// 转义 LIKE 中的通配符，避免用户输入的 % 和 _ 被当作通配符
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+	var res []Tag
+	err := d.db.WithContext(ctx).
+		Where("name LIKE ? AND article_cnt > 0", escaped+"%").
+		Order("article_cnt DESC").
+		Limit(limit).Find(&res).Error
+	return res, err
+}
+
+// diffIDs 计算新旧两个集合的差异，返回新增和删除的 ID
+func diffIDs(oldIDs, newIDs []int64) (added, removed []int64) {
+	oldSet := make(map[int64]struct{}, len(oldIDs))
+	for _, id := range oldIDs {
+		oldSet[id] = struct{}{}
+	}
+	newSet := make(map[int64]struct{}, len(newIDs))
+	for _, id := range newIDs {
+		newSet[id] = struct{}{}
+		if _, ok := oldSet[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	for _, id := range oldIDs {
+		if _, ok := newSet[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,133 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Upload 上传文件表，记录对象存储中每个文件的上传者和引用它的文章
+type Upload struct {
+	ID         int64  `gorm:"primaryKey,autoIncrement"`
+	UploaderID int64  `gorm:"index"`
+	ObjectKey  string `gorm:"type:varchar(255);uniqueIndex"`
+	Size       int64
+	MimeType   string `gorm:"type:varchar(128)"`
+	Width      int
+	Height     int
+	Variants   string `gorm:"type:varchar(1024)"`  // 缩略图的对象键，逗号分隔
+	ArticleID  int64  `gorm:"index:article_utime"` // 引用该文件的文章，0 表示没有被引用
+	Pending    bool   // 已签发直传地址但还没有确认上传完成
+	Ctime      int64
+	Utime      int64 `gorm:"index:article_utime"` // 和文章ID组成联合索引，方便扫描长时间没有被引用的文件
+}
+
+type UploadDAO interface {
+	// Insert 记录上传的文件
+	Insert(ctx context.Context, u Upload) (int64, error)
+	// FindByKey 根据对象键查找上传记录
+	FindByKey(ctx context.Context, key string) (Upload, error)
+	// BindArticle 将文件标记为被文章引用，文章中不再引用的文件解除引用
+	BindArticle(ctx context.Context, articleID int64, keys []string) error
+	// FindUnreferenced 查找 before 之前就没有被引用的文件
+	FindUnreferenced(ctx context.Context, before int64, limit int) ([]Upload, error)
+	// Complete 确认直传的文件上传完成，更新文件信息；文件不是待确认状态时返回 ErrNotFound
+	Complete(ctx context.Context, u Upload) error
+	// Touch 更新文件的更新时间，推迟下一次检查
+	Touch(ctx context.Context, id int64) error
+	// Delete 删除上传记录
+	Delete(ctx context.Context, id int64) error
+}
+
+type GORMUploadDAO struct {
+	db *gorm.DB
+}
+
+func NewUploadDAO(db *gorm.DB) UploadDAO {
+	return &GORMUploadDAO{
+		db: db,
+	}
+}
+
+// Insert 记录上传的文件
+func (d *GORMUploadDAO) Insert(ctx context.Context, u Upload) (int64, error) {
+	now := time.Now().UnixMilli()
+	u.Ctime = now
+	u.Utime = now
+	err := d.db.WithContext(ctx).Create(&u).Error
+	return u.ID, err
+}
+
+// FindByKey 根据对象键查找上传记录
+func (d *GORMUploadDAO) FindByKey(ctx context.Context, key string) (Upload, error) {
+	var u Upload
+	err := d.db.WithContext(ctx).Where("object_key = ?", key).First(&u).Error
+	return u, err
+}
+
+// BindArticle 将 keys 对应的文件标记为被文章引用，之前被该文章引用但不在 keys 中的文件解除引用
+func (d *GORMUploadDAO) BindArticle(ctx context.Context, articleID int64, keys []string) error {
+	now := time.Now().UnixMilli()
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		unbind := tx.Model(&Upload{}).Where("article_id = ?", articleID)
+		if len(keys) > 0 {
+			unbind = unbind.Where("object_key NOT IN ?", keys)
+		}
+		err := unbind.Updates(map[string]any{
+			"article_id": 0,
+			"utime":      now,
+		}).Error
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+		return tx.Model(&Upload{}).Where("object_key IN ?", keys).
+			Updates(map[string]any{
+				"article_id": articleID,
+				"utime":      now,
+			}).Error
+	})
+}
+
+// FindUnreferenced 查找 before 之前就没有被引用的文件
+func (d *GORMUploadDAO) FindUnreferenced(ctx context.Context, before int64, limit int) ([]Upload, error) {
+	var result []Upload
+	err := d.db.WithContext(ctx).
+		Where("article_id = ? AND utime < ?", 0, before).
+		Order("utime ASC").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// Complete 确认直传的文件上传完成，只更新待确认的记录，避免重复确认
+func (d *GORMUploadDAO) Complete(ctx context.Context, u Upload) error {
+	res := d.db.WithContext(ctx).Model(&Upload{}).
+		Where("id = ? AND pending = ?", u.ID, true).
+		Updates(map[string]any{
+			"object_key": u.ObjectKey,
+			"size":       u.Size,
+			"mime_type":  u.MimeType,
+			"width":      u.Width,
+			"height":     u.Height,
+			"variants":   u.Variants,
+			"pending":    false,
+			"utime":      time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Touch 更新文件的更新时间
+func (d *GORMUploadDAO) Touch(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Model(&Upload{}).Where("id = ?", id).
+		Update("utime", time.Now().UnixMilli()).Error
+}
+
+// Delete 删除上传记录
+func (d *GORMUploadDAO) Delete(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Where("id = ?", id).Delete(&Upload{}).Error
+}
@@ -14,7 +14,7 @@ import (
 type User struct {
 	ID       int64          `gorm:"primaryKey,autoIncrement"` // 主键
 	Email    sql.NullString `gorm:"type:varchar(100);unique"` // 邮箱，唯一索引
-	Username string         `gorm:"type:varchar(100)"`        // 用户名
+	Username string         `gorm:"type:varchar(100);index"`  // 用户名，@ 提及时按用户名查找
 	Password string         `gorm:"type:varchar(100)"`
 	Ctime    int64          `gorm:"autoCreateTime"`
 	Utime    int64          `gorm:"autoUpdateTime"`
@@ -32,6 +32,7 @@ type UserDaoInterface interface {
 	Insert(ctx context.Context, user *User) error
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	UpdateBalance(ctx context.Context, userID int64, amount int64) error
+	FindByUsernames(ctx context.Context, names []string) ([]User, error)
 }
 
 type UserDAO struct {
@@ -113,3 +114,10 @@ func (ud *UserDAO) UpdateBalance(ctx context.Context, userID int64, amount int64
 		Update("balance", gorm.Expr("balance + ?", amount)).
 		Update("utime", time.Now().UnixMilli()).Error
 }
+
+// FindByUsernames 根据用户名批量查找用户，用户名不唯一，同名的用户都会返回
+func (ud *UserDAO) FindByUsernames(ctx context.Context, names []string) ([]User, error) {
+	var users []User
+	err := ud.db.WithContext(ctx).Where("username IN ?", names).Find(&users).Error
+	return users, err
+}
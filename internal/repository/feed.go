@@ -30,6 +30,8 @@ type FeedRepository interface {
 	GetFeedEventsSince(ctx context.Context, since time.Time, offset, limit int) ([]domain.FeedEvent, error)
 	// GetFeedEventByID 根据 ID 获取 Feed 事件
 	GetFeedEventByID(ctx context.Context, id int64) (domain.FeedEvent, error)
+	// RemoveArticleItems 从用户的收件箱和发件箱中移除与文章相关的 Feed 项
+	RemoveArticleItems(ctx context.Context, userID, articleID int64) error
 }
 
 type CachedFeedRepository struct {
@@ -84,6 +86,11 @@ func (r *CachedFeedRepository) AddFeedEvent(ctx context.Context, event domain.Fe
 	return id, nil
 }
 
+// RemoveArticleItems 从用户的收件箱和发件箱中移除与文章相关的 Feed 项
+func (r *CachedFeedRepository) RemoveArticleItems(ctx context.Context, userID, articleID int64) error {
+	return r.cache.RemoveArticleItems(ctx, userID, articleID)
+}
+
 // AddToOutbox 将用户产生的事件添加到其发件箱 (用于拉模型)
 func (r *CachedFeedRepository) AddToOutbox(ctx context.Context, userID int64, item domain.UserFeedItem) error {
 	return r.cache.AddToOutbox(ctx, userID, item)
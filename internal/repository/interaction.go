@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,17 +11,30 @@ import (
 	"github.com/Fairy-nn/inspora/internal/repository/dao"
 )
 
+// ErrCollectedInOtherCollection 已经收藏在其他收藏夹中
+var ErrCollectedInOtherCollection = dao.ErrCollectedInOtherCollection
+
 type InteractionRepositoryInterface interface {
 	IncrViewCount(ctx context.Context, biz string, bizId int64) error
-	IncrLikeCount(ctx context.Context, biz string, bizId, uid int64) error
-	DecrLikeCount(ctx context.Context, biz string, bizId, uid int64) error
-	AddCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) error
+	// IncrLikeCount 记录点赞并实时更新缓存中的点赞量，返回点赞状态是否变化
+	// 数据库中的点赞量由 BatchIncrCounts 批量更新，缓存中的计数才是实时的
+	IncrLikeCount(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+	DecrLikeCount(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+	// AddCollectionItem 记录收藏并实时更新缓存中的收藏量，返回是否新增了收藏，
+	// 已经收藏在其他收藏夹时返回 ErrCollectedInOtherCollection
+	AddCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) (bool, error)
 	Get(ctx context.Context, biz string, bizId int64) (domain.Interaction, error)
 	Liked(ctx context.Context, biz string, bizId, uid int64) (bool, error)
 	Collected(ctx context.Context, biz string, bizId, uid int64) (bool, error)
-	RemoveCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) error
-	BatchIncrViewCount(ctx context.Context, biz []string, bizIds []int64) error
+	// RemoveCollectionItem 取消收藏，一篇文章只会收藏在一个收藏夹中，不需要指定收藏夹
+	RemoveCollectionItem(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+	// BatchIncrCounts 把聚合后的点赞量和收藏量增量批量写入数据库
+	BatchIncrCounts(ctx context.Context, deltas []domain.InteractionDelta) error
+	// BatchIncrViewCount 批量增加浏览量，visitors 是每次浏览的访客标识，用于统计独立浏览量
+	BatchIncrViewCount(ctx context.Context, biz []string, bizIds []int64, visitors []string) error
 	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interaction, error)
+	LikedIds(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]bool, error)
+	DeleteByBiz(ctx context.Context, biz string, bizId int64) error
 }
 
 type InteractionRepository struct {
@@ -48,45 +62,98 @@ func (i *InteractionRepository) IncrViewCount(ctx context.Context, biz string, b
 }
 
 // IncrLikeCount 增加点赞量
-func (i *InteractionRepository) IncrLikeCount(ctx context.Context, biz string, bizId, uid int64) error {
-	// 先增加数据库中的点赞量
-	err := i.dao.InsertLikeInfo(ctx, biz, bizId, uid)
-	if err != nil {
-		return err
+func (i *InteractionRepository) IncrLikeCount(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
+	// 先记录数据库中的点赞信息
+	changed, err := i.dao.InsertLikeInfo(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		return changed, err
 	}
 
 	// 然后增加缓存中的点赞量
-	return i.cache.IncrLikeCntIfPresent(ctx, biz, bizId)
+	i.updateCache(ctx, biz, bizId, i.cache.IncrLikeCnt, 1)
+	return true, nil
 }
 
 // DecrLikeCount 减少点赞量
-func (i *InteractionRepository) DecrLikeCount(ctx context.Context, biz string, bizId, uid int64) error {
+func (i *InteractionRepository) DecrLikeCount(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
 	// 先删除数据库中的点赞信息
-	err := i.dao.DeleteLikeInfo(ctx, biz, bizId, uid)
-	if err != nil {
-		return err
+	changed, err := i.dao.DeleteLikeInfo(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		return changed, err
 	}
 
 	// 然后减少缓存中的点赞量
-	return i.cache.DecrLikeCntIfPresent(ctx, biz, bizId)
+	i.updateCache(ctx, biz, bizId, i.cache.IncrLikeCnt, -1)
+	return true, nil
 }
 
 // AddCollectionItem 添加收藏项
-func (i *InteractionRepository) AddCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) error {
+func (i *InteractionRepository) AddCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) (bool, error) {
 	// 先插入数据库中的收藏信息
-	err := i.dao.InsertCollectionBiz(ctx, dao.UserCollectionBiz{
+	changed, err := i.dao.InsertCollectionBiz(ctx, dao.UserCollectionBiz{
 		Biz:          biz,
 		BizID:        bizId,
 		CollectionID: cid,
 		Uid:          uid,
 		Utime:        time.Now().UnixMilli(),
 	})
+	if err != nil || !changed {
+		return changed, err
+	}
+
+	// 然后增加缓存中的收藏量
+	i.updateCache(ctx, biz, bizId, i.cache.IncrCollectCnt, 1)
+	return true, nil
+}
+
+// updateCache 更新缓存中的计数，缓存中没有计数时用数据库中的计数初始化
+// 数据库中的计数还不包含批量写入之前的增量，增量同时记录在缓存中，初始化时会加上，计数不会回退
+func (i *InteractionRepository) updateCache(ctx context.Context, biz string, bizId int64,
+	fn func(ctx context.Context, biz string, bizId int64, delta int64) (bool, error), delta int64) {
+	present, err := fn(ctx, biz, bizId, delta)
 	if err != nil {
+		fmt.Println("更新交互计数缓存失败:", err)
+		return
+	}
+	if present {
+		return
+	}
+	if err := i.seedCache(ctx, biz, bizId); err != nil {
+		fmt.Println("初始化交互计数缓存失败:", err)
+	}
+}
+
+// seedCache 用数据库中的计数初始化缓存，还没有交互记录时从 0 开始
+func (i *InteractionRepository) seedCache(ctx context.Context, biz string, bizId int64) error {
+	interEntity, err := i.dao.Get(ctx, biz, bizId)
+	if err != nil && !errors.Is(err, dao.ErrNotFound) {
 		return err
 	}
+	return i.cache.Seed(ctx, biz, bizId, i.toDomain(interEntity))
+}
 
-	// 然后增加缓存中的收藏量
-	return i.cache.IncrCollectCntIfPresent(ctx, biz, bizId)
+// BatchIncrCounts 批量写入点赞量和收藏量的增量，缓存中的计数已经实时更新过，
+// 写入之后从缓存记录的未写入增量中扣除
+func (i *InteractionRepository) BatchIncrCounts(ctx context.Context, deltas []domain.InteractionDelta) error {
+	entities := make([]dao.InteractionDao, 0, len(deltas))
+	for _, d := range deltas {
+		if d.LikeCnt == 0 && d.CollectCnt == 0 {
+			continue
+		}
+		entities = append(entities, dao.InteractionDao{
+			Biz:          d.Biz,
+			BizID:        d.BizID,
+			LikeCount:    d.LikeCnt,
+			CollectCount: d.CollectCnt,
+		})
+	}
+	if err := i.dao.BatchIncrCounts(ctx, entities); err != nil {
+		return err
+	}
+	if err := i.cache.SettlePending(ctx, deltas); err != nil {
+		fmt.Println("扣除未写入的交互计数增量失败:", err)
+	}
+	return nil
 }
 
 // Get 获取交互信息
@@ -105,13 +172,14 @@ func (i *InteractionRepository) Get(ctx context.Context, biz string, bizId int64
 
 	interaction = i.toDomain(interEntity)
 
-	// 将获取到的交互信息存入缓存中
-	go func() {
-		err := i.cache.Set(ctx, biz, bizId, interaction)
-		if err != nil {
-			fmt.Println("缓存交互信息失败:", err)
-		}
-	}()
+	// 用数据库中的计数初始化缓存，初始化时加上还没有写入数据库的增量，再从缓存中读出实时的计数
+	if err := i.cache.Seed(ctx, biz, bizId, interaction); err != nil {
+		fmt.Println("缓存交互信息失败:", err)
+		return interaction, nil
+	}
+	if cached, err := i.cache.Get(ctx, biz, bizId); err == nil {
+		return cached, nil
+	}
 	return interaction, nil
 }
 
@@ -142,35 +210,44 @@ func (i *InteractionRepository) Collected(ctx context.Context, biz string, bizId
 }
 
 // RemoveCollectionItem 删除收藏项
-func (i *InteractionRepository) RemoveCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) error {
+func (i *InteractionRepository) RemoveCollectionItem(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
 	// 先删除数据库中的收藏信息
-	err := i.dao.DeleteCollectionInfo(ctx, biz, bizId, uid)
-	if err != nil {
-		return err
+	changed, err := i.dao.DeleteCollectionInfo(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		return changed, err
 	}
 
 	// 然后减少缓存中的收藏量
-	return i.cache.DecrCollectCntIfPresent(ctx, biz, bizId)
+	i.updateCache(ctx, biz, bizId, i.cache.IncrCollectCnt, -1)
+	return true, nil
 }
 
 // toDomain 将数据库实体转换为领域模型
 func (i *InteractionRepository) toDomain(interEntity dao.InteractionDao) domain.Interaction {
 	return domain.Interaction{
-		ViewCnt:    interEntity.ViewCount,
-		LikeCnt:    interEntity.LikeCount,
-		CollectCnt: interEntity.CollectCount,
+		ViewCnt:       interEntity.ViewCount,
+		UniqueViewCnt: interEntity.UniqueViewCount,
+		LikeCnt:       interEntity.LikeCount,
+		CollectCnt:    interEntity.CollectCount,
 	}
 }
 
 // BatchIncrViewCount 批量增加浏览量
-func (i *InteractionRepository) BatchIncrViewCount(ctx context.Context, biz []string, bizIds []int64) error {
-	// 先批量增加数据库中的浏览量
-	err := i.dao.BatchIncrReadCnt(ctx, biz, bizIds)
+func (i *InteractionRepository) BatchIncrViewCount(ctx context.Context, biz []string, bizIds []int64, visitors []string) error {
+	// 先判断哪些浏览来自去重窗口内的新访客
+	unique, err := i.cache.AddViewers(ctx, biz, bizIds, visitors)
+	if err != nil {
+		// 去重失败时只增加总浏览量，宁可少算独立浏览量也不重复计算
+		fmt.Println("浏览去重失败:", err)
+		unique = make([]bool, len(biz))
+	}
+	// 然后批量增加数据库中的浏览量
+	err = i.dao.BatchIncrReadCnt(ctx, biz, bizIds, unique)
 	if err != nil {
 		return err
 	}
-	// 然后批量增加缓存中的浏览量
-	return i.cache.BatchIncrViewCntIfPresent(ctx, biz, bizIds)
+	// 最后批量增加缓存中的浏览量
+	return i.cache.BatchIncrViewCntIfPresent(ctx, biz, bizIds, unique)
 }
 
 func (i *InteractionRepository) GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interaction, error) {
@@ -186,3 +263,24 @@ func (i *InteractionRepository) GetByIds(ctx context.Context, biz string, ids []
 
 	return res, nil
 }
+
+// LikedIds 批量判断用户是否点赞
+func (i *InteractionRepository) LikedIds(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]bool, error) {
+	liked, err := i.dao.GetLikedBizIds(ctx, biz, ids, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]bool, len(liked))
+	for _, id := range liked {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// DeleteByBiz 删除业务对象的交互数据，先删数据库再删缓存
+func (i *InteractionRepository) DeleteByBiz(ctx context.Context, biz string, bizId int64) error {
+	if err := i.dao.DeleteByBiz(ctx, biz, bizId); err != nil {
+		return err
+	}
+	return i.cache.Del(ctx, biz, bizId)
+}
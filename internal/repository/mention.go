@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+type MentionRepository interface {
+	// Replace 用 mentions 替换内容原有的提及，返回新增的提及
+	Replace(ctx context.Context, biz string, bizID, mentionerID int64, mentions []domain.Mention) ([]domain.Mention, error)
+	// FindByBiz 批量获取内容的提及，按内容ID分组
+	FindByBiz(ctx context.Context, biz string, bizIDs []int64) (map[int64][]domain.Mention, error)
+	// DeleteByBiz 删除内容的所有提及
+	DeleteByBiz(ctx context.Context, biz string, bizIDs []int64) error
+}
+
+type mentionRepository struct {
+	dao dao.MentionDAO
+}
+
+func NewMentionRepository(dao dao.MentionDAO) MentionRepository {
+	return &mentionRepository{
+		dao: dao,
+	}
+}
+
+func (r *mentionRepository) Replace(ctx context.Context, biz string, bizID, mentionerID int64, mentions []domain.Mention) ([]domain.Mention, error) {
+	entities := make([]dao.Mention, 0, len(mentions))
+	for _, m := range mentions {
+		entities = append(entities, dao.Mention{
+			UserID:      m.UserID,
+			Username:    m.Username,
+			MentionerID: mentionerID,
+		})
+	}
+	added, err := r.dao.Replace(ctx, biz, bizID, entities)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Mention, 0, len(added))
+	for _, m := range added {
+		res = append(res, r.toDomain(m))
+	}
+	return res, nil
+}
+
+func (r *mentionRepository) FindByBiz(ctx context.Context, biz string, bizIDs []int64) (map[int64][]domain.Mention, error) {
+	res := make(map[int64][]domain.Mention)
+	if len(bizIDs) == 0 {
+		return res, nil
+	}
+	mentions, err := r.dao.FindByBiz(ctx, biz, bizIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mentions {
+		res[m.BizID] = append(res[m.BizID], r.toDomain(m))
+	}
+	return res, nil
+}
+
+func (r *mentionRepository) DeleteByBiz(ctx context.Context, biz string, bizIDs []int64) error {
+	if len(bizIDs) == 0 {
+		return nil
+	}
+	return r.dao.DeleteByBiz(ctx, biz, bizIDs)
+}
+
+func (r *mentionRepository) toDomain(m dao.Mention) domain.Mention {
+	return domain.Mention{
+		UserID:   m.UserID,
+		Username: m.Username,
+	}
+}
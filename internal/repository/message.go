@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+type MessageRepository interface {
+	// Send 保存私信，返回带有ID和发送时间的私信
+	Send(ctx context.Context, msg domain.Message) (domain.Message, error)
+	// History 获取两个用户之间 ID 小于 beforeID 的私信，最新的在前
+	History(ctx context.Context, uid, peerID, beforeID int64, limit int) ([]domain.Message, error)
+	// Conversations 按最近私信时间倒序获取用户的会话，不包含对方的用户名
+	Conversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error)
+	// MarkRead 清空会话的未读数
+	MarkRead(ctx context.Context, uid, peerID int64) error
+	// TotalUnread 获取用户所有会话的未读数
+	TotalUnread(ctx context.Context, uid int64) (int64, error)
+	// Policy 获取用户的私信设置，没有设置时返回默认设置
+	Policy(ctx context.Context, uid int64) (domain.DMPolicy, error)
+	// SetPolicy 保存用户的私信设置
+	SetPolicy(ctx context.Context, uid int64, policy domain.DMPolicy) error
+}
+
+type messageRepository struct {
+	dao dao.MessageDAO
+}
+
+func NewMessageRepository(dao dao.MessageDAO) MessageRepository {
+	return &messageRepository{
+		dao: dao,
+	}
+}
+
+func (r *messageRepository) Send(ctx context.Context, msg domain.Message) (domain.Message, error) {
+	msg.Ctime = time.Now().UnixMilli()
+	id, err := r.dao.Insert(ctx, dao.DirectMessage{
+		SenderID:   msg.SenderID,
+		ReceiverID: msg.ReceiverID,
+		Content:    msg.Content,
+		Ctime:      msg.Ctime,
+	})
+	if err != nil {
+		return domain.Message{}, err
+	}
+	msg.ID = id
+	return msg, nil
+}
+
+func (r *messageRepository) History(ctx context.Context, uid, peerID, beforeID int64, limit int) ([]domain.Message, error) {
+	msgs, err := r.dao.History(ctx, dao.ConvKey(uid, peerID), beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Message, 0, len(msgs))
+	for _, m := range msgs {
+		res = append(res, r.toDomain(m))
+	}
+	return res, nil
+}
+
+func (r *messageRepository) Conversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error) {
+	convs, err := r.dao.ListConversations(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(convs))
+	for _, c := range convs {
+		ids = append(ids, c.LastMsgID)
+	}
+	msgs, err := r.dao.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[int64]domain.Message, len(msgs))
+	for _, m := range msgs {
+		last[m.ID] = r.toDomain(m)
+	}
+
+	res := make([]domain.Conversation, 0, len(convs))
+	for _, c := range convs {
+		res = append(res, domain.Conversation{
+			Peer:        domain.Author{ID: c.PeerID},
+			LastMessage: last[c.LastMsgID],
+			Unread:      c.Unread,
+			Utime:       c.Utime,
+		})
+	}
+	return res, nil
+}
+
+func (r *messageRepository) MarkRead(ctx context.Context, uid, peerID int64) error {
+	return r.dao.MarkRead(ctx, uid, peerID)
+}
+
+func (r *messageRepository) TotalUnread(ctx context.Context, uid int64) (int64, error) {
+	return r.dao.TotalUnread(ctx, uid)
+}
+
+func (r *messageRepository) Policy(ctx context.Context, uid int64) (domain.DMPolicy, error) {
+	s, err := r.dao.GetSetting(ctx, uid)
+	if errors.Is(err, dao.ErrNotFound) {
+		return domain.DMPolicyEveryone, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return domain.DMPolicy(s.Policy), nil
+}
+
+func (r *messageRepository) SetPolicy(ctx context.Context, uid int64, policy domain.DMPolicy) error {
+	return r.dao.UpsertSetting(ctx, dao.MessageSetting{
+		UserID: uid,
+		Policy: uint8(policy),
+		Utime:  time.Now().UnixMilli(),
+	})
+}
+
+func (r *messageRepository) toDomain(m dao.DirectMessage) domain.Message {
+	return domain.Message{
+		ID:         m.ID,
+		SenderID:   m.SenderID,
+		ReceiverID: m.ReceiverID,
+		Content:    m.Content,
+		Ctime:      m.Ctime,
+	}
+}
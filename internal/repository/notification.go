@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/cache"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+type NotificationRepository interface {
+	// Add 保存通知，可聚合的通知合并到同组未读的通知中
+	Add(ctx context.Context, n domain.Notification) error
+	// List 按最近触发时间倒序获取用户的通知
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error)
+	// UnreadCount 获取用户的未读通知数
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+	// MarkRead 标记通知为已读
+	MarkRead(ctx context.Context, uid, id int64) error
+	// MarkAllRead 标记用户的所有通知为已读
+	MarkAllRead(ctx context.Context, uid int64) error
+	// MutedTypes 获取用户关闭的通知类型
+	MutedTypes(ctx context.Context, uid int64) ([]string, error)
+	// SetMuted 关闭或打开某类通知
+	SetMuted(ctx context.Context, uid int64, typ string, muted bool) error
+}
+
+type notificationRepository struct {
+	dao   dao.NotificationDAO
+	cache cache.NotificationCache
+}
+
+func NewNotificationRepository(dao dao.NotificationDAO, cache cache.NotificationCache) NotificationRepository {
+	return &notificationRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+func (r *notificationRepository) Add(ctx context.Context, n domain.Notification) error {
+	inserted, err := r.dao.Insert(ctx, r.toEntity(n))
+	if err != nil {
+		return err
+	}
+	// 聚合到已有的未读通知时未读数不变
+	if inserted {
+		r.delUnread(ctx, n.UserID)
+	}
+	return nil
+}
+
+func (r *notificationRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error) {
+	entities, err := r.dao.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Notification, 0, len(entities))
+	for _, n := range entities {
+		res = append(res, r.toDomain(n))
+	}
+	return res, nil
+}
+
+func (r *notificationRepository) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	cnt, err := r.cache.GetUnread(ctx, uid)
+	if err == nil {
+		return cnt, nil
+	}
+	cnt, err = r.dao.CountUnread(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.cache.SetUnread(ctx, uid, cnt); err != nil {
+		log.Printf("缓存未读通知数失败, 用户ID: %d, %v", uid, err)
+	}
+	return cnt, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, uid, id int64) error {
+	changed, err := r.dao.MarkRead(ctx, uid, id, time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+	if changed {
+		r.delUnread(ctx, uid)
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, uid int64) error {
+	if err := r.dao.MarkAllRead(ctx, uid, time.Now().UnixMilli()); err != nil {
+		return err
+	}
+	r.delUnread(ctx, uid)
+	return nil
+}
+
+func (r *notificationRepository) MutedTypes(ctx context.Context, uid int64) ([]string, error) {
+	return r.dao.GetMutedTypes(ctx, uid)
+}
+
+func (r *notificationRepository) SetMuted(ctx context.Context, uid int64, typ string, muted bool) error {
+	return r.dao.SetMuted(ctx, uid, typ, muted, time.Now().UnixMilli())
+}
+
+// delUnread 删除未读数缓存，失败时缓存最多在过期前不准确
+func (r *notificationRepository) delUnread(ctx context.Context, uid int64) {
+	if err := r.cache.DelUnread(ctx, uid); err != nil {
+		log.Printf("删除未读通知数缓存失败, 用户ID: %d, %v", uid, err)
+	}
+}
+
+func (r *notificationRepository) toEntity(n domain.Notification) dao.Notification {
+	entity := dao.Notification{
+		UserID:    n.UserID,
+		Type:      n.Type,
+		Biz:       n.Biz,
+		BizID:     n.BizID,
+		ArticleID: n.ArticleID,
+		ActorID:   n.Actor.ID,
+		ActorCnt:  1,
+		Content:   n.Content,
+		Amount:    n.Amount,
+		Ctime:     n.Ctime,
+		Utime:     n.Ctime,
+	}
+	if n.Aggregatable() {
+		key := n.GroupKey()
+		entity.GroupKey = &key
+	}
+	return entity
+}
+
+func (r *notificationRepository) toDomain(n dao.Notification) domain.Notification {
+	return domain.Notification{
+		ID:        n.ID,
+		UserID:    n.UserID,
+		Type:      n.Type,
+		Biz:       n.Biz,
+		BizID:     n.BizID,
+		ArticleID: n.ArticleID,
+		Actor:     domain.Author{ID: n.ActorID},
+		ActorCnt:  n.ActorCnt,
+		Content:   n.Content,
+		Amount:    n.Amount,
+		Read:      n.ReadTime > 0,
+		Ctime:     n.Ctime,
+		Utime:     n.Utime,
+	}
+}
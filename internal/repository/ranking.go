@@ -6,11 +6,13 @@ import (
 
 	"github.com/Fairy-nn/inspora/internal/domain"
 	"github.com/Fairy-nn/inspora/internal/repository/cache"
+	"github.com/redis/go-redis/v9"
 )
 
 type RankingRepositoryInterface interface {
 	ReplaceTopN(ctx context.Context, articles []domain.Article) error
 	GetTopN(ctx context.Context) ([]domain.Article, error)
+	RemoveFromTopN(ctx context.Context, articleID int64) error
 }
 
 type CachedRankingRepository struct {
@@ -57,3 +59,29 @@ func (r *CachedRankingRepository) GetTopN(ctx context.Context) ([]domain.Article
 	}
 	return data, err
 }
+
+// RemoveFromTopN 从榜单缓存中移除文章，缓存中没有榜单时不做处理
+func (r *CachedRankingRepository) RemoveFromTopN(ctx context.Context, articleID int64) error {
+	if data, err := r.local.ForceGet(ctx); err == nil {
+		_ = r.local.Set(ctx, removeArticle(data, articleID))
+	}
+	data, err := r.redis.Get(ctx)
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+		return err
+	}
+	return r.redis.Set(ctx, removeArticle(data, articleID))
+}
+
+// removeArticle 返回去掉指定文章后的新切片，不修改原切片
+func removeArticle(articles []domain.Article, articleID int64) []domain.Article {
+	res := make([]domain.Article, 0, len(articles))
+	for _, a := range articles {
+		if a.ID != articleID {
+			res = append(res, a)
+		}
+	}
+	return res
+}
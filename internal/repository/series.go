@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+var (
+	ErrSeriesNotFound       = dao.ErrNotFound
+	ErrSeriesArticleExists  = dao.ErrSeriesArticleExists
+	ErrSeriesArticleInvalid = dao.ErrSeriesArticleInvalid
+)
+
+type SeriesRepository interface {
+	// Create 创建系列
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	// FindByID 获取系列及其有序的文章ID
+	FindByID(ctx context.Context, id int64) (domain.Series, error)
+	// FindByAuthor 获取作者的所有系列
+	FindByAuthor(ctx context.Context, authorID int64) ([]domain.Series, error)
+	// FindByArticleID 获取文章所在的系列
+	FindByArticleID(ctx context.Context, articleID int64) (domain.Series, error)
+	// AddArticle 将文章追加到系列末尾
+	AddArticle(ctx context.Context, seriesID, articleID int64) error
+	// RemoveArticle 从系列中移除文章
+	RemoveArticle(ctx context.Context, seriesID, articleID int64) error
+	// Reorder 重新排列系列中的文章
+	Reorder(ctx context.Context, seriesID int64, articleIDs []int64) error
+}
+
+type SeriesRepositoryImpl struct {
+	dao dao.SeriesDAO
+}
+
+func NewSeriesRepository(dao dao.SeriesDAO) SeriesRepository {
+	return &SeriesRepositoryImpl{
+		dao: dao,
+	}
+}
+
+// Create 创建系列
+func (r *SeriesRepositoryImpl) Create(ctx context.Context, s domain.Series) (int64, error) {
+	return r.dao.Insert(ctx, dao.Series{
+		Title:       s.Title,
+		Description: s.Description,
+		AuthorID:    s.AuthorID,
+	})
+}
+
+// FindByID 获取系列及其有序的文章ID
+func (r *SeriesRepositoryImpl) FindByID(ctx context.Context, id int64) (domain.Series, error) {
+	s, err := r.dao.FindByID(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	articles, err := r.dao.FindArticles(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	res := r.toDomain(s)
+	for _, sa := range articles {
+		res.ArticleIDs = append(res.ArticleIDs, sa.ArticleID)
+	}
+	return res, nil
+}
+
+// FindByAuthor 获取作者的所有系列，不包含文章列表
+func (r *SeriesRepositoryImpl) FindByAuthor(ctx context.Context, authorID int64) ([]domain.Series, error) {
+	series, err := r.dao.FindByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Series, 0, len(series))
+	for _, s := range series {
+		res = append(res, r.toDomain(s))
+	}
+	return res, nil
+}
+
+// FindByArticleID 获取文章所在的系列
+func (r *SeriesRepositoryImpl) FindByArticleID(ctx context.Context, articleID int64) (domain.Series, error) {
+	sa, err := r.dao.FindByArticleID(ctx, articleID)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	return r.FindByID(ctx, sa.SeriesID)
+}
+
+// AddArticle 将文章追加到系列末尾
+func (r *SeriesRepositoryImpl) AddArticle(ctx context.Context, seriesID, articleID int64) error {
+	return r.dao.AddArticle(ctx, seriesID, articleID)
+}
+
+// RemoveArticle 从系列中移除文章
+func (r *SeriesRepositoryImpl) RemoveArticle(ctx context.Context, seriesID, articleID int64) error {
+	return r.dao.RemoveArticle(ctx, seriesID, articleID)
+}
+
+// Reorder 重新排列系列中的文章
+func (r *SeriesRepositoryImpl) Reorder(ctx context.Context, seriesID int64, articleIDs []int64) error {
+	return r.dao.Reorder(ctx, seriesID, articleIDs)
+}
+
+func (r *SeriesRepositoryImpl) toDomain(s dao.Series) domain.Series {
+	return domain.Series{
+		ID:          s.ID,
+		Title:       s.Title,
+		Description: s.Description,
+		AuthorID:    s.AuthorID,
+		Ctime:       time.UnixMilli(s.Ctime),
+		Utime:       time.UnixMilli(s.Utime),
+	}
+}
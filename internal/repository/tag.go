@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/cache"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+type TagRepository interface {
+	// SetArticleTags 设置已发布文章的标签
+	SetArticleTags(ctx context.Context, articleID int64, tags []string) error
+	// FindTrending 获取 since 之后的热门标签
+	FindTrending(ctx context.Context, since time.Time, limit int) ([]domain.Tag, error)
+	// FindByPrefix 根据前缀查找标签
+	FindByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Tag, error)
+}
+
+type CachedTagRepository struct {
+	dao   dao.TagDAO
+	cache cache.TagCache
+}
+
+func NewCachedTagRepository(dao dao.TagDAO, cache cache.TagCache) TagRepository {
+	return &CachedTagRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+// SetArticleTags 设置已发布文章的标签
+func (r *CachedTagRepository) SetArticleTags(ctx context.Context, articleID int64, tags []string) error {
+	return r.dao.SetArticleTags(ctx, articleID, tags)
+}
+
+// FindTrending 获取 since 之后的热门标签，结果会缓存一段时间
+func (r *CachedTagRepository) FindTrending(ctx context.Context, since time.Time, limit int) ([]domain.Tag, error) {
+	tags, err := r.cache.GetTrending(ctx, limit)
+	if err == nil {
+		return tags, nil
+	}
+
+	res, err := r.dao.FindTrending(ctx, since.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	tags = r.toDomainList(res)
+
+	if err := r.cache.SetTrending(ctx, limit, tags); err != nil {
+		fmt.Println("设置热门标签缓存失败", err)
+	}
+	return tags, nil
+}
+
+// FindByPrefix 根据前缀查找标签
+func (r *CachedTagRepository) FindByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	res, err := r.dao.FindByPrefix(ctx, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainList(res), nil
+}
+
+func (r *CachedTagRepository) toDomainList(tags []dao.Tag) []domain.Tag {
+	result := make([]domain.Tag, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, domain.Tag{
+			ID:         t.ID,
+			Name:       t.Name,
+			ArticleCnt: t.ArticleCnt,
+			Ctime:      time.UnixMilli(t.Ctime),
+			Utime:      time.UnixMilli(t.Utime),
+		})
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository/dao"
+)
+
+// ErrUploadNotFound 上传记录不存在
+var ErrUploadNotFound = dao.ErrNotFound
+
+type UploadRepository interface {
+	// Create 记录上传的文件
+	Create(ctx context.Context, u domain.Upload) (int64, error)
+	// FindByKey 根据对象键获取上传记录
+	FindByKey(ctx context.Context, key string) (domain.Upload, error)
+	// BindArticle 更新文章引用的文件，文章中不再引用的文件解除引用
+	BindArticle(ctx context.Context, articleID int64, keys []string) error
+	// FindUnreferenced 获取 before 之前就没有被引用的文件
+	FindUnreferenced(ctx context.Context, before time.Time, limit int) ([]domain.Upload, error)
+	// Complete 确认直传的文件上传完成，处理后的文件存放在新的对象键下
+	Complete(ctx context.Context, u domain.Upload) error
+	// Touch 推迟文件的下一次检查
+	Touch(ctx context.Context, id int64) error
+	// Delete 删除上传记录
+	Delete(ctx context.Context, id int64) error
+}
+
+type UploadRepositoryImpl struct {
+	dao dao.UploadDAO
+}
+
+func NewUploadRepository(dao dao.UploadDAO) UploadRepository {
+	return &UploadRepositoryImpl{
+		dao: dao,
+	}
+}
+
+// Create 记录上传的文件
+func (r *UploadRepositoryImpl) Create(ctx context.Context, u domain.Upload) (int64, error) {
+	return r.dao.Insert(ctx, dao.Upload{
+		UploaderID: u.UploaderID,
+		ObjectKey:  u.ObjectKey,
+		Size:       u.Size,
+		MimeType:   u.MimeType,
+		Width:      u.Width,
+		Height:     u.Height,
+		Variants:   strings.Join(u.Variants, ","),
+		ArticleID:  u.ArticleID,
+		Pending:    u.Pending,
+	})
+}
+
+// FindByKey 根据对象键获取上传记录
+func (r *UploadRepositoryImpl) FindByKey(ctx context.Context, key string) (domain.Upload, error) {
+	u, err := r.dao.FindByKey(ctx, key)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	return r.toDomain(u), nil
+}
+
+// BindArticle 更新文章引用的文件
+func (r *UploadRepositoryImpl) BindArticle(ctx context.Context, articleID int64, keys []string) error {
+	return r.dao.BindArticle(ctx, articleID, keys)
+}
+
+// FindUnreferenced 获取 before 之前就没有被引用的文件
+func (r *UploadRepositoryImpl) FindUnreferenced(ctx context.Context, before time.Time, limit int) ([]domain.Upload, error) {
+	uploads, err := r.dao.FindUnreferenced(ctx, before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Upload, 0, len(uploads))
+	for _, u := range uploads {
+		res = append(res, r.toDomain(u))
+	}
+	return res, nil
+}
+
+// Complete 确认直传的文件上传完成
+func (r *UploadRepositoryImpl) Complete(ctx context.Context, u domain.Upload) error {
+	return r.dao.Complete(ctx, dao.Upload{
+		ID:        u.ID,
+		ObjectKey: u.ObjectKey,
+		Size:      u.Size,
+		MimeType:  u.MimeType,
+		Width:     u.Width,
+		Height:    u.Height,
+		Variants:  strings.Join(u.Variants, ","),
+	})
+}
+
+// Touch 推迟文件的下一次检查
+func (r *UploadRepositoryImpl) Touch(ctx context.Context, id int64) error {
+	return r.dao.Touch(ctx, id)
+}
+
+// Delete 删除上传记录
+func (r *UploadRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	return r.dao.Delete(ctx, id)
+}
+
+func (r *UploadRepositoryImpl) toDomain(u dao.Upload) domain.Upload {
+	var variants []string
+	if u.Variants != "" {
+		variants = strings.Split(u.Variants, ",")
+	}
+	return domain.Upload{
+		ID:         u.ID,
+		UploaderID: u.UploaderID,
+		ObjectKey:  u.ObjectKey,
+		Size:       u.Size,
+		MimeType:   u.MimeType,
+		Width:      u.Width,
+		Height:     u.Height,
+		Variants:   variants,
+		ArticleID:  u.ArticleID,
+		Pending:    u.Pending,
+		Ctime:      time.UnixMilli(u.Ctime),
+		Utime:      time.UnixMilli(u.Utime),
+	}
+}
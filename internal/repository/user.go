@@ -19,6 +19,8 @@ type UserRepositoryInterface interface {
 	GetByID(ctx context.Context, id int64) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	UpdateBalance(ctx context.Context, userID int64, amount int64) error
+	// FindByUsernames 根据用户名批量查找用户，同名的用户都会返回
+	FindByUsernames(ctx context.Context, names []string) ([]domain.User, error)
 }
 
 type UserRepository struct {
@@ -37,6 +39,8 @@ var (
 	errUserNotFound       = errors.New("用户不存在")
 	ErrUserNotFound       = dao.ErrUserNotFound
 	ErrUserDuplicateEmail = dao.ErrUserDuplicateEmail
+	// ErrUserRecordNotFound GetByID 找不到用户时直接返回 DAO 层的记录不存在错误
+	ErrUserRecordNotFound = dao.ErrNotFound
 )
 
 // Create 创建用户
@@ -146,3 +150,19 @@ func (r *UserRepository) UpdateBalance(ctx context.Context, userID int64, amount
 
 	return nil
 }
+
+// FindByUsernames 根据用户名批量查找用户
+func (r *UserRepository) FindByUsernames(ctx context.Context, names []string) ([]domain.User, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	users, err := r.dao.FindByUsernames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.User, 0, len(users))
+	for _, u := range users {
+		res = append(res, r.enityToDomain(u))
+	}
+	return res, nil
+}
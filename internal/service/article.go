@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,36 +13,93 @@ import (
 	events "github.com/Fairy-nn/inspora/internal/events/article"
 	feedevents "github.com/Fairy-nn/inspora/internal/events/feed"
 	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/pkg/render"
+)
+
+// ErrInvalidPublishTime 定时发布时间不合法
+var ErrInvalidPublishTime = errors.New("定时发布时间必须晚于当前时间")
+
+var (
+	// ErrArticleNotShareable 只有已发布或私密的文章可以分享
+	ErrArticleNotShareable = errors.New("只有已发布或私密的文章可以分享")
+	// ErrInvalidShareExpire 分享链接的有效期不合法
+	ErrInvalidShareExpire = errors.New("分享链接的有效期必须在 1 小时到 30 天之间")
+	// ErrArticlePermissionDenied 协作者的角色没有权限执行该操作
+	ErrArticlePermissionDenied = errors.New("没有权限操作这篇文章")
+	// ErrArticleNotDeleted 只有软删除的文章可以恢复
+	ErrArticleNotDeleted = errors.New("只有已删除的文章可以恢复")
+	// ErrArticleNotPublished 只有已发布的文章可以设为私密
+	ErrArticleNotPublished = errors.New("只有已发布的文章可以设为私密")
+	// ErrArticleUnavailable 文章不存在、已下线或者是别人的私密文章，不能点赞、收藏和评论
+	ErrArticleUnavailable = errors.New("文章不存在或者不可访问")
+)
+
+const (
+	// DefaultShareExpire 分享链接默认的有效期
+	DefaultShareExpire = 7 * 24 * time.Hour
+	minShareExpire     = time.Hour
+	maxShareExpire     = 30 * 24 * time.Hour
 )
 
 // ArticleServiceInterface 文章服务接口
 type ArticleServiceInterface interface {
 	Save(ctx context.Context, article domain.Article) (int64, error)
 	Publish(ctx context.Context, article domain.Article) (int64, error)
+	Schedule(ctx context.Context, article domain.Article) (int64, error)
+	PublishScheduled(ctx context.Context, now time.Time, limit int) (int, error)
 	Withdraw(ctx context.Context, article domain.Article) error
-	List(ctx context.Context, userID int64, limit int, offset int) ([]domain.Article, error)
+	Delete(ctx context.Context, articleID, authorID int64, permanent bool) error
+	Restore(ctx context.Context, articleID, authorID int64) error
+	List(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
 	FindById(ctx context.Context, id, uid int64) (domain.Article, error)
 	FindPublicArticleById(ctx context.Context, id int64, uid int64) (domain.Article, error)
-	ListPublic(ctx context.Context, startTime time.Time, offset, limit int) ([]domain.Article, error)
+	FindSharedArticle(ctx context.Context, id int64, token string, uid int64) (domain.Article, error)
+	// FindInteractable 获取可以点赞、收藏和评论的文章，私密文章只有作者可以互动
+	FindInteractable(ctx context.Context, id int64, uid int64) (domain.Article, error)
+	// RecordView 记录一次文章阅读，爬虫等自动化程序的访问不计入浏览量
+	RecordView(ctx context.Context, id int64, viewer domain.Viewer)
+	MakePrivate(ctx context.Context, articleID, authorID int64) error
+	CreateShare(ctx context.Context, articleID, authorID int64, expire time.Duration) (domain.ArticleShare, error)
+	ListShares(ctx context.Context, articleID, authorID int64) ([]domain.ArticleShare, error)
+	RevokeShare(ctx context.Context, shareID, authorID int64) error
+	ListPublic(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+	ListPublicByAuthor(ctx context.Context, authorID int64, cursor domain.ArticleCursor, limit int) (domain.Author, []domain.Article, error)
 }
 
 // ArticleService 文章服务实现
 type ArticleService struct {
-	repo      repository.ArticleRepository
-	producer  events.Producer
-	searchSvc SearchService
-	feedProd  feedevents.Producer // Feed 事件生产者
+	repo       repository.ArticleRepository
+	producer   events.Producer
+	searchSvc  SearchService
+	feedProd   feedevents.Producer // Feed 事件生产者
+	tagRepo    repository.TagRepository
+	seriesRepo repository.SeriesRepository
+	shareRepo  repository.ArticleShareRepository
+	collabRepo repository.CollaboratorRepository
+	ossSvc     OSSServiceInterface
+	mentionSvc MentionServiceInterface
 }
 
 // NewArticleService 创建文章服务
 func NewArticleService(repo repository.ArticleRepository,
 	producer events.Producer, searchSvc SearchService,
-	feedProd feedevents.Producer) ArticleServiceInterface {
+	feedProd feedevents.Producer, tagRepo repository.TagRepository,
+	seriesRepo repository.SeriesRepository,
+	shareRepo repository.ArticleShareRepository,
+	collabRepo repository.CollaboratorRepository,
+	ossSvc OSSServiceInterface,
+	mentionSvc MentionServiceInterface) ArticleServiceInterface {
 	return &ArticleService{
-		repo:      repo,
-		producer:  producer,
-		searchSvc: searchSvc,
-		feedProd:  feedProd,
+		repo:       repo,
+		producer:   producer,
+		searchSvc:  searchSvc,
+		feedProd:   feedProd,
+		tagRepo:    tagRepo,
+		seriesRepo: seriesRepo,
+		shareRepo:  shareRepo,
+		collabRepo: collabRepo,
+		ossSvc:     ossSvc,
+		mentionSvc: mentionSvc,
 	}
 }
 
@@ -47,16 +107,26 @@ func NewArticleService(repo repository.ArticleRepository,
 func (a *ArticleService) Save(ctx context.Context, article domain.Article) (int64, error) {
 	// 设置文章状态为草稿
 	article.Status = domain.ArticleStatusDraft
+	article.Tags = domain.NormalizeTags(article.Tags)
+	article.HTML = article.RenderHTML()
 
 	// 如果文章ID大于0，则更新文章，否则创建新文章
 	if article.ID > 0 {
-		err := a.repo.Update(ctx, article)
+		// 作者和编辑者都可以修改文章
+		owner, err := a.authorize(ctx, article.ID, article.Author.ID, domain.CollaboratorRole.CanEdit)
 		if err != nil {
 			return article.ID, err
 		}
+		// 协作者修改时按所有者写入，制作库中的作者不变
+		article.Author = owner.Author
+		err = a.repo.Update(ctx, article)
+		if err != nil {
+			return article.ID, err
+		}
+		a.bindImages(ctx, article.ID, article.ImgUrls)
 
 		// 使用事务或锁确保数据一致性
-		return article.ID, a.updateArticleIndex(ctx, article.ID, article.Author.ID)
+		return article.ID, a.updateArticleIndex(ctx, article.ID)
 
 		// // 更新索引
 		// if err == nil && a.searchSvc != nil {
@@ -73,9 +143,10 @@ func (a *ArticleService) Save(ctx context.Context, article domain.Article) (int6
 	if err != nil {
 		return 0, err
 	}
+	a.bindImages(ctx, id, article.ImgUrls)
 
 	// 使用事务或锁确保数据一致性
-	return id, a.updateArticleIndex(ctx, id, article.Author.ID)
+	return id, a.updateArticleIndex(ctx, id)
 
 	// id, err := a.repo.Create(ctx, article)
 	// if err == nil && a.searchSvc != nil && id> 0 {
@@ -91,14 +162,29 @@ func (a *ArticleService) Save(ctx context.Context, article domain.Article) (int6
 func (a *ArticleService) Publish(ctx context.Context, article domain.Article) (int64, error) {
 	// 设置文章状态为已发布
 	article.Status = domain.ArticleStatusPublished
+	article.Tags = domain.NormalizeTags(article.Tags)
+	// 发布时渲染 HTML，和线上文章一起存储和缓存
+	article.HTML = article.RenderHTML()
+	if article.ID > 0 {
+		owner, err := a.authorize(ctx, article.ID, article.Author.ID, domain.CollaboratorRole.CanEdit)
+		if err != nil {
+			return article.ID, err
+		}
+		article.Author = owner.Author
+	}
+	// 提及的用户渲染为链接，和 HTML 一起存储
+	mentions := a.resolveMentions(ctx, &article)
 	// 同步到数据库
 	id, err := a.repo.Sync(ctx, article)
 	if err != nil {
 		return id, err
 	}
+	a.syncArticleTags(ctx, id, article.Tags)
+	a.bindImages(ctx, id, article.ImgUrls)
+	a.saveMentions(ctx, id, article, mentions)
 
 	// 更新搜索索引
-	err = a.updateArticleIndex(ctx, id, article.Author.ID)
+	err = a.updateArticleIndex(ctx, id)
 	if err != nil {
 		log.Println("Failed to update article index:", err)
 	}
@@ -115,68 +201,457 @@ func (a *ArticleService) Publish(ctx context.Context, article domain.Article) (i
 	return id, nil
 }
 
+// Schedule 定时发布文章，文章先保存在制作库，到达发布时间后由定时任务发布
+func (a *ArticleService) Schedule(ctx context.Context, article domain.Article) (int64, error) {
+	if !article.PublishAt.After(time.Now()) {
+		return 0, ErrInvalidPublishTime
+	}
+	article.Status = domain.ArticleStatusScheduled
+	article.Tags = domain.NormalizeTags(article.Tags)
+	article.HTML = article.RenderHTML()
+
+	if article.ID > 0 {
+		owner, err := a.authorize(ctx, article.ID, article.Author.ID, domain.CollaboratorRole.CanEdit)
+		if err != nil {
+			return article.ID, err
+		}
+		article.Author = owner.Author
+		if err := a.repo.Update(ctx, article); err != nil {
+			return article.ID, err
+		}
+		a.bindImages(ctx, article.ID, article.ImgUrls)
+		return article.ID, nil
+	}
+	id, err := a.repo.Create(ctx, article)
+	if err != nil {
+		return 0, err
+	}
+	a.bindImages(ctx, id, article.ImgUrls)
+	return id, nil
+}
+
+// PublishScheduled 发布已到发布时间的定时文章，返回当前实例实际发布的文章数量
+func (a *ArticleService) PublishScheduled(ctx context.Context, now time.Time, limit int) (int, error) {
+	articles, err := a.repo.FindDueScheduled(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, article := range articles {
+		ok, err := a.publishScheduled(ctx, article)
+		if err != nil {
+			// 事务已回滚，文章仍是定时发布状态，等待下一次任务重试
+			log.Println("Failed to publish scheduled article:", article.ID, err)
+			continue
+		}
+		if ok {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// publishScheduled 走和 Publish 相同的同步流程发布一篇定时文章
+// 多个实例同时扫描到同一篇文章时只有一个实例能抢占成功，保证发布事件和索引更新只执行一次
+func (a *ArticleService) publishScheduled(ctx context.Context, article domain.Article) (bool, error) {
+	article.Status = domain.ArticleStatusPublished
+	// 发布后清空定时发布时间
+	article.PublishAt = time.Time{}
+	article.HTML = article.RenderHTML()
+	mentions := a.resolveMentions(ctx, &article)
+	ok, err := a.repo.SyncScheduled(ctx, article)
+	if err != nil || !ok {
+		// 已被其他实例发布，或者作者已经撤回/修改了文章
+		return false, err
+	}
+	id := article.ID
+	a.syncArticleTags(ctx, id, article.Tags)
+	a.saveMentions(ctx, id, article, mentions)
+
+	// 更新搜索索引
+	if err := a.updateArticleIndex(ctx, id); err != nil {
+		log.Println("Failed to update article index:", err)
+	}
+	// 发送文章发布feed事件，定时任务的上下文结束后会被取消，这里同步发送
+	if a.feedProd != nil {
+		if feedErr := a.feedProd.ProduceArticlePublishedEvent(ctx, article.Author.ID, id, article.Title); feedErr != nil {
+			fmt.Println("Failed to send article published feed event:", feedErr)
+		}
+	}
+	return true, nil
+}
+
 // Withdraw 撤回文章
 func (a *ArticleService) Withdraw(ctx context.Context, article domain.Article) error {
 	// 把文章撤回了，这里设置成草稿状态
 	// return a.repo.SyncStatus(ctx, article.ID, article.Author.ID, domain.ArticleStatusDraft)
+	owner, err := a.authorize(ctx, article.ID, article.Author.ID, domain.CollaboratorRole.CanEdit)
+	if err != nil {
+		return err
+	}
+	article.Author = owner.Author
 	if err := a.repo.SyncStatus(ctx, article.ID, article.Author.ID, domain.ArticleStatusDraft); err != nil {
 		return err
 	}
+	// 撤回后文章不再出现在标签列表中
+	a.syncArticleTags(ctx, article.ID, nil)
 
 	// 更新搜索索引
-	return a.updateArticleIndex(ctx, article.ID, article.Author.ID)
+	return a.updateArticleIndex(ctx, article.ID)
+}
+
+// Delete 作者删除文章，permanent 为 false 时软删除，文章标记为已删除状态并下线，
+// 为 true 时彻底删除。线上库、搜索索引、缓存、Feed 等关联数据由删除事件的消费者清理
+func (a *ArticleService) Delete(ctx context.Context, articleID, authorID int64, permanent bool) error {
+	// 同时校验文章是否属于当前作者
+	article, err := a.repo.FindById(ctx, articleID, authorID)
+	if err != nil {
+		return err
+	}
+
+	if permanent {
+		err = a.repo.Delete(ctx, articleID, authorID)
+	} else {
+		err = a.repo.SyncStatus(ctx, articleID, authorID, domain.ArticleStatusDeleted)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = a.producer.ProduceDeletedEvent(ctx, events.DeletedEvent{
+		Aid:       articleID,
+		Uid:       authorID,
+		ImgUrls:   article.ImgUrls,
+		Permanent: permanent,
+	})
+	if err != nil {
+		// 文章已经删除，这里只记录错误
+		log.Println("Failed to produce article deleted event:", articleID, err)
+	}
+	return nil
+}
+
+// Restore 恢复软删除的文章，恢复后文章回到草稿状态，作者重新发布后才会再次上线
+func (a *ArticleService) Restore(ctx context.Context, articleID, authorID int64) error {
+	// 同时校验文章是否属于当前作者
+	article, err := a.repo.FindById(ctx, articleID, authorID)
+	if err != nil {
+		return err
+	}
+	if article.Status != domain.ArticleStatusDeleted {
+		return ErrArticleNotDeleted
+	}
+	return a.repo.SyncStatus(ctx, articleID, authorID, domain.ArticleStatusDraft)
 }
 
 // List 获取文章列表
-func (a *ArticleService) List(ctx context.Context, userID int64, limit int, offset int) ([]domain.Article, error) {
-	return a.repo.List(ctx, userID, limit, offset)
+func (a *ArticleService) List(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return a.repo.List(ctx, userID, cursor, limit)
 }
 
-// FindById 根据ID获取文章
+// FindById 根据ID获取文章，作者和所有协作者都可以查看
 func (a *ArticleService) FindById(ctx context.Context, id, uid int64) (domain.Article, error) {
-	return a.repo.FindById(ctx, id, uid)
+	return a.authorize(ctx, id, uid, domain.CollaboratorRole.CanView)
+}
+
+// authorize 校验用户对制作库中文章的权限，返回的文章的作者始终是所有者
+// 用户既不是作者也不是协作者时，对外表现为文章不存在
+func (a *ArticleService) authorize(ctx context.Context, articleID, uid int64,
+	allowed func(domain.CollaboratorRole) bool) (domain.Article, error) {
+	article, err := a.repo.GetByID(ctx, articleID)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	role, err := collaboratorRole(ctx, a.collabRepo, article, uid)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if role == domain.CollaboratorRoleUnknown {
+		return domain.Article{}, repository.ErrArticleNotFound
+	}
+	if !allowed(role) {
+		return domain.Article{}, ErrArticlePermissionDenied
+	}
+	return article, nil
 }
 
 // FindPublicArticleById 根据ID获取公开文章
 func (a *ArticleService) FindPublicArticleById(ctx context.Context, id int64, uid int64) (domain.Article, error) {
+	return a.findPublic(ctx, id, uid, false)
+}
+
+// FindSharedArticle 通过分享链接获取文章，令牌有效时私密文章也可以阅读
+// 令牌不存在、已过期或者不属于这篇文章时，对外表现为文章不存在
+func (a *ArticleService) FindSharedArticle(ctx context.Context, id int64, token string, uid int64) (domain.Article, error) {
+	share, err := a.shareRepo.FindByToken(ctx, token)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if share.ArticleID != id || share.Expired(time.Now()) {
+		return domain.Article{}, repository.ErrArticleNotFound
+	}
+	return a.findPublic(ctx, id, uid, true)
+}
+
+// FindInteractable 获取可以点赞、收藏和评论的文章
+// 只有已发布的文章和作者自己的私密文章可以互动，其他情况返回 ErrArticleUnavailable
+func (a *ArticleService) FindInteractable(ctx context.Context, id int64, uid int64) (domain.Article, error) {
+	article, err := a.repo.FindPublicArticleById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrArticleNotFound) {
+			return domain.Article{}, ErrArticleUnavailable
+		}
+		return domain.Article{}, err
+	}
+	switch {
+	case article.Status == domain.ArticleStatusPublished:
+		return article, nil
+	case article.Status == domain.ArticleStatusPrivate && article.Author.ID == uid:
+		return article, nil
+	default:
+		return domain.Article{}, ErrArticleUnavailable
+	}
+}
+
+// findPublic 获取线上库中的文章，只有已发布的文章可以阅读，allowPrivate 为 true 时私密文章也可以阅读，
+// 撤回、已删除等其他状态的文章对外表现为不存在
+func (a *ArticleService) findPublic(ctx context.Context, id int64, uid int64, allowPrivate bool) (domain.Article, error) {
 	// return a.repo.FindPublicArticleById(ctx, id)
 	article, err := a.repo.FindPublicArticleById(ctx, id)
 	if err == nil {
-		go func() {
-			// 发送浏览事件到 Kafka
-			err := a.producer.ProducerViewEvent(ctx, events.ViewEvent{
-				Uid: uid,
-				Aid: id,
-			})
-			if err != nil {
-				// 处理错误
-				fmt.Println("Error producing view event:", err)
-			}
-		}()
+		visible := article.Status == domain.ArticleStatusPublished ||
+			(article.Status == domain.ArticleStatusPrivate && allowPrivate)
+		if !visible {
+			return domain.Article{}, repository.ErrArticleNotFound
+		}
+	}
+	if err == nil {
+		// 历史文章没有存储渲染结果，这里现场渲染
+		if article.HTML == "" {
+			article.HTML = article.RenderHTML()
+		}
+		article.SeriesNav = a.seriesNav(ctx, id)
 	}
 	return article, err
 }
 
-// ListPublic 取出规定时间内的文章列表
-func (a *ArticleService) ListPublic(ctx context.Context, startTime time.Time, offset, limit int) ([]domain.Article, error) {
-	// 根据时间获取文章列表
-	return a.repo.ListPublic(ctx, startTime, offset, limit)
+// RecordView 记录一次文章阅读
+// 浏览事件只在阅读文章详情时发送，点赞、评论等内部查询文章不会增加浏览量
+func (a *ArticleService) RecordView(ctx context.Context, id int64, viewer domain.Viewer) {
+	if viewer.IsBot() {
+		return
+	}
+	go func() {
+		// 请求结束后上下文会被取消，这里使用新的上下文发送浏览事件到 Kafka
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		err := a.producer.ProducerViewEvent(ctx, events.ViewEvent{
+			Uid:     viewer.Uid,
+			Aid:     id,
+			Visitor: viewer.Key(),
+		})
+		if err != nil {
+			// 处理错误
+			fmt.Println("Error producing view event:", err)
+		}
+	}()
+}
+
+// MakePrivate 将已发布的文章设为私密，私密文章不出现在公开列表、搜索、Feed 和榜单中，
+// 只能由作者或者持有分享链接的人阅读。只修改线上库中文章的状态，制作库中未发布的修改不会被公开，
+// 下线的清理由可见性事件的消费者完成
+func (a *ArticleService) MakePrivate(ctx context.Context, articleID, authorID int64) error {
+	// 同时校验文章是否属于当前作者
+	if _, err := a.repo.FindById(ctx, articleID, authorID); err != nil {
+		return err
+	}
+	ok, err := a.repo.SetPublishedStatus(ctx, articleID, authorID,
+		domain.ArticleStatusPublished, domain.ArticleStatusPrivate)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// 已经是私密的文章不需要重复处理
+		pub, err := a.repo.FindPublicArticleById(ctx, articleID)
+		if err == nil && pub.Status == domain.ArticleStatusPrivate {
+			return nil
+		}
+		return ErrArticleNotPublished
+	}
+
+	err = a.producer.ProduceVisibilityEvent(ctx, events.VisibilityEvent{
+		Aid:     articleID,
+		Uid:     authorID,
+		Private: true,
+	})
+	if err != nil {
+		// 文章已经设为私密，这里只记录错误
+		log.Println("Failed to produce article private event:", articleID, err)
+	}
+	return nil
+}
+
+// CreateShare 为作者已发布或私密的文章创建分享链接，expire 为 0 时使用默认有效期
+func (a *ArticleService) CreateShare(ctx context.Context, articleID, authorID int64, expire time.Duration) (domain.ArticleShare, error) {
+	if expire == 0 {
+		expire = DefaultShareExpire
+	}
+	if expire < minShareExpire || expire > maxShareExpire {
+		return domain.ArticleShare{}, ErrInvalidShareExpire
+	}
+	// 校验文章是否属于当前作者
+	if _, err := a.repo.FindById(ctx, articleID, authorID); err != nil {
+		return domain.ArticleShare{}, err
+	}
+	// 分享的是线上库中的文章，草稿和已删除的文章不能分享
+	pub, err := a.repo.FindPublicArticleById(ctx, articleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrArticleNotFound) {
+			return domain.ArticleShare{}, ErrArticleNotShareable
+		}
+		return domain.ArticleShare{}, err
+	}
+	if pub.Status != domain.ArticleStatusPublished && pub.Status != domain.ArticleStatusPrivate {
+		return domain.ArticleShare{}, ErrArticleNotShareable
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return domain.ArticleShare{}, err
+	}
+	share := domain.ArticleShare{
+		ArticleID: articleID,
+		AuthorID:  authorID,
+		Token:     token,
+		ExpireAt:  time.Now().Add(expire),
+	}
+	share.ID, err = a.shareRepo.Create(ctx, share)
+	return share, err
+}
+
+// ListShares 获取作者为文章创建的分享链接
+func (a *ArticleService) ListShares(ctx context.Context, articleID, authorID int64) ([]domain.ArticleShare, error) {
+	return a.shareRepo.ListByArticle(ctx, articleID, authorID)
+}
+
+// RevokeShare 撤销分享链接，撤销后令牌立即失效
+func (a *ArticleService) RevokeShare(ctx context.Context, shareID, authorID int64) error {
+	return a.shareRepo.Revoke(ctx, shareID, authorID)
+}
+
+// newShareToken 生成随机的分享令牌
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// seriesNav 获取文章在系列中的上一篇/下一篇，文章不属于任何系列时返回 nil
+func (a *ArticleService) seriesNav(ctx context.Context, articleID int64) *domain.SeriesNav {
+	if a.seriesRepo == nil {
+		return nil
+	}
+	series, err := a.seriesRepo.FindByArticleID(ctx, articleID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrSeriesNotFound) {
+			log.Println("Failed to find article series:", articleID, err)
+		}
+		return nil
+	}
+	published, err := a.repo.FindPublicByIds(ctx, series.ArticleIDs)
+	if err != nil {
+		log.Println("Failed to find series articles:", series.ID, err)
+		return nil
+	}
+	return series.Nav(articleID, published)
+}
+
+// ListPublic 按更新时间倒序取出已发布的文章，从游标之后开始
+func (a *ArticleService) ListPublic(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return a.repo.ListPublic(ctx, cursor, limit)
+}
+
+// ListPublicByAuthor 获取作者信息和作者已发布的文章，用于作者主页
+func (a *ArticleService) ListPublicByAuthor(ctx context.Context, authorID int64, cursor domain.ArticleCursor, limit int) (domain.Author, []domain.Article, error) {
+	return a.repo.ListPublicByAuthor(ctx, authorID, cursor, limit)
+}
+
+// syncArticleTags 更新已发布文章的标签关联和标签计数
+func (a *ArticleService) syncArticleTags(ctx context.Context, articleID int64, tags []string) {
+	if a.tagRepo == nil {
+		return
+	}
+	if err := a.tagRepo.SetArticleTags(ctx, articleID, tags); err != nil {
+		log.Println("Failed to sync article tags:", articleID, err)
+	}
+}
+
+// bindImages 记录文章引用的图片，长时间没有被引用的图片会被定时任务清理
+func (a *ArticleService) bindImages(ctx context.Context, articleID int64, urls []string) {
+	if a.ossSvc == nil {
+		return
+	}
+	if err := a.ossSvc.BindArticleImages(ctx, articleID, urls); err != nil {
+		log.Println("Failed to bind article images:", articleID, err)
+	}
+}
+
+// resolveMentions 解析文章中 @ 提及的用户，并在文章 HTML 中渲染为链接
+func (a *ArticleService) resolveMentions(ctx context.Context, article *domain.Article) []domain.Mention {
+	if a.mentionSvc == nil {
+		return nil
+	}
+	mentions, err := a.mentionSvc.Resolve(ctx, article.PlainText(), article.Author.ID)
+	if err != nil {
+		log.Println("Failed to resolve article mentions:", article.ID, err)
+		return nil
+	}
+	article.HTML = render.LinkMentions(article.HTML, domain.MentionLinks(mentions))
+	return mentions
+}
+
+// saveMentions 保存已发布文章的提及，新被提及的用户会收到通知
+func (a *ArticleService) saveMentions(ctx context.Context, articleID int64, article domain.Article, mentions []domain.Mention) {
+	if a.mentionSvc == nil {
+		return
+	}
+	err := a.mentionSvc.Save(ctx, MentionTarget{
+		Biz:       "article",
+		BizID:     articleID,
+		ArticleID: articleID,
+		AuthorID:  article.Author.ID,
+		Content:   article.Title,
+	}, mentions)
+	if err != nil {
+		log.Println("Failed to save article mentions:", articleID, err)
+	}
 }
 
-// updateArticleIndex 确保获取最新的文章数据并更新索引
-func (a *ArticleService) updateArticleIndex(ctx context.Context, articleID, authorID int64) error {
+// updateArticleIndex 根据线上库中的文章更新索引
+// 搜索的是读者能看到的内容，制作库中未发布的修改不进入索引；私密文章只修改线上库的状态，
+// 所以文章是否可以被搜索到也只看线上库，没有发布或者不是已发布状态的文章从索引中删除
+func (a *ArticleService) updateArticleIndex(ctx context.Context, articleID int64) error {
 	if a.searchSvc == nil {
 		return nil
 	}
 
-	// 获取最新的文章数据
-	fullArticle, err := a.repo.FindById(ctx, articleID, authorID)
+	// 获取线上库中最新的文章数据
+	pub, err := a.repo.FindPublicArticleById(ctx, articleID)
+	if errors.Is(err, repository.ErrArticleNotFound) || (err == nil && pub.Status != domain.ArticleStatusPublished) {
+		if err := a.searchSvc.DeleteArticleIndex(ctx, articleID); err != nil {
+			return fmt.Errorf("failed to delete article %d index: %w", articleID, err)
+		}
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to find article %d for indexing: %w", articleID, err)
 	}
 
 	// 更新索引，不忽略错误
-	if err := a.searchSvc.IndexArticle(ctx, fullArticle); err != nil {
+	if err := a.searchSvc.IndexArticle(ctx, pub); err != nil {
 		return fmt.Errorf("failed to index article %d: %w", articleID, err)
 	}
 
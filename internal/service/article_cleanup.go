@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	events "github.com/Fairy-nn/inspora/internal/events/article"
+	feedevents "github.com/Fairy-nn/inspora/internal/events/feed"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+// ArticleCleanupService 处理文章删除和可见性变化事件，清理文章的关联数据
+// 每一步都是幂等的，事件重复消费不会产生副作用
+type ArticleCleanupService struct {
+	articleRepo     repository.ArticleRepository
+	interactionRepo repository.InteractionRepositoryInterface
+	commentRepo     repository.CommentRepository
+	rankingRepo     repository.RankingRepositoryInterface
+	feedProd        feedevents.Producer // Feed 的清理需要遍历所有粉丝，交给 Feed 消费者异步完成
+	tagRepo         repository.TagRepository
+	seriesRepo      repository.SeriesRepository
+	collabRepo      repository.CollaboratorRepository
+	searchSvc       SearchService
+	ossSvc          OSSServiceInterface
+	attachmentSvc   AttachmentServiceInterface
+	mentionRepo     repository.MentionRepository
+}
+
+func NewArticleCleanupService(articleRepo repository.ArticleRepository,
+	interactionRepo repository.InteractionRepositoryInterface,
+	commentRepo repository.CommentRepository,
+	rankingRepo repository.RankingRepositoryInterface,
+	feedProd feedevents.Producer,
+	tagRepo repository.TagRepository, seriesRepo repository.SeriesRepository,
+	collabRepo repository.CollaboratorRepository,
+	searchSvc SearchService, ossSvc OSSServiceInterface,
+	attachmentSvc AttachmentServiceInterface,
+	mentionRepo repository.MentionRepository) *ArticleCleanupService {
+	return &ArticleCleanupService{
+		articleRepo:     articleRepo,
+		interactionRepo: interactionRepo,
+		commentRepo:     commentRepo,
+		rankingRepo:     rankingRepo,
+		feedProd:        feedProd,
+		tagRepo:         tagRepo,
+		seriesRepo:      seriesRepo,
+		collabRepo:      collabRepo,
+		searchSvc:       searchSvc,
+		ossSvc:          ossSvc,
+		attachmentSvc:   attachmentSvc,
+		mentionRepo:     mentionRepo,
+	}
+}
+
+// HandleArticleDeleted 清理已删除文章的关联数据
+// 软删除只让文章下线：清理缓存、搜索索引、标签、榜单和 Feed；
+// 彻底删除还会删除线上库记录、互动计数、评论、提及、系列关系、协作者、附件以及不再被引用的图片
+func (s *ArticleCleanupService) HandleArticleDeleted(ctx context.Context, event events.DeletedEvent) error {
+	var errs []error
+	step := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if event.Permanent {
+		step("published article", s.articleRepo.DeletePublished(ctx, event.Aid, event.Uid))
+	} else {
+		step("article cache", s.articleRepo.ClearCache(ctx, event.Aid, event.Uid))
+	}
+	s.takeOffline(ctx, event.Aid, event.Uid, step)
+
+	if event.Permanent {
+		step("interaction", s.interactionRepo.DeleteByBiz(ctx, "article", event.Aid))
+		step("comments", s.commentRepo.DeleteByBiz(ctx, "article", event.Aid))
+		step("series", s.removeFromSeries(ctx, event.Aid))
+		step("collaborators", s.collabRepo.RemoveByArticle(ctx, event.Aid))
+		step("images", s.deleteImages(ctx, event.Aid, event.ImgUrls))
+		if s.attachmentSvc != nil {
+			step("attachments", s.attachmentSvc.DeleteByArticle(ctx, event.Aid))
+		}
+		if s.mentionRepo != nil {
+			step("mentions", s.mentionRepo.DeleteByBiz(ctx, "article", []int64{event.Aid}))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleArticleVisibility 文章设为私密后，清理缓存、搜索索引、标签、榜单和 Feed，
+// 线上库中的文章、评论和互动数据都保留，作者和持有分享链接的人仍然可以阅读
+func (s *ArticleCleanupService) HandleArticleVisibility(ctx context.Context, event events.VisibilityEvent) error {
+	if !event.Private {
+		// 重新公开需要作者再次发布，发布流程会重建索引、标签和 Feed
+		return nil
+	}
+	var errs []error
+	step := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	step("article cache", s.articleRepo.ClearCache(ctx, event.Aid, event.Uid))
+	s.takeOffline(ctx, event.Aid, event.Uid, step)
+	return errors.Join(errs...)
+}
+
+// takeOffline 把文章从搜索、标签列表、榜单和 Feed 中移除
+func (s *ArticleCleanupService) takeOffline(ctx context.Context, articleID, authorID int64, step func(name string, err error)) {
+	if s.searchSvc != nil {
+		step("search index", s.searchSvc.DeleteArticleIndex(ctx, articleID))
+	}
+	if s.tagRepo != nil {
+		step("tags", s.tagRepo.SetArticleTags(ctx, articleID, nil))
+	}
+	step("ranking", s.rankingRepo.RemoveFromTopN(ctx, articleID))
+	step("feed", s.feedProd.ProduceArticleDeletedEvent(ctx, authorID, articleID))
+}
+
+// removeFromSeries 把文章从所属的系列中移除
+func (s *ArticleCleanupService) removeFromSeries(ctx context.Context, articleID int64) error {
+	if s.seriesRepo == nil {
+		return nil
+	}
+	series, err := s.seriesRepo.FindByArticleID(ctx, articleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSeriesNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.seriesRepo.RemoveArticle(ctx, series.ID, articleID)
+}
+
+// deleteImages 删除文章中不再被其他文章引用的图片
+func (s *ArticleCleanupService) deleteImages(ctx context.Context, articleID int64, urls []string) error {
+	if s.ossSvc == nil {
+		return nil
+	}
+	// 先解除文章对图片的引用，仍被其他文章引用的图片之后由定时任务重新确认
+	if err := s.ossSvc.BindArticleImages(ctx, articleID, nil); err != nil {
+		return err
+	}
+	var errs []error
+	for _, url := range urls {
+		inUse, err := s.articleRepo.ImgUrlInUse(ctx, url, articleID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if inUse {
+			continue
+		}
+		if err := s.ossSvc.DeleteFile(ctx, url); err != nil {
+			// 外链图片不属于当前存储，无法删除，记录后跳过
+			log.Println("Failed to delete article image:", url, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+var (
+	// ErrInvalidCollaboratorRole 只能邀请编辑者或查看者
+	ErrInvalidCollaboratorRole = errors.New("只能邀请编辑者或查看者")
+	// ErrInvalidCollaborator 不能邀请自己
+	ErrInvalidCollaborator = errors.New("不能邀请自己协作")
+)
+
+// CollaborationServiceInterface 文章协作服务接口
+type CollaborationServiceInterface interface {
+	// Invite 作者邀请用户协作，已经是协作者时修改角色
+	Invite(ctx context.Context, ownerID, articleID, userID int64, role domain.CollaboratorRole) error
+	// Respond 被邀请的用户接受或拒绝邀请
+	Respond(ctx context.Context, userID, articleID int64, accept bool) error
+	// Remove 作者移除协作者，或者协作者自己退出协作
+	Remove(ctx context.Context, operatorID, articleID, userID int64) error
+	// Members 获取文章的协作者，作者和协作者都可以查看
+	Members(ctx context.Context, uid, articleID int64) ([]domain.Collaborator, error)
+	// Invitations 获取用户还没有处理的邀请
+	Invitations(ctx context.Context, userID int64) ([]domain.Collaborator, error)
+	// ListArticles 获取用户参与协作的文章
+	ListArticles(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+}
+
+type CollaborationService struct {
+	articleRepo repository.ArticleRepository
+	collabRepo  repository.CollaboratorRepository
+	userRepo    repository.UserRepositoryInterface
+}
+
+func NewCollaborationService(articleRepo repository.ArticleRepository,
+	collabRepo repository.CollaboratorRepository,
+	userRepo repository.UserRepositoryInterface) CollaborationServiceInterface {
+	return &CollaborationService{
+		articleRepo: articleRepo,
+		collabRepo:  collabRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// Invite 作者邀请用户协作，邀请需要对方接受之后才生效
+func (s *CollaborationService) Invite(ctx context.Context, ownerID, articleID, userID int64, role domain.CollaboratorRole) error {
+	if !role.Invitable() {
+		return ErrInvalidCollaboratorRole
+	}
+	if userID == ownerID {
+		return ErrInvalidCollaborator
+	}
+	// 只有作者可以邀请，同时校验文章是否存在
+	if _, err := s.articleRepo.FindById(ctx, articleID, ownerID); err != nil {
+		return err
+	}
+	// 被邀请的用户必须存在
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+	return s.collabRepo.Invite(ctx, domain.Collaborator{
+		ArticleID: articleID,
+		UserID:    userID,
+		Role:      role,
+		InviterID: ownerID,
+	})
+}
+
+// Respond 接受邀请后角色生效，拒绝邀请直接删除邀请记录
+func (s *CollaborationService) Respond(ctx context.Context, userID, articleID int64, accept bool) error {
+	if accept {
+		return s.collabRepo.Accept(ctx, articleID, userID)
+	}
+	c, err := s.collabRepo.Find(ctx, articleID, userID)
+	if err != nil {
+		return err
+	}
+	if c.Active() {
+		// 已经接受的邀请不能拒绝，需要退出协作
+		return repository.ErrCollaboratorNotFound
+	}
+	return s.collabRepo.Remove(ctx, articleID, userID)
+}
+
+// Remove 作者可以移除任何协作者，协作者只能移除自己
+func (s *CollaborationService) Remove(ctx context.Context, operatorID, articleID, userID int64) error {
+	if operatorID != userID {
+		if _, err := s.articleRepo.FindById(ctx, articleID, operatorID); err != nil {
+			if errors.Is(err, repository.ErrArticleNotFound) {
+				return ErrArticlePermissionDenied
+			}
+			return err
+		}
+	}
+	return s.collabRepo.Remove(ctx, articleID, userID)
+}
+
+// Members 获取文章的协作者，包括还没有接受邀请的
+func (s *CollaborationService) Members(ctx context.Context, uid, articleID int64) ([]domain.Collaborator, error) {
+	article, err := s.articleRepo.GetByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := collaboratorRole(ctx, s.collabRepo, article, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !role.CanView() {
+		return nil, repository.ErrArticleNotFound
+	}
+	return s.collabRepo.ListByArticle(ctx, articleID)
+}
+
+// Invitations 获取用户还没有处理的邀请
+func (s *CollaborationService) Invitations(ctx context.Context, userID int64) ([]domain.Collaborator, error) {
+	return s.collabRepo.ListInvitations(ctx, userID)
+}
+
+// ListArticles 获取用户已接受邀请参与协作的文章
+func (s *CollaborationService) ListArticles(ctx context.Context, userID int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return s.articleRepo.ListByCollaborator(ctx, userID, cursor, limit)
+}
+
+// collaboratorRole 获取用户在文章中的角色，作者是所有者，协作者只有接受邀请之后角色才生效
+func collaboratorRole(ctx context.Context, collabRepo repository.CollaboratorRepository,
+	article domain.Article, uid int64) (domain.CollaboratorRole, error) {
+	if article.Author.ID == uid {
+		return domain.CollaboratorRoleOwner, nil
+	}
+	if collabRepo == nil {
+		return domain.CollaboratorRoleUnknown, nil
+	}
+	c, err := collabRepo.Find(ctx, article.ID, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrCollaboratorNotFound) {
+			return domain.CollaboratorRoleUnknown, nil
+		}
+		return domain.CollaboratorRoleUnknown, err
+	}
+	if !c.Active() {
+		return domain.CollaboratorRoleUnknown, nil
+	}
+	return c.Role, nil
+}
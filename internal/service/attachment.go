@@ -0,0 +1,510 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	// 分片大小的上下限，客户端指定的分片大小超出范围时会被调整
+	minChunkSize = 1 << 20
+	maxChunkSize = 16 << 20
+	// 单个文件最多的分片数量
+	maxChunkParts = 10000
+	// 合并分片的锁的有效期，需要覆盖合并一个最大文件的时间
+	chunkCompleteLockTTL = 10 * time.Minute
+)
+
+var (
+	// ErrInvalidAttachment 文件名、大小或者分片参数不合法
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrUnsupportedAttachmentType 附件只支持 PDF 和常见的压缩包
+	ErrUnsupportedAttachmentType = errors.New("unsupported attachment type")
+	// ErrInvalidChecksum 校验和不是十六进制的 SHA-256
+	ErrInvalidChecksum = errors.New("checksum must be a hex encoded SHA-256")
+	// ErrChecksumMismatch 上传的内容和声明的校验和不一致
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrInvalidPart 分片序号超出范围，或者分片大小不正确
+	ErrInvalidPart = errors.New("invalid part")
+	// ErrChunkUploadIncomplete 还有分片没有上传
+	ErrChunkUploadIncomplete = errors.New("upload is incomplete")
+	// ErrChunkUploadInProgress 分片正在合并
+	ErrChunkUploadInProgress = errors.New("upload is being completed")
+)
+
+// 支持的附件类型及对应的扩展名，按文件头识别
+var attachmentTypes = map[string]string{
+	"application/pdf":              ".pdf",
+	"application/zip":              ".zip",
+	"application/x-gzip":           ".gz",
+	"application/x-rar-compressed": ".rar",
+	"application/x-7z-compressed":  ".7z",
+}
+
+// AttachmentOptions 附件上传配置
+type AttachmentOptions struct {
+	// MaxSize 单个附件的最大字节数
+	MaxSize int64
+	// ChunkSize 客户端没有指定时使用的分片大小
+	ChunkSize int64
+	// Expire 分片上传任务的有效期，过期之后已上传的分片会被清理
+	Expire time.Duration
+}
+
+// AttachmentServiceInterface 文章附件服务
+// 附件通过分片上传：申请上传任务、逐个上传分片、合并分片，也可以中途放弃
+type AttachmentServiceInterface interface {
+	// InitiateUpload 申请分片上传任务，只有文章的作者和编辑者可以上传附件
+	InitiateUpload(ctx context.Context, uid, articleID int64, filename string, size int64,
+		checksum string, chunkSize int64) (domain.ChunkUpload, error)
+	// UploadPart 上传第 part 个分片，checksum 为分片的 SHA-256，重复上传同一个分片会覆盖
+	UploadPart(ctx context.Context, uid int64, uploadID string, part int, r io.Reader, checksum string) error
+	// UploadStatus 获取上传任务和已上传的分片，断点续传时使用
+	UploadStatus(ctx context.Context, uid int64, uploadID string) (domain.ChunkUpload, error)
+	// CompleteUpload 合并分片，校验整个文件的 SHA-256 后登记为文章的附件
+	CompleteUpload(ctx context.Context, uid int64, uploadID string) (domain.Attachment, error)
+	// AbortUpload 放弃上传，删除已上传的分片
+	AbortUpload(ctx context.Context, uid int64, uploadID string) error
+	// List 获取文章的附件，能阅读文章的用户都可以查看
+	List(ctx context.Context, uid, articleID int64) ([]domain.Attachment, error)
+	// Delete 删除附件，上传者和文章的作者、编辑者可以删除
+	Delete(ctx context.Context, uid, id int64) error
+	// DeleteByArticle 删除文章的所有附件，文章彻底删除时使用
+	DeleteByArticle(ctx context.Context, articleID int64) error
+	// CleanupExpired 清理过期的分片上传任务，返回清理的数量
+	CleanupExpired(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type AttachmentService struct {
+	repo        repository.AttachmentRepository
+	articleRepo repository.ArticleRepository
+	collabRepo  repository.CollaboratorRepository
+	store       storage.Storage
+	opts        AttachmentOptions
+}
+
+func NewAttachmentService(repo repository.AttachmentRepository, articleRepo repository.ArticleRepository,
+	collabRepo repository.CollaboratorRepository, store storage.Storage, opts AttachmentOptions) AttachmentServiceInterface {
+	return &AttachmentService{
+		repo:        repo,
+		articleRepo: articleRepo,
+		collabRepo:  collabRepo,
+		store:       store,
+		opts:        opts,
+	}
+}
+
+// InitiateUpload 申请分片上传任务，分片大小超出范围时会被调整，以返回的任务为准
+func (s *AttachmentService) InitiateUpload(ctx context.Context, uid, articleID int64, filename string, size int64,
+	checksum string, chunkSize int64) (domain.ChunkUpload, error) {
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if filename == "" || filename == "." || filename == "/" || len(filename) > 255 || size <= 0 {
+		return domain.ChunkUpload{}, ErrInvalidAttachment
+	}
+	if s.opts.MaxSize > 0 && size > s.opts.MaxSize {
+		return domain.ChunkUpload{}, ErrFileTooLarge
+	}
+	checksum, ok := normalizeChecksum(checksum)
+	if !ok {
+		return domain.ChunkUpload{}, ErrInvalidChecksum
+	}
+	if chunkSize <= 0 {
+		chunkSize = s.opts.ChunkSize
+	}
+	chunkSize = min(max(chunkSize, minChunkSize), maxChunkSize)
+	totalParts := int((size + chunkSize - 1) / chunkSize)
+	if totalParts > maxChunkParts {
+		return domain.ChunkUpload{}, ErrInvalidAttachment
+	}
+	if err := s.authorizeEdit(ctx, articleID, uid); err != nil {
+		return domain.ChunkUpload{}, err
+	}
+
+	u := domain.ChunkUpload{
+		ID:         strings.ReplaceAll(uuid.New().String(), "-", ""),
+		UploaderID: uid,
+		ArticleID:  articleID,
+		Filename:   filename,
+		Size:       size,
+		ChunkSize:  chunkSize,
+		TotalParts: totalParts,
+		SHA256:     checksum,
+		Parts:      map[int]string{},
+		ExpireAt:   time.Now().Add(s.opts.Expire),
+	}
+	if err := s.repo.CreateUpload(ctx, u); err != nil {
+		return domain.ChunkUpload{}, err
+	}
+	return u, nil
+}
+
+// UploadPart 上传分片，分片大小必须和任务的分片大小一致，校验和不一致时拒绝
+// 第一个分片包含文件头，上传时就校验文件类型，避免上传完整个文件才发现类型不支持
+func (s *AttachmentService) UploadPart(ctx context.Context, uid int64, uploadID string, part int,
+	r io.Reader, checksum string) error {
+	checksum, ok := normalizeChecksum(checksum)
+	if !ok {
+		return ErrInvalidChecksum
+	}
+	u, err := s.getUpload(ctx, uid, uploadID)
+	if err != nil {
+		return err
+	}
+	size := u.PartSize(part)
+	if size <= 0 {
+		return ErrInvalidPart
+	}
+	data, err := io.ReadAll(io.LimitReader(r, size+1))
+	if err != nil {
+		return fmt.Errorf("failed to read part: %w", err)
+	}
+	if int64(len(data)) != size {
+		return ErrInvalidPart
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return ErrChecksumMismatch
+	}
+	if part == 1 {
+		if _, _, ok := detectAttachmentType(data); !ok {
+			return ErrUnsupportedAttachmentType
+		}
+	}
+
+	err = s.store.Put(ctx, chunkPartKey(u.ID, part), bytes.NewReader(data), size, "application/octet-stream")
+	if err != nil {
+		return fmt.Errorf("failed to put part: %w", err)
+	}
+	return s.repo.SetPart(ctx, u, part, checksum)
+}
+
+// UploadStatus 获取上传任务和已上传的分片
+func (s *AttachmentService) UploadStatus(ctx context.Context, uid int64, uploadID string) (domain.ChunkUpload, error) {
+	return s.getUpload(ctx, uid, uploadID)
+}
+
+// CompleteUpload 按顺序读取各个分片写入最终的文件，写入的同时计算整个文件的 SHA-256
+// 校验失败时删除合并出来的文件，但保留分片和任务，客户端可以重新上传有问题的分片
+func (s *AttachmentService) CompleteUpload(ctx context.Context, uid int64, uploadID string) (domain.Attachment, error) {
+	u, err := s.getUpload(ctx, uid, uploadID)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if missing := u.MissingParts(); len(missing) > 0 {
+		return domain.Attachment{}, fmt.Errorf("%w: %d parts missing", ErrChunkUploadIncomplete, len(missing))
+	}
+	// 每次合并使用不同的 token，合并超时之后不会误删别的请求拿到的锁
+	token := uuid.New().String()
+	locked, err := s.repo.LockUpload(ctx, u.ID, token, chunkCompleteLockTTL)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if !locked {
+		return domain.Attachment{}, ErrChunkUploadInProgress
+	}
+	defer func() {
+		if err := s.repo.UnlockUpload(ctx, u.ID, token); err != nil {
+			log.Println("Failed to unlock chunk upload:", u.ID, err)
+		}
+	}()
+
+	// 1. 根据第一个分片的文件头确定文件类型
+	head, err := s.readHead(ctx, chunkPartKey(u.ID, 1))
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	mimeType, ext, ok := detectAttachmentType(head)
+	if !ok {
+		return domain.Attachment{}, ErrUnsupportedAttachmentType
+	}
+
+	// 2. 合并分片，同时计算整个文件的校验和
+	objectKey := fmt.Sprintf("attachments/%s/%s%s", time.Now().Format("2006/01/02"), uuid.New().String(), ext)
+	keys := make([]string, 0, u.TotalParts)
+	for n := 1; n <= u.TotalParts; n++ {
+		keys = append(keys, chunkPartKey(u.ID, n))
+	}
+	parts := &partReader{ctx: ctx, store: s.store, keys: keys}
+	defer parts.Close()
+	hash := sha256.New()
+	if err = s.store.Put(ctx, objectKey, io.TeeReader(parts, hash), u.Size, mimeType); err != nil {
+		s.deleteObject(ctx, objectKey)
+		return domain.Attachment{}, fmt.Errorf("failed to assemble parts: %w", err)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != u.SHA256 {
+		s.deleteObject(ctx, objectKey)
+		return domain.Attachment{}, ErrChecksumMismatch
+	}
+
+	// 3. 登记为文章的附件
+	attachment := domain.Attachment{
+		ArticleID:  u.ArticleID,
+		UploaderID: u.UploaderID,
+		Filename:   u.Filename,
+		ObjectKey:  objectKey,
+		Size:       u.Size,
+		MimeType:   mimeType,
+		SHA256:     u.SHA256,
+		Ctime:      time.Now(),
+	}
+	attachment.ID, err = s.repo.Create(ctx, attachment)
+	if err != nil {
+		s.deleteObject(ctx, objectKey)
+		return domain.Attachment{}, fmt.Errorf("failed to record attachment: %w", err)
+	}
+	attachment.URL = s.store.URL(objectKey)
+
+	// 4. 删除分片和上传任务，失败的部分由过期清理兜底
+	s.deleteParts(ctx, u)
+	if err := s.repo.DeleteUpload(ctx, u.ID); err != nil {
+		log.Println("Failed to delete chunk upload:", u.ID, err)
+	}
+	return attachment, nil
+}
+
+// AbortUpload 放弃上传，删除已上传的分片和上传任务
+func (s *AttachmentService) AbortUpload(ctx context.Context, uid int64, uploadID string) error {
+	u, err := s.getUpload(ctx, uid, uploadID)
+	if err != nil {
+		return err
+	}
+	s.deleteParts(ctx, u)
+	return s.repo.DeleteUpload(ctx, u.ID)
+}
+
+// List 获取文章的附件，公开文章所有人都可以查看，未公开的文章只有作者和协作者可以查看
+func (s *AttachmentService) List(ctx context.Context, uid, articleID int64) ([]domain.Attachment, error) {
+	if err := s.authorizeView(ctx, articleID, uid); err != nil {
+		return nil, err
+	}
+	attachments, err := s.repo.FindByArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range attachments {
+		attachments[i].URL = s.store.URL(attachments[i].ObjectKey)
+	}
+	return attachments, nil
+}
+
+// Delete 删除附件
+func (s *AttachmentService) Delete(ctx context.Context, uid, id int64) error {
+	a, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.UploaderID != uid {
+		if err := s.authorizeEdit(ctx, a.ArticleID, uid); err != nil {
+			return err
+		}
+	}
+	if err := s.store.Delete(ctx, a.ObjectKey); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, a.ID)
+}
+
+// DeleteByArticle 删除文章的所有附件
+func (s *AttachmentService) DeleteByArticle(ctx context.Context, articleID int64) error {
+	attachments, err := s.repo.FindByArticle(ctx, articleID)
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		if err := s.store.Delete(ctx, a.ObjectKey); err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, a.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CleanupExpired 清理过期的分片上传任务，任务信息已经不存在时只能跳过分片
+func (s *AttachmentService) CleanupExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	ids, err := s.repo.ExpiredUploads(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		u, err := s.repo.GetUpload(ctx, id)
+		switch {
+		case err == nil:
+			s.deleteParts(ctx, u)
+		case !errors.Is(err, repository.ErrChunkUploadNotFound):
+			return 0, err
+		}
+		if err := s.repo.DeleteUpload(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// getUpload 获取用户自己的、还没有过期的上传任务，其他人的任务对外表现为不存在
+func (s *AttachmentService) getUpload(ctx context.Context, uid int64, uploadID string) (domain.ChunkUpload, error) {
+	u, err := s.repo.GetUpload(ctx, uploadID)
+	if err != nil {
+		return domain.ChunkUpload{}, err
+	}
+	if u.UploaderID != uid || time.Now().After(u.ExpireAt) {
+		return domain.ChunkUpload{}, repository.ErrChunkUploadNotFound
+	}
+	return u, nil
+}
+
+// authorizeEdit 校验用户可以修改文章
+func (s *AttachmentService) authorizeEdit(ctx context.Context, articleID, uid int64) error {
+	article, err := s.articleRepo.GetByID(ctx, articleID)
+	if err != nil {
+		return err
+	}
+	role, err := collaboratorRole(ctx, s.collabRepo, article, uid)
+	if err != nil {
+		return err
+	}
+	if role == domain.CollaboratorRoleUnknown {
+		return repository.ErrArticleNotFound
+	}
+	if !role.CanEdit() {
+		return ErrArticlePermissionDenied
+	}
+	return nil
+}
+
+// authorizeView 校验用户可以阅读文章
+func (s *AttachmentService) authorizeView(ctx context.Context, articleID, uid int64) error {
+	pub, err := s.articleRepo.FindPublicArticleById(ctx, articleID)
+	if err == nil && pub.Status == domain.ArticleStatusPublished {
+		return nil
+	}
+	if err != nil && !errors.Is(err, repository.ErrArticleNotFound) {
+		return err
+	}
+	article, err := s.articleRepo.GetByID(ctx, articleID)
+	if err != nil {
+		return err
+	}
+	role, err := collaboratorRole(ctx, s.collabRepo, article, uid)
+	if err != nil {
+		return err
+	}
+	if !role.CanView() {
+		return repository.ErrArticleNotFound
+	}
+	return nil
+}
+
+// readHead 读取对象开头的一段内容，用于识别文件类型
+func (s *AttachmentService) readHead(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+// deleteParts 删除任务的所有分片，没有上传的分片删除时也不会报错
+func (s *AttachmentService) deleteParts(ctx context.Context, u domain.ChunkUpload) {
+	for n := 1; n <= u.TotalParts; n++ {
+		s.deleteObject(ctx, chunkPartKey(u.ID, n))
+	}
+}
+
+func (s *AttachmentService) deleteObject(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		log.Println("Failed to delete object:", key, err)
+	}
+}
+
+// chunkPartKey 分片在存储中的临时对象键
+func chunkPartKey(uploadID string, part int) string {
+	return fmt.Sprintf("tmp/chunks/%s/%d", uploadID, part)
+}
+
+// normalizeChecksum 校验并统一为小写的十六进制 SHA-256
+func normalizeChecksum(checksum string) (string, bool) {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if len(checksum) != sha256.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(checksum); err != nil {
+		return "", false
+	}
+	return checksum, true
+}
+
+// detectAttachmentType 根据文件头识别附件类型
+// http.DetectContentType 不识别 7z，单独判断
+func detectAttachmentType(head []byte) (string, string, bool) {
+	mimeType := http.DetectContentType(head)
+	if bytes.HasPrefix(head, []byte("7z\xBC\xAF\x27\x1C")) {
+		mimeType = "application/x-7z-compressed"
+	}
+	ext, ok := attachmentTypes[mimeType]
+	return mimeType, ext, ok
+}
+
+// partReader 依次读取各个分片，把它们拼接成一个完整的文件，每次只打开一个分片
+type partReader struct {
+	ctx   context.Context
+	store storage.Storage
+	keys  []string
+	cur   io.ReadCloser
+}
+
+func (p *partReader) Read(b []byte) (int, error) {
+	for {
+		if p.cur == nil {
+			if len(p.keys) == 0 {
+				return 0, io.EOF
+			}
+			body, err := p.store.Get(p.ctx, p.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			p.cur, p.keys = body, p.keys[1:]
+		}
+		n, err := p.cur.Read(b)
+		if errors.Is(err, io.EOF) {
+			p.cur.Close()
+			p.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (p *partReader) Close() error {
+	if p.cur == nil {
+		return nil
+	}
+	err := p.cur.Close()
+	p.cur = nil
+	return err
+}
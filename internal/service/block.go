@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+var (
+	// ErrInvalidBlock 不能拉黑或屏蔽自己
+	ErrInvalidBlock = errors.New("不能拉黑或屏蔽自己")
+	// ErrUserBlocked 双方之间存在拉黑关系，不能关注、评论、点赞、私信或打赏
+	ErrUserBlocked = errors.New("你们之间存在拉黑关系，无法进行该操作")
+)
+
+// BlockServiceInterface 拉黑和屏蔽
+// 拉黑之后双方都不能再互动，屏蔽只是不再看到对方的内容
+type BlockServiceInterface interface {
+	// Block 拉黑用户，同时解除双方之间的关注关系
+	Block(ctx context.Context, uid, target int64) error
+	// Unblock 取消拉黑
+	Unblock(ctx context.Context, uid, target int64) error
+	// List 获取用户拉黑的人
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.UserBlock, error)
+	// IsBlocked 两个用户之间是否有任意一方拉黑了另一方，拉黑后双方都不能再互动
+	IsBlocked(ctx context.Context, a, b int64) (bool, error)
+	// CheckInteraction uid 和 others 中任意一个用户之间存在拉黑关系时返回 ErrUserBlocked
+	CheckInteraction(ctx context.Context, uid int64, others ...int64) error
+	// Mute 屏蔽用户
+	Mute(ctx context.Context, uid, target int64) error
+	// Unmute 取消屏蔽
+	Unmute(ctx context.Context, uid, target int64) error
+	// ListMuted 获取用户屏蔽的人
+	ListMuted(ctx context.Context, uid int64, offset, limit int) ([]domain.UserMute, error)
+	// HiddenUsers 获取用户拉黑和屏蔽的人，他们的内容不再展示给该用户
+	HiddenUsers(ctx context.Context, uid int64) (map[int64]bool, error)
+}
+
+type BlockService struct {
+	repo       repository.BlockRepository
+	followRepo repository.FollowRepository
+}
+
+func NewBlockService(repo repository.BlockRepository, followRepo repository.FollowRepository) BlockServiceInterface {
+	return &BlockService{
+		repo:       repo,
+		followRepo: followRepo,
+	}
+}
+
+func (s *BlockService) Block(ctx context.Context, uid, target int64) error {
+	if target <= 0 || uid == target {
+		return ErrInvalidBlock
+	}
+	if err := s.repo.Block(ctx, uid, target); err != nil {
+		return err
+	}
+	// 双方互相取消关注
+	if err := s.followRepo.CancelFollow(ctx, uid, target); err != nil {
+		return err
+	}
+	return s.followRepo.CancelFollow(ctx, target, uid)
+}
+
+func (s *BlockService) Unblock(ctx context.Context, uid, target int64) error {
+	return s.repo.Unblock(ctx, uid, target)
+}
+
+func (s *BlockService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.UserBlock, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.List(ctx, uid, offset, limit)
+}
+
+func (s *BlockService) IsBlocked(ctx context.Context, a, b int64) (bool, error) {
+	return s.repo.IsBlockedEither(ctx, a, b)
+}
+
+func (s *BlockService) CheckInteraction(ctx context.Context, uid int64, others ...int64) error {
+	for _, other := range others {
+		if other <= 0 || other == uid {
+			continue
+		}
+		blocked, err := s.repo.IsBlockedEither(ctx, uid, other)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return ErrUserBlocked
+		}
+	}
+	return nil
+}
+
+func (s *BlockService) Mute(ctx context.Context, uid, target int64) error {
+	if target <= 0 || uid == target {
+		return ErrInvalidBlock
+	}
+	return s.repo.Mute(ctx, uid, target)
+}
+
+func (s *BlockService) Unmute(ctx context.Context, uid, target int64) error {
+	return s.repo.Unmute(ctx, uid, target)
+}
+
+func (s *BlockService) ListMuted(ctx context.Context, uid int64, offset, limit int) ([]domain.UserMute, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListMuted(ctx, uid, offset, limit)
+}
+
+func (s *BlockService) HiddenUsers(ctx context.Context, uid int64) (map[int64]bool, error) {
+	if uid <= 0 {
+		return nil, nil
+	}
+	ids, err := s.repo.HiddenIDs(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+var (
+	ErrInvalidCollection  = errors.New("invalid collection")
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrCollectionItemNotFound 移动收藏时用户没有收藏这篇文章
+	ErrCollectionItemNotFound = errors.New("没有收藏这篇文章")
+	// ErrCollectedInOtherCollection 文章已经收藏在其他收藏夹中，需要移动收藏
+	ErrCollectedInOtherCollection = repository.ErrCollectedInOtherCollection
+)
+
+// 收藏夹中的收藏目前只有文章
+const collectionBiz = "article"
+
+// CollectionServiceInterface 收藏夹服务接口
+type CollectionServiceInterface interface {
+	// Create 创建收藏夹
+	Create(ctx context.Context, c domain.Collection) (int64, error)
+	// Update 修改收藏夹的名称、描述和公开状态，只有收藏夹的主人可以修改
+	Update(ctx context.Context, c domain.Collection) error
+	// Delete 删除收藏夹，其中的收藏移到默认收藏夹
+	Delete(ctx context.Context, uid, id int64) error
+	// Get 获取收藏夹，私密的收藏夹只有主人可以查看
+	Get(ctx context.Context, id, viewer int64) (domain.Collection, error)
+	// List 获取用户的收藏夹，查看别人的收藏夹时只返回公开的
+	List(ctx context.Context, owner, viewer int64, offset, limit int) ([]domain.Collection, error)
+	// AddItem 把文章收藏到指定的收藏夹
+	AddItem(ctx context.Context, uid, cid, articleID int64) error
+	// MoveItem 把已经收藏的文章移到另一个收藏夹
+	MoveItem(ctx context.Context, uid, articleID, cid int64) error
+	// Items 按收藏时间倒序获取收藏夹中的文章，cid 为默认收藏夹时获取 viewer 自己的默认收藏夹
+	// 返回下一页的游标，没有更多时为 0
+	Items(ctx context.Context, cid, viewer, beforeID int64, limit int) ([]domain.CollectionItem, int64, error)
+}
+
+type CollectionService struct {
+	repo           repository.CollectionRepository
+	articleRepo    repository.ArticleRepository
+	interactionSvc InteractionServiceInterface
+}
+
+func NewCollectionService(repo repository.CollectionRepository, articleRepo repository.ArticleRepository,
+	interactionSvc InteractionServiceInterface) CollectionServiceInterface {
+	return &CollectionService{
+		repo:           repo,
+		articleRepo:    articleRepo,
+		interactionSvc: interactionSvc,
+	}
+}
+
+// Create 创建收藏夹
+func (s *CollectionService) Create(ctx context.Context, c domain.Collection) (int64, error) {
+	c, err := s.normalize(c)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, c)
+}
+
+// Update 修改收藏夹
+func (s *CollectionService) Update(ctx context.Context, c domain.Collection) error {
+	c, err := s.normalize(c)
+	if err != nil {
+		return err
+	}
+	err = s.repo.Update(ctx, c)
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		return ErrCollectionNotFound
+	}
+	return err
+}
+
+// Delete 删除收藏夹
+func (s *CollectionService) Delete(ctx context.Context, uid, id int64) error {
+	err := s.repo.Delete(ctx, id, uid)
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		return ErrCollectionNotFound
+	}
+	return err
+}
+
+// Get 获取收藏夹
+func (s *CollectionService) Get(ctx context.Context, id, viewer int64) (domain.Collection, error) {
+	c, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCollectionNotFound) {
+			return domain.Collection{}, ErrCollectionNotFound
+		}
+		return domain.Collection{}, err
+	}
+	// 私密的收藏夹对其他人表现为不存在
+	if !c.VisibleTo(viewer) {
+		return domain.Collection{}, ErrCollectionNotFound
+	}
+	return c, nil
+}
+
+// List 获取用户的收藏夹
+func (s *CollectionService) List(ctx context.Context, owner, viewer int64, offset, limit int) ([]domain.Collection, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.FindByUid(ctx, owner, owner != viewer, offset, limit)
+}
+
+// AddItem 把文章收藏到指定的收藏夹，收藏夹的校验在互动服务中进行
+func (s *CollectionService) AddItem(ctx context.Context, uid, cid, articleID int64) error {
+	return s.interactionSvc.Collect(ctx, collectionBiz, articleID, cid, uid)
+}
+
+// MoveItem 把已经收藏的文章移到另一个收藏夹
+func (s *CollectionService) MoveItem(ctx context.Context, uid, articleID, cid int64) error {
+	if err := checkCollectionOwner(ctx, s.repo, uid, cid); err != nil {
+		return err
+	}
+	err := s.repo.MoveItem(ctx, uid, collectionBiz, articleID, cid)
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		return ErrCollectionItemNotFound
+	}
+	return err
+}
+
+// Items 按收藏时间倒序获取收藏夹中的文章，已经不公开的文章会被跳过
+func (s *CollectionService) Items(ctx context.Context, cid, viewer, beforeID int64, limit int) ([]domain.CollectionItem, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	owner := viewer
+	if cid != domain.DefaultCollectionID {
+		c, err := s.Get(ctx, cid, viewer)
+		if err != nil {
+			return nil, 0, err
+		}
+		owner = c.Uid
+	}
+
+	items, err := s.repo.FindItems(ctx, owner, cid, beforeID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	// 游标按收藏记录计算，跳过的文章不影响翻页
+	var next int64
+	if len(items) == limit {
+		next = items[len(items)-1].ID
+	}
+
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.BizID)
+	}
+	articles, err := s.articleRepo.FindPublicByIds(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[int64]domain.Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+	res := make([]domain.CollectionItem, 0, len(items))
+	for _, item := range items {
+		a, ok := byID[item.BizID]
+		if !ok {
+			continue
+		}
+		item.Article = a
+		res = append(res, item)
+	}
+	return res, next, nil
+}
+
+// normalize 校验收藏夹的名称和描述
+func (s *CollectionService) normalize(c domain.Collection) (domain.Collection, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+	if c.Uid <= 0 || c.Name == "" ||
+		utf8.RuneCountInString(c.Name) > domain.MaxCollectionNameLength ||
+		utf8.RuneCountInString(c.Description) > domain.MaxCollectionDescriptionLength {
+		return domain.Collection{}, ErrInvalidCollection
+	}
+	return c, nil
+}
+
+// checkCollectionOwner 检查收藏夹是否属于用户，默认收藏夹属于所有用户
+func checkCollectionOwner(ctx context.Context, repo repository.CollectionRepository, uid, cid int64) error {
+	if cid == domain.DefaultCollectionID {
+		return nil
+	}
+	c, err := repo.FindByID(ctx, cid)
+	if err != nil {
+		if errors.Is(err, repository.ErrCollectionNotFound) {
+			return ErrCollectionNotFound
+		}
+		return err
+	}
+	if c.Uid != uid {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
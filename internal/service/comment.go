@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Fairy-nn/inspora/internal/domain"
+	commentevents "github.com/Fairy-nn/inspora/internal/events/comment"
 	"github.com/Fairy-nn/inspora/internal/events/feed"
 	"github.com/Fairy-nn/inspora/internal/repository"
 )
@@ -14,6 +16,10 @@ import (
 var (
 	ErrInvalidComment  = errors.New("invalid comment")
 	ErrCommentNotFound = errors.New("comment not found")
+	// ErrCommentPermissionDenied 评论只能由评论者本人编辑，由评论者本人或文章作者删除
+	ErrCommentPermissionDenied = errors.New("没有权限操作该评论")
+	// ErrCommentEditExpired 评论发布超过可编辑时间后不能再编辑
+	ErrCommentEditExpired = errors.New("评论已超过可编辑时间")
 )
 
 type CommentService interface {
@@ -21,29 +27,55 @@ type CommentService interface {
 	CreateComment(ctx context.Context, comment domain.Comment) (int64, error)
 	// GetComment 根据ID获取评论
 	GetComment(ctx context.Context, id int64) (domain.Comment, error)
-	// GetComments 获取评论列表，包括子评论
-	GetComments(ctx context.Context, biz string, bizID int64, minID int64, limit int) ([]domain.Comment, error)
+	// GetComments 获取评论列表，包括子评论，uid 用于填充当前用户的点赞状态
+	GetComments(ctx context.Context, biz string, bizID int64, minID int64, limit int, uid int64) ([]domain.Comment, error)
 	// GetChildrenComments 获取子评论列表
-	GetChildrenComments(ctx context.Context, parentID int64, minID int64, limit int) ([]domain.Comment, error)
-	// DeleteComment 删除评论
+	GetChildrenComments(ctx context.Context, parentID int64, minID int64, limit int, uid int64) ([]domain.Comment, error)
+	// DeleteComment 删除评论，评论者本人和文章作者都可以删除
 	DeleteComment(ctx context.Context, id int64, userID int64) error
 	// GetHotComments 获取热门评论
-	GetHotComments(ctx context.Context, biz string, bizID int64, limit int) ([]domain.Comment, error)
+	GetHotComments(ctx context.Context, biz string, bizID int64, limit int, uid int64) ([]domain.Comment, error)
+	// LikeComment 点赞或取消点赞评论
+	LikeComment(ctx context.Context, id int64, uid int64, like bool) error
+	// EditComment 编辑评论，只有评论者本人在可编辑时间内可以编辑
+	EditComment(ctx context.Context, id int64, uid int64, content string) (domain.Comment, error)
+	// GetCommentHistory 获取评论的历史版本
+	GetCommentHistory(ctx context.Context, id int64) ([]domain.CommentRevision, error)
 	// GetUserNameById 获取用户名称
 	GetUserNameById(ctx context.Context, userID int64) (string, error)
 }
 
+// 评论在互动模块中的业务类型
+const commentBiz = "comment"
+
+// CommentOptions 评论配置
+type CommentOptions struct {
+	EditWindow time.Duration // 评论发布后可以编辑的时间
+}
+
 type commentService struct {
-	repo       repository.CommentRepository
-	feedProd   feed.Producer
-	articleSvc ArticleServiceInterface
+	repo           repository.CommentRepository
+	feedProd       feed.Producer
+	articleSvc     ArticleServiceInterface
+	interactionSvc InteractionServiceInterface
+	engagementProd commentevents.Producer
+	mentionSvc     MentionServiceInterface
+	blockSvc       BlockServiceInterface
+	opts           CommentOptions
 }
 
-func NewCommentService(repo repository.CommentRepository, feedProd feed.Producer, articleSvc ArticleServiceInterface) CommentService {
+func NewCommentService(repo repository.CommentRepository, feedProd feed.Producer, articleSvc ArticleServiceInterface,
+	interactionSvc InteractionServiceInterface, engagementProd commentevents.Producer, mentionSvc MentionServiceInterface,
+	blockSvc BlockServiceInterface, opts CommentOptions) CommentService {
 	return &commentService{
-		repo:       repo,
-		feedProd:   feedProd,
-		articleSvc: articleSvc,
+		repo:           repo,
+		feedProd:       feedProd,
+		articleSvc:     articleSvc,
+		interactionSvc: interactionSvc,
+		engagementProd: engagementProd,
+		mentionSvc:     mentionSvc,
+		blockSvc:       blockSvc,
+		opts:           opts,
 	}
 }
 
@@ -54,11 +86,13 @@ func (s *commentService) CreateComment(ctx context.Context, comment domain.Comme
 		return 0, ErrInvalidComment
 	}
 	// 如果是子评论，需要校验父评论是否存在
+	var parentUserID int64
 	if comment.ParentID > 0 {
 		parent, err := s.repo.GetComment(ctx, comment.ParentID)
-		if err != nil {
+		if err != nil || parent.IsDeleted() {
 			return 0, ErrCommentNotFound
 		}
+		parentUserID = parent.UserID
 
 		// 设置根评论ID
 		if parent.RootID > 0 {
@@ -69,24 +103,47 @@ func (s *commentService) CreateComment(ctx context.Context, comment domain.Comme
 		}
 	}
 
+	// 获取文章作者ID
+	var authorID int64
+	if comment.Biz == "article" {
+		// 文章不存在、已下线或者是别人的私密文章时不能评论
+		article, err := s.articleSvc.FindInteractable(ctx, comment.BizID, comment.UserID)
+		if err != nil {
+			return 0, err
+		}
+		authorID = article.Author.ID
+	}
+	// 和文章作者或者被回复的人之间存在拉黑关系时不能评论
+	if err := s.blockSvc.CheckInteraction(ctx, comment.UserID, authorID, parentUserID); err != nil {
+		return 0, err
+	}
+
+	if comment.Ctime <= 0 {
+		comment.Ctime = time.Now().Unix()
+	}
 	commentID, err := s.repo.CreateComment(ctx, comment)
 	if err != nil {
 		return 0, err
 	}
 
+	// 更新热门评论排行：根评论加入排行，回复增加根评论的回复数
+	if comment.ParentID > 0 {
+		s.produceEngagement(commentevents.EngagementEvent{
+			Type: commentevents.EngagementReplied, Biz: comment.Biz, BizID: comment.BizID,
+			CommentID: comment.RootID, Delta: 1,
+		})
+	} else {
+		s.produceEngagement(commentevents.EngagementEvent{
+			Type: commentevents.EngagementCreated, Biz: comment.Biz, BizID: comment.BizID,
+			CommentID: commentID, Ctime: comment.Ctime,
+		})
+	}
+
+	comment.ID = commentID
+	s.syncMentions(ctx, comment)
+
 	// 发送评论feed事件
 	if comment.Biz == "article" && s.feedProd != nil {
-		// 获取文章作者ID
-		var authorID int64
-		// 从文章服务获取文章信息
-		article, err := s.articleSvc.FindPublicArticleById(ctx, comment.BizID, comment.UserID)
-		if err != nil {
-			// 仅记录错误日志，不影响主流程
-			fmt.Printf("获取文章信息失败: %v\n", err)
-		} else {
-			authorID = article.Author.ID
-		}
-
 		// 异步发送Feed事件，避免阻塞主流程
 		go func(aid int64) {
 			ctxTimeout, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -113,7 +170,7 @@ func (s *commentService) GetComment(ctx context.Context, id int64) (domain.Comme
 }
 
 // GetComments 获取评论列表，包括子评论
-func (s *commentService) GetComments(ctx context.Context, biz string, bizID int64, minID int64, limit int) ([]domain.Comment, error) {
+func (s *commentService) GetComments(ctx context.Context, biz string, bizID int64, minID int64, limit int, uid int64) ([]domain.Comment, error) {
 	// 默认限制为20条
 	if limit <= 0 || limit > 100 {
 		limit = 20
@@ -138,34 +195,58 @@ func (s *commentService) GetComments(ctx context.Context, biz string, bizID int6
 		}
 		comments[i].Children = ptrChildren
 	}
+	comments = s.filterHidden(ctx, comments, uid)
+	s.fillDetails(ctx, comments, uid)
 	return comments, nil
 }
 
 // GetChildrenComments 获取子评论列表
-func (s *commentService) GetChildrenComments(ctx context.Context, parentID int64, minID int64, limit int) ([]domain.Comment, error) {
+func (s *commentService) GetChildrenComments(ctx context.Context, parentID int64, minID int64, limit int, uid int64) ([]domain.Comment, error) {
 	// 默认限制为20条
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
-	return s.repo.GetChildrenComments(ctx, parentID, minID, limit)
+	comments, err := s.repo.GetChildrenComments(ctx, parentID, minID, limit)
+	if err != nil {
+		return nil, err
+	}
+	comments = s.filterHidden(ctx, comments, uid)
+	s.fillDetails(ctx, comments, uid)
+	return comments, nil
 }
 
 // DeleteComment 删除评论
+// 删除只是把评论标记为已删除，回复保留，列表中用占位内容代替
 func (s *commentService) DeleteComment(ctx context.Context, id int64, userID int64) error {
-	// 检查评论是否存在，并且是否属于当前用户
 	comment, err := s.repo.GetComment(ctx, id)
 	if err != nil {
 		return ErrCommentNotFound
 	}
-	if comment.UserID != userID {
-		return errors.New("没有权限删除该评论")
+	if comment.IsDeleted() {
+		return nil
+	}
+	// 评论者本人或者被评论文章的作者才能删除
+	if comment.UserID != userID && !s.isArticleAuthor(ctx, comment, userID) {
+		return ErrCommentPermissionDenied
 	}
-	// 删除评论及其所有子评论
 	return s.repo.DeleteComment(ctx, id)
 }
 
+// isArticleAuthor 判断用户是否是评论所属文章的作者
+func (s *commentService) isArticleAuthor(ctx context.Context, comment domain.Comment, userID int64) bool {
+	if comment.Biz != "article" {
+		return false
+	}
+	// 不是作者或协作者时制作库会返回文章不存在
+	article, err := s.articleSvc.FindById(ctx, comment.BizID, userID)
+	if err != nil {
+		return false
+	}
+	return article.Author.ID == userID
+}
+
 // GetHotComments 获取热门评论
-func (s *commentService) GetHotComments(ctx context.Context, biz string, bizID int64, limit int) ([]domain.Comment, error) {
+func (s *commentService) GetHotComments(ctx context.Context, biz string, bizID int64, limit int, uid int64) ([]domain.Comment, error) {
 	// 默认获取3条热门评论
 	if limit <= 0 || limit > 100 {
 		limit = 3
@@ -187,9 +268,202 @@ func (s *commentService) GetHotComments(ctx context.Context, biz string, bizID i
 		}
 		comments[i].Children = ptrChildren
 	}
+	comments = s.filterHidden(ctx, comments, uid)
+	s.fillDetails(ctx, comments, uid)
 	return comments, nil
 }
 
+// LikeComment 点赞或取消点赞评论，重复操作直接返回
+func (s *commentService) LikeComment(ctx context.Context, id int64, uid int64, like bool) error {
+	comment, err := s.repo.GetComment(ctx, id)
+	if err != nil || comment.IsDeleted() {
+		return ErrCommentNotFound
+	}
+	// 和评论者之间存在拉黑关系时不能点赞，取消点赞不受影响
+	if like {
+		if err := s.blockSvc.CheckInteraction(ctx, uid, comment.UserID); err != nil {
+			return err
+		}
+	}
+
+	// 点赞记录的插入和删除本身是幂等的，只有真正改变了点赞状态才发送排行事件，
+	// 并发的重复请求不会让热门评论的点赞数多算
+	var changed bool
+	delta := int64(1)
+	if like {
+		changed, err = s.interactionSvc.Like(ctx, commentBiz, id, uid)
+	} else {
+		changed, err = s.interactionSvc.CancelLike(ctx, commentBiz, id, uid)
+		delta = -1
+	}
+	if err != nil || !changed {
+		return err
+	}
+
+	// 只有根评论参与热门评论排行
+	if comment.IsRootComment() {
+		s.produceEngagement(commentevents.EngagementEvent{
+			Type: commentevents.EngagementLiked, Biz: comment.Biz, BizID: comment.BizID,
+			CommentID: comment.ID, Delta: delta,
+		})
+	}
+	return nil
+}
+
+// EditComment 编辑评论，编辑前的内容保存为历史版本
+func (s *commentService) EditComment(ctx context.Context, id int64, uid int64, content string) (domain.Comment, error) {
+	if strings.TrimSpace(content) == "" {
+		return domain.Comment{}, ErrInvalidComment
+	}
+	comment, err := s.repo.GetComment(ctx, id)
+	if err != nil || comment.IsDeleted() {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+	if comment.UserID != uid {
+		return domain.Comment{}, ErrCommentPermissionDenied
+	}
+	now := time.Now()
+	if now.Sub(time.Unix(comment.Ctime, 0)) > s.opts.EditWindow {
+		return domain.Comment{}, ErrCommentEditExpired
+	}
+
+	if comment.Content != content {
+		comment, err = s.repo.EditComment(ctx, id, content, now.Unix())
+		if err != nil {
+			if errors.Is(err, repository.ErrCommentNotFound) {
+				return domain.Comment{}, ErrCommentNotFound
+			}
+			return domain.Comment{}, err
+		}
+		s.syncMentions(ctx, comment)
+	}
+	comments := []domain.Comment{comment}
+	s.fillDetails(ctx, comments, uid)
+	return comments[0], nil
+}
+
+// GetCommentHistory 获取评论的历史版本，已删除评论的历史内容同样不再展示
+func (s *commentService) GetCommentHistory(ctx context.Context, id int64) ([]domain.CommentRevision, error) {
+	comment, err := s.repo.GetComment(ctx, id)
+	if err != nil || comment.IsDeleted() {
+		return nil, ErrCommentNotFound
+	}
+	return s.repo.GetCommentHistory(ctx, id)
+}
+
+// syncMentions 解析评论中的 @ 提及并保存，新被提及的用户会收到通知
+// 提及只是附加信息，失败时只记录日志，不影响评论的发布
+func (s *commentService) syncMentions(ctx context.Context, comment domain.Comment) {
+	if s.mentionSvc == nil {
+		return
+	}
+	mentions, err := s.mentionSvc.Resolve(ctx, comment.Content, comment.UserID)
+	if err != nil {
+		fmt.Printf("解析评论提及失败: %v\n", err)
+		return
+	}
+	target := MentionTarget{
+		Biz:      commentBiz,
+		BizID:    comment.ID,
+		AuthorID: comment.UserID,
+		Content:  comment.Content,
+	}
+	if comment.Biz == "article" {
+		target.ArticleID = comment.BizID
+	}
+	if err := s.mentionSvc.Save(ctx, target, mentions); err != nil {
+		fmt.Printf("保存评论提及失败: %v\n", err)
+	}
+}
+
+// fillDetails 填充评论及其子评论的点赞数、当前用户的点赞状态和提及的用户
+// 这些数据只是附加信息，获取失败时不影响评论列表的返回
+func (s *commentService) fillDetails(ctx context.Context, comments []domain.Comment, uid int64) {
+	all := make([]*domain.Comment, 0, len(comments))
+	for i := range comments {
+		all = append(all, &comments[i])
+		all = append(all, comments[i].Children...)
+	}
+	if len(all) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(all))
+	for _, c := range all {
+		ids = append(ids, c.ID)
+	}
+
+	interactions, err := s.interactionSvc.GetByIds(ctx, commentBiz, ids)
+	if err != nil {
+		fmt.Printf("获取评论点赞数失败: %v\n", err)
+	}
+	var liked map[int64]bool
+	if uid > 0 {
+		liked, err = s.interactionSvc.LikedIds(ctx, commentBiz, ids, uid)
+		if err != nil {
+			fmt.Printf("获取评论点赞状态失败: %v\n", err)
+		}
+	}
+	var mentions map[int64][]domain.Mention
+	if s.mentionSvc != nil {
+		mentions, err = s.mentionSvc.Find(ctx, commentBiz, ids)
+		if err != nil {
+			fmt.Printf("获取评论提及失败: %v\n", err)
+		}
+	}
+	for _, c := range all {
+		c.LikeCnt = interactions[c.ID].LikeCnt
+		c.Liked = liked[c.ID]
+		// 已删除评论的内容已经被替换为占位内容
+		if !c.IsDeleted() {
+			c.Mentions = mentions[c.ID]
+		}
+	}
+}
+
+// filterHidden 去掉当前用户拉黑或屏蔽的人发的评论，包括预加载的子评论
+func (s *commentService) filterHidden(ctx context.Context, comments []domain.Comment, uid int64) []domain.Comment {
+	if uid <= 0 || len(comments) == 0 {
+		return comments
+	}
+	hidden, err := s.blockSvc.HiddenUsers(ctx, uid)
+	if err != nil {
+		fmt.Printf("获取拉黑和屏蔽的用户失败: %v\n", err)
+		return comments
+	}
+	if len(hidden) == 0 {
+		return comments
+	}
+	res := comments[:0]
+	for _, c := range comments {
+		if hidden[c.UserID] {
+			continue
+		}
+		children := c.Children[:0]
+		for _, child := range c.Children {
+			if !hidden[child.UserID] {
+				children = append(children, child)
+			}
+		}
+		c.Children = children
+		res = append(res, c)
+	}
+	return res
+}
+
+// produceEngagement 异步发送评论互动事件，失败只记录日志，排行榜重建时会恢复
+func (s *commentService) produceEngagement(event commentevents.EngagementEvent) {
+	if s.engagementProd == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := s.engagementProd.ProduceEngagementEvent(ctx, event); err != nil {
+			fmt.Printf("发送评论互动事件失败: %v\n", err)
+		}
+	}()
+}
+
 // GetUserNameById 获取用户名称
 func (s *commentService) GetUserNameById(ctx context.Context, userID int64) (string, error) {
 	user, err := s.repo.GetUserById(ctx, userID)
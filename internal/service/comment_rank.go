@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	commentevents "github.com/Fairy-nn/inspora/internal/events/comment"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+// CommentRankService 消费评论互动事件，增量更新热门评论排行
+type CommentRankService struct {
+	repo repository.CommentRepository
+}
+
+func NewCommentRankService(repo repository.CommentRepository) commentevents.EngagementHandler {
+	return &CommentRankService{
+		repo: repo,
+	}
+}
+
+// HandleCommentEngagement 根据事件类型调整根评论的热度
+func (s *CommentRankService) HandleCommentEngagement(ctx context.Context, event commentevents.EngagementEvent) error {
+	switch event.Type {
+	case commentevents.EngagementCreated:
+		return s.repo.AddHotComment(ctx, event.Biz, event.BizID, event.CommentID, event.Ctime)
+	case commentevents.EngagementLiked:
+		return s.repo.IncrHotScore(ctx, event.Biz, event.BizID, event.CommentID, event.Delta, 0)
+	case commentevents.EngagementReplied:
+		return s.repo.IncrHotScore(ctx, event.Biz, event.BizID, event.CommentID, 0, event.Delta)
+	default:
+		return nil
+	}
+}
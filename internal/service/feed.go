@@ -39,6 +39,8 @@ type FeedService struct {
 	userRepo   repository.UserRepositoryInterface // 用户信息仓储层接口
 	// Kafka 生产者，用于异步推送 Feed 事件
 	feedProd feed.Producer
+	// 拉黑和屏蔽服务，用户拉黑或屏蔽的人的动态不出现在 Feed 中
+	blockSvc BlockServiceInterface
 	// 判定大V用户的粉丝数阈值（超过此值视为大V）
 	bigVThreshold int64
 	// 用户收件箱最大长度，超出部分将被淘汰
@@ -50,7 +52,8 @@ func NewFeedService(
 	followRepo repository.FollowRepository,
 	articleSvc ArticleServiceInterface,
 	userRepo repository.UserRepositoryInterface,
-	feedProd feed.Producer) FeedServiceInterface {
+	feedProd feed.Producer,
+	blockSvc BlockServiceInterface) FeedServiceInterface {
 	// 初始化并返回服务实例
 	return &FeedService{
 		feedRepo:      feedRepo,             // 注入 Feed 仓储
@@ -58,6 +61,7 @@ func NewFeedService(
 		articleSvc:    articleSvc,           // 注入文章服务
 		userRepo:      userRepo,             // 注入用户信息仓储
 		feedProd:      feedProd,             // 注入 Kafka 生产者
+		blockSvc:      blockSvc,             // 注入拉黑和屏蔽服务
 		bigVThreshold: 10000, // 配置大V阈值
 		inboxMaxLen:   1000,   // 配置收件箱最大长度
 	}
@@ -112,9 +116,18 @@ func (s *FeedService) GetUserFeed(ctx context.Context, userID int64, offset, lim
 	// 合并收件箱（普通用户推送）和大V发件箱（实时拉取）的内容
 	allItems := append(inboxItems, bigVItems...)
 
+	// 去掉用户拉黑或屏蔽的人的动态
+	hidden, err := s.blockSvc.HiddenUsers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取拉黑和屏蔽的用户失败: %w", err)
+	}
+
 	// 将 Feed 项目转换为可展示的形式（添加用户信息、格式化内容等）
 	renderedItems := make([]domain.RenderedFeedItem, 0, len(allItems))
 	for _, item := range allItems {
+		if hidden[item.ActorID] {
+			continue
+		}
 		rendered, err := s.renderFeedItem(ctx, item)
 		if err != nil {
 			// 渲染失败则跳过该项目（保证主流程不受影响）
@@ -177,7 +190,7 @@ func (s *FeedService) renderFeedItem(ctx context.Context, item domain.UserFeedIt
 		rendered.Object = "article"                            // 对象类型
 		rendered.ObjectID = article.ID                         // 对象 ID
 		rendered.Title = article.Title                         // 文章标题
-		rendered.Summary = truncateText(article.Content, 100)  // 截取内容前100个字符作为摘要
+		rendered.Summary = article.GenerateAbstract()          // 纯文本的前100个字符作为摘要
 		rendered.Link = fmt.Sprintf("/article/%d", article.ID) // 文章详情链接
 	case "like":
 		// 点赞事件
@@ -259,14 +272,6 @@ func (s *FeedService) renderFeedItem(ctx context.Context, item domain.UserFeedIt
 	return rendered, nil
 }
 
-// truncateText 截取字符串到指定长度，并添加省略号
-func truncateText(text string, maxLen int) string {
-	if len(text) <= maxLen {
-		return text // 长度未超过限制，直接返回原字符串
-	}
-	return text[:maxLen] + "..." // 截断并添加省略号
-}
-
 // RebuildUserFeed 重建用户的Feed (包括收件箱和发件箱)
 func (s *FeedService) RebuildUserFeed(ctx context.Context, userID int64, sinceDays int) error {
 	// 1. 清空用户的收件箱和发件箱
@@ -28,17 +28,23 @@ type FollowService interface {
 type followService struct {
 	repo     repository.FollowRepository
 	feedProd feed.Producer
+	blockSvc BlockServiceInterface
 }
 
-func NewFollowService(repo repository.FollowRepository, feedProd feed.Producer) FollowService {
+func NewFollowService(repo repository.FollowRepository, feedProd feed.Producer, blockSvc BlockServiceInterface) FollowService {
 	return &followService{
 		repo:     repo,
 		feedProd: feedProd,
+		blockSvc: blockSvc,
 	}
 }
 
 // Follow 关注用户
 func (s *followService) Follow(ctx context.Context, follower, followee int64) error {
+	// 双方之间存在拉黑关系时不能关注
+	if err := s.blockSvc.CheckInteraction(ctx, follower, followee); err != nil {
+		return err
+	}
 	//  return s.repo.Follow(ctx, follower, followee)
 	err := s.repo.Follow(ctx, follower, followee)
 	if err != nil {
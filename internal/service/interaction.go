@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Fairy-nn/inspora/internal/domain"
+	events "github.com/Fairy-nn/inspora/internal/events/article"
 	"github.com/Fairy-nn/inspora/internal/events/feed"
 	"github.com/Fairy-nn/inspora/internal/repository"
 	"golang.org/x/sync/errgroup"
@@ -15,27 +16,40 @@ import (
 
 type InteractionServiceInterface interface {
 	IncrViewCount(ctx context.Context, biz string, id int64) error
-	Like(ctx context.Context, biz string, bizId int64, uid int64) error
-	CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	// Like 和 CancelLike 返回点赞状态是否发生了变化，重复操作返回 false
+	Like(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	CancelLike(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
 	// cid是收藏夹的id
 	Collect(ctx context.Context, biz string, bizId int64, cid, uid int64) error
-	CancelCollect(ctx context.Context, biz string, bizId int64, cid, uid int64) error
+	// CancelCollect 取消收藏，文章收藏在哪个收藏夹都会被移除
+	CancelCollect(ctx context.Context, biz string, bizId int64, uid int64) error
 	Get(ctx context.Context, biz string, bizId, uid int64) (domain.Interaction, error)
 	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interaction, error)
+	Liked(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+	// LikedIds 批量获取用户的点赞状态，只包含已点赞的ID
+	LikedIds(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]bool, error)
 }
 
 type InteractionService struct {
 	repo       repository.InteractionRepositoryInterface
 	feedProd   feed.Producer
+	countProd  events.Producer // 点赞量、收藏量的变化通过计数事件批量写入数据库
 	articleSvc ArticleServiceInterface
+	blockSvc   BlockServiceInterface
+	// 收藏夹仓储，收藏到指定收藏夹时校验收藏夹属于当前用户
+	collectionRepo repository.CollectionRepository
 }
 
 // 创建一个新的交互服务实例
-func NewInteractionService(repo repository.InteractionRepositoryInterface, feedProd feed.Producer, articleSvc ArticleServiceInterface) InteractionServiceInterface {
+func NewInteractionService(repo repository.InteractionRepositoryInterface, feedProd feed.Producer, countProd events.Producer,
+	articleSvc ArticleServiceInterface, blockSvc BlockServiceInterface, collectionRepo repository.CollectionRepository) InteractionServiceInterface {
 	return &InteractionService{
-		repo:       repo,
-		feedProd:   feedProd,
-		articleSvc: articleSvc,
+		repo:           repo,
+		feedProd:       feedProd,
+		countProd:      countProd,
+		articleSvc:     articleSvc,
+		blockSvc:       blockSvc,
+		collectionRepo: collectionRepo,
 	}
 }
 
@@ -45,25 +59,30 @@ func (i *InteractionService) IncrViewCount(ctx context.Context, biz string, id i
 }
 
 // Like 增加点赞量
-func (i *InteractionService) Like(ctx context.Context, biz string, bizId int64, uid int64) error {
-	err := i.repo.IncrLikeCount(ctx, biz, bizId, uid)
-	if err != nil {
-		return err
-	}
-	// 发送用户点赞事件
-	if biz == "article" && i.feedProd != nil {
-		// 获取文章作者ID
-		var authorID int64
-
-		// 从文章服务获取文章信息
-		article, err := i.articleSvc.FindPublicArticleById(ctx, bizId, uid)
+func (i *InteractionService) Like(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	// 获取文章作者ID
+	var authorID int64
+	if biz == "article" {
+		// 文章不存在、已下线或者是别人的私密文章时不能点赞
+		article, err := i.articleSvc.FindInteractable(ctx, bizId, uid)
 		if err != nil {
-			// 仅记录错误日志，不影响主流程
-			fmt.Printf("获取文章信息失败: %v\n", err)
-		} else {
-			authorID = article.Author.ID
+			return false, err
+		}
+		authorID = article.Author.ID
+		// 和文章作者之间存在拉黑关系时不能点赞
+		if err := i.blockSvc.CheckInteraction(ctx, uid, authorID); err != nil {
+			return false, err
 		}
+	}
 
+	changed, err := i.repo.IncrLikeCount(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		// 重复点赞不改变计数，也不再发送Feed事件
+		return false, err
+	}
+	i.produceCount(ctx, events.CountEvent{Biz: biz, BizID: bizId, LikeDelta: 1})
+	// 发送用户点赞事件
+	if biz == "article" && i.feedProd != nil {
 		// 异步发送Feed事件，避免阻塞主流程
 		go func(aid int64) {
 			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -74,36 +93,43 @@ func (i *InteractionService) Like(ctx context.Context, biz string, bizId int64,
 			}
 		}(authorID)
 	}
-	return nil
+	return true, nil
 }
 
 // CancelLike 减少点赞量
-func (i *InteractionService) CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error {
-
-	return i.repo.DecrLikeCount(ctx, biz, bizId, uid)
+func (i *InteractionService) CancelLike(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	changed, err := i.repo.DecrLikeCount(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		return false, err
+	}
+	i.produceCount(ctx, events.CountEvent{Biz: biz, BizID: bizId, LikeDelta: -1})
+	return true, nil
 }
 
-// Collect 增加收藏量
+// Collect 增加收藏量，cid 为收藏夹ID，0 表示默认收藏夹
 func (i *InteractionService) Collect(ctx context.Context, biz string, bizId int64, cid, uid int64) error {
-	err := i.repo.AddCollectionItem(ctx, biz, bizId, cid, uid)
-	if err != nil {
-		fmt.Printf("添加收藏失败: %v\n", err)
+	if err := checkCollectionOwner(ctx, i.collectionRepo, uid, cid); err != nil {
+		return err
 	}
+	// 获取文章作者ID
+	var authorID int64
+	if biz == "article" {
+		// 文章不存在、已下线或者是别人的私密文章时不能收藏
+		article, err := i.articleSvc.FindInteractable(ctx, bizId, uid)
+		if err != nil {
+			return err
+		}
+		authorID = article.Author.ID
+	}
+	changed, err := i.repo.AddCollectionItem(ctx, biz, bizId, cid, uid)
+	if err != nil || !changed {
+		// 已经收藏过的不改变计数
+		return err
+	}
+	i.produceCount(ctx, events.CountEvent{Biz: biz, BizID: bizId, CollectDelta: 1})
 	// 发送用户收藏事件
 	// 发送Feed事件（仅对文章收藏发送）
 	if biz == "article" && i.feedProd != nil {
-		// 获取文章作者ID
-		var authorID int64
-
-		// 从文章服务获取文章信息
-		article, err := i.articleSvc.FindPublicArticleById(ctx, bizId, uid)
-		if err != nil {
-			// 仅记录错误日志，不影响主流程
-			fmt.Printf("获取文章信息失败: %v\n", err)
-		} else {
-			authorID = article.Author.ID
-		}
-
 		// 异步发送Feed事件，避免阻塞主流程
 		go func(aid int64) {
 			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -119,8 +145,31 @@ func (i *InteractionService) Collect(ctx context.Context, biz string, bizId int6
 }
 
 // CancelCollect 减少收藏量
-func (i *InteractionService) CancelCollect(ctx context.Context, biz string, bizId int64, cid, uid int64) error {
-	return i.repo.RemoveCollectionItem(ctx, biz, bizId, cid, uid)
+func (i *InteractionService) CancelCollect(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := i.repo.RemoveCollectionItem(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		return err
+	}
+	i.produceCount(ctx, events.CountEvent{Biz: biz, BizID: bizId, CollectDelta: -1})
+	return nil
+}
+
+// produceCount 发送计数事件，发送失败时直接写入数据库，保证计数不丢失
+func (i *InteractionService) produceCount(ctx context.Context, evt events.CountEvent) {
+	err := i.countProd.ProduceCountEvent(ctx, evt)
+	if err == nil {
+		return
+	}
+	fmt.Printf("发送计数事件失败，直接写入数据库: %v\n", err)
+	err = i.repo.BatchIncrCounts(ctx, []domain.InteractionDelta{{
+		Biz:        evt.Biz,
+		BizID:      evt.BizID,
+		LikeCnt:    evt.LikeDelta,
+		CollectCnt: evt.CollectDelta,
+	}})
+	if err != nil {
+		fmt.Printf("写入点赞量、收藏量失败: %v\n", err)
+	}
 }
 
 // Get 获取交互信息
@@ -189,3 +238,8 @@ func (i *InteractionService) Collected(ctx context.Context, biz string, bizId, u
 func (i *InteractionService) GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interaction, error) {
 	return i.repo.GetByIds(ctx, biz, ids)
 }
+
+// LikedIds 批量获取用户的点赞状态
+func (i *InteractionService) LikedIds(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]bool, error) {
+	return i.repo.LikedIds(ctx, biz, ids, uid)
+}
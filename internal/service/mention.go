@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/events/notification"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/pkg/render"
+)
+
+// 通知中内容摘要的最大字数
+const mentionSnippetLen = 50
+
+// MentionTarget 提及所在的内容
+type MentionTarget struct {
+	Biz       string // article 或 comment
+	BizID     int64
+	ArticleID int64 // 所属文章
+	AuthorID  int64 // 内容的作者，即发出提及的用户
+	Content   string
+}
+
+// MentionServiceInterface 解析、保存内容中的 @ 提及，并通知被提及的用户
+type MentionServiceInterface interface {
+	// Resolve 解析内容中的 @用户名，只保留能唯一对应到一个用户的提及，不包括作者自己
+	Resolve(ctx context.Context, content string, authorID int64) ([]domain.Mention, error)
+	// Save 保存内容的提及，只通知新被提及的用户
+	Save(ctx context.Context, target MentionTarget, mentions []domain.Mention) error
+	// Find 批量获取内容的提及
+	Find(ctx context.Context, biz string, bizIDs []int64) (map[int64][]domain.Mention, error)
+}
+
+type MentionService struct {
+	repo     repository.MentionRepository
+	userRepo repository.UserRepositoryInterface
+	producer notification.Producer
+}
+
+func NewMentionService(repo repository.MentionRepository, userRepo repository.UserRepositoryInterface,
+	producer notification.Producer) MentionServiceInterface {
+	return &MentionService{
+		repo:     repo,
+		userRepo: userRepo,
+		producer: producer,
+	}
+}
+
+// Resolve 解析内容中的 @用户名
+// 用户名并不唯一，同名的多个用户无法确定提及的是谁，这种提及直接忽略
+func (s *MentionService) Resolve(ctx context.Context, content string, authorID int64) ([]domain.Mention, error) {
+	names := render.Mentions(content, domain.MaxMentions)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	users, err := s.userRepo.FindByUsernames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string][]domain.User, len(users))
+	for _, u := range users {
+		byName[u.Username] = append(byName[u.Username], u)
+	}
+
+	mentions := make([]domain.Mention, 0, len(names))
+	for _, name := range names {
+		matched := byName[name]
+		if len(matched) != 1 || matched[0].ID == authorID {
+			continue
+		}
+		mentions = append(mentions, domain.Mention{
+			UserID:   matched[0].ID,
+			Username: name,
+		})
+	}
+	return mentions, nil
+}
+
+// Save 保存内容的提及，编辑内容后重新保存时已经提及过的用户不会再收到通知
+func (s *MentionService) Save(ctx context.Context, target MentionTarget, mentions []domain.Mention) error {
+	added, err := s.repo.Replace(ctx, target.Biz, target.BizID, target.AuthorID, mentions)
+	if err != nil {
+		return err
+	}
+	if s.producer == nil {
+		return nil
+	}
+	snippet := []rune(target.Content)
+	if len(snippet) > mentionSnippetLen {
+		snippet = append(snippet[:mentionSnippetLen], []rune("...")...)
+	}
+	now := time.Now().UnixMilli()
+	for _, m := range added {
+		err := s.producer.ProduceEvent(ctx, notification.Event{
+			Type:      notification.TypeMention,
+			UserID:    m.UserID,
+			ActorID:   target.AuthorID,
+			Biz:       target.Biz,
+			BizID:     target.BizID,
+			ArticleID: target.ArticleID,
+			Content:   string(snippet),
+			Ctime:     now,
+		})
+		if err != nil {
+			// 通知发送失败不影响内容的发布
+			log.Printf("发送提及通知失败, 用户ID: %d, %s: %d, %v", m.UserID, target.Biz, target.BizID, err)
+		}
+	}
+	return nil
+}
+
+// Find 批量获取内容的提及
+func (s *MentionService) Find(ctx context.Context, biz string, bizIDs []int64) (map[int64][]domain.Mention, error) {
+	return s.repo.FindByBiz(ctx, biz, bizIDs)
+}
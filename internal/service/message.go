@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/events/push"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+var (
+	// ErrInvalidMessage 私信内容为空或者太长
+	ErrInvalidMessage = errors.New("私信内容不能为空，且不能超过 2000 字")
+	// ErrMessageToSelf 不能给自己发私信
+	ErrMessageToSelf = errors.New("不能给自己发私信")
+	// ErrMessageReceiverNotFound 接收私信的用户不存在
+	ErrMessageReceiverNotFound = errors.New("用户不存在")
+	// ErrMessageNotAllowed 对方的私信设置不允许，或者双方存在拉黑关系
+	// 拉黑时也返回同样的错误，不让发送方知道自己被拉黑
+	ErrMessageNotAllowed = errors.New("对方暂时不接收你的私信")
+)
+
+// MessageServiceInterface 用户之间的一对一私信
+type MessageServiceInterface interface {
+	// Send 发送私信，接收方在线时实时推送
+	Send(ctx context.Context, senderID, receiverID int64, content string) (domain.Message, error)
+	// History 获取和对方的私信记录，beforeID 为上一页最早一条私信的ID，最新的在前
+	History(ctx context.Context, uid, peerID, beforeID int64, limit int) ([]domain.Message, error)
+	// Conversations 获取用户的会话列表
+	Conversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error)
+	// MarkRead 把和对方的会话标记为已读
+	MarkRead(ctx context.Context, uid, peerID int64) error
+	// UnreadCount 获取所有会话的未读私信数
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+	// Policy 获取谁可以给用户发私信
+	Policy(ctx context.Context, uid int64) (domain.DMPolicy, error)
+	// SetPolicy 设置谁可以给用户发私信
+	SetPolicy(ctx context.Context, uid int64, policy domain.DMPolicy) error
+}
+
+type MessageService struct {
+	repo       repository.MessageRepository
+	userRepo   repository.UserRepositoryInterface
+	followRepo repository.FollowRepository
+	blockSvc   BlockServiceInterface
+	pusher     push.Publisher
+}
+
+func NewMessageService(repo repository.MessageRepository, userRepo repository.UserRepositoryInterface,
+	followRepo repository.FollowRepository, blockSvc BlockServiceInterface,
+	pusher push.Publisher) MessageServiceInterface {
+	return &MessageService{
+		repo:       repo,
+		userRepo:   userRepo,
+		followRepo: followRepo,
+		blockSvc:   blockSvc,
+		pusher:     pusher,
+	}
+}
+
+func (s *MessageService) Send(ctx context.Context, senderID, receiverID int64, content string) (domain.Message, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > domain.MaxMessageLength {
+		return domain.Message{}, ErrInvalidMessage
+	}
+	if senderID == receiverID {
+		return domain.Message{}, ErrMessageToSelf
+	}
+	if _, err := s.userRepo.GetByID(ctx, receiverID); err != nil {
+		if errors.Is(err, repository.ErrUserRecordNotFound) {
+			return domain.Message{}, ErrMessageReceiverNotFound
+		}
+		return domain.Message{}, err
+	}
+	if err := s.checkAllowed(ctx, senderID, receiverID); err != nil {
+		return domain.Message{}, err
+	}
+
+	msg, err := s.repo.Send(ctx, domain.Message{
+		SenderID:   senderID,
+		ReceiverID: receiverID,
+		Content:    content,
+	})
+	if err != nil {
+		return domain.Message{}, err
+	}
+	s.push(ctx, msg)
+	return msg, nil
+}
+
+// checkAllowed 检查拉黑关系和接收方的私信设置
+func (s *MessageService) checkAllowed(ctx context.Context, senderID, receiverID int64) error {
+	blocked, err := s.blockSvc.IsBlocked(ctx, senderID, receiverID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrMessageNotAllowed
+	}
+
+	policy, err := s.repo.Policy(ctx, receiverID)
+	if err != nil {
+		return err
+	}
+	switch policy {
+	case domain.DMPolicyNobody:
+		return ErrMessageNotAllowed
+	case domain.DMPolicyFollowing:
+		// 只接收自己关注的人发来的私信
+		following, err := s.followRepo.IsFollowing(ctx, receiverID, senderID)
+		if err != nil {
+			return err
+		}
+		if !following {
+			return ErrMessageNotAllowed
+		}
+	}
+	return nil
+}
+
+// messagePush 推送给接收方的新私信
+type messagePush struct {
+	ID       int64  `json:"id"`
+	SenderID int64  `json:"sender_id"`
+	Content  string `json:"content"`
+	Ctime    int64  `json:"ctime"`
+}
+
+// push 把私信实时推送给在线的接收方，失败时接收方仍然可以在会话中看到
+func (s *MessageService) push(ctx context.Context, msg domain.Message) {
+	if s.pusher == nil {
+		return
+	}
+	m, err := push.NewMessage(msg.ReceiverID, push.TypeMessage, messagePush{
+		ID:       msg.ID,
+		SenderID: msg.SenderID,
+		Content:  msg.Content,
+		Ctime:    msg.Ctime,
+	})
+	if err == nil {
+		err = s.pusher.Publish(ctx, m)
+	}
+	if err != nil {
+		log.Printf("推送私信失败, 私信ID: %d, %v", msg.ID, err)
+	}
+}
+
+func (s *MessageService) History(ctx context.Context, uid, peerID, beforeID int64, limit int) ([]domain.Message, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.History(ctx, uid, peerID, beforeID, limit)
+}
+
+func (s *MessageService) Conversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	convs, err := s.repo.Conversations(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	// 用户名只是展示信息，获取失败时只返回用户ID
+	for i := range convs {
+		user, err := s.userRepo.GetByID(ctx, convs[i].Peer.ID)
+		if err != nil {
+			log.Printf("获取会话的用户信息失败, 用户ID: %d, %v", convs[i].Peer.ID, err)
+			continue
+		}
+		convs[i].Peer.Name = user.Username
+	}
+	return convs, nil
+}
+
+func (s *MessageService) MarkRead(ctx context.Context, uid, peerID int64) error {
+	return s.repo.MarkRead(ctx, uid, peerID)
+}
+
+func (s *MessageService) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	return s.repo.TotalUnread(ctx, uid)
+}
+
+func (s *MessageService) Policy(ctx context.Context, uid int64) (domain.DMPolicy, error) {
+	return s.repo.Policy(ctx, uid)
+}
+
+func (s *MessageService) SetPolicy(ctx context.Context, uid int64, policy domain.DMPolicy) error {
+	return s.repo.SetPolicy(ctx, uid, policy)
+}
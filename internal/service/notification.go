@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/events/notification"
+	"github.com/Fairy-nn/inspora/internal/events/push"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+// ErrInvalidNotificationType 通知类型不存在
+var ErrInvalidNotificationType = errors.New("通知类型不存在")
+
+// NotificationServiceInterface 站内通知
+type NotificationServiceInterface interface {
+	// List 按最近触发时间倒序获取用户的通知，并填充触发通知的用户
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error)
+	// UnreadCount 获取用户的未读通知数
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+	// MarkRead 标记通知为已读
+	MarkRead(ctx context.Context, uid, id int64) error
+	// MarkAllRead 标记用户的所有通知为已读
+	MarkAllRead(ctx context.Context, uid int64) error
+	// Settings 获取用户每种通知是否关闭
+	Settings(ctx context.Context, uid int64) (map[string]bool, error)
+	// SetMuted 关闭或打开某类通知，关闭期间不再生成这类通知
+	SetMuted(ctx context.Context, uid int64, typ string, muted bool) error
+}
+
+type NotificationService struct {
+	repo     repository.NotificationRepository
+	userRepo repository.UserRepositoryInterface
+}
+
+func NewNotificationService(repo repository.NotificationRepository,
+	userRepo repository.UserRepositoryInterface) NotificationServiceInterface {
+	return &NotificationService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *NotificationService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	notifications, err := s.repo.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户名只是展示信息，获取失败时只返回用户ID
+	names := make(map[int64]string)
+	for i := range notifications {
+		actorID := notifications[i].Actor.ID
+		name, ok := names[actorID]
+		if !ok {
+			user, err := s.userRepo.GetByID(ctx, actorID)
+			if err != nil {
+				log.Printf("获取通知的触发用户失败, 用户ID: %d, %v", actorID, err)
+			}
+			name = user.Username
+			names[actorID] = name
+		}
+		notifications[i].Actor.Name = name
+	}
+	return notifications, nil
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	return s.repo.UnreadCount(ctx, uid)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, uid, id int64) error {
+	return s.repo.MarkRead(ctx, uid, id)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, uid int64) error {
+	return s.repo.MarkAllRead(ctx, uid)
+}
+
+func (s *NotificationService) Settings(ctx context.Context, uid int64) (map[string]bool, error) {
+	muted, err := s.repo.MutedTypes(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]bool, len(domain.NotificationTypes))
+	for _, typ := range domain.NotificationTypes {
+		res[typ] = slices.Contains(muted, typ)
+	}
+	return res, nil
+}
+
+func (s *NotificationService) SetMuted(ctx context.Context, uid int64, typ string, muted bool) error {
+	if !domain.ValidNotificationType(typ) {
+		return ErrInvalidNotificationType
+	}
+	return s.repo.SetMuted(ctx, uid, typ, muted)
+}
+
+// NotificationEventService 消费通知事件，为接收者生成站内通知，并推送给在线的接收者
+type NotificationEventService struct {
+	repo     repository.NotificationRepository
+	pusher   push.Publisher
+	blockSvc BlockServiceInterface
+}
+
+func NewNotificationEventService(repo repository.NotificationRepository, pusher push.Publisher,
+	blockSvc BlockServiceInterface) notification.Handler {
+	return &NotificationEventService{
+		repo:     repo,
+		pusher:   pusher,
+		blockSvc: blockSvc,
+	}
+}
+
+// notificationPush 推送给客户端的新通知，客户端据此更新未读数，需要时再拉取通知列表
+type notificationPush struct {
+	Type      string `json:"type"`
+	Biz       string `json:"biz"`
+	BizID     int64  `json:"biz_id"`
+	ArticleID int64  `json:"article_id,omitempty"`
+	ActorID   int64  `json:"actor_id"`
+	Content   string `json:"content,omitempty"`
+	Amount    int64  `json:"amount,omitempty"`
+	Unread    int64  `json:"unread"`
+	Ctime     int64  `json:"ctime"`
+}
+
+// HandleNotification 保存通知，自己触发的事件和接收者关闭的通知类型直接忽略
+func (s *NotificationEventService) HandleNotification(ctx context.Context, event notification.Event) error {
+	if event.UserID <= 0 || event.UserID == event.ActorID || !domain.ValidNotificationType(event.Type) {
+		return nil
+	}
+	muted, err := s.repo.MutedTypes(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(muted, event.Type) {
+		return nil
+	}
+	// 用户拉黑或屏蔽的人产生的通知直接丢弃，对方不会察觉
+	hidden, err := s.blockSvc.HiddenUsers(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if hidden[event.ActorID] {
+		return nil
+	}
+	err = s.repo.Add(ctx, domain.Notification{
+		UserID:    event.UserID,
+		Type:      event.Type,
+		Biz:       event.Biz,
+		BizID:     event.BizID,
+		ArticleID: event.ArticleID,
+		Actor:     domain.Author{ID: event.ActorID},
+		Content:   event.Content,
+		Amount:    event.Amount,
+		Ctime:     event.Ctime,
+	})
+	if err != nil {
+		return err
+	}
+	s.push(ctx, event)
+	return nil
+}
+
+// push 把新通知推送给在线的接收者，失败只记录日志，不重新处理事件
+func (s *NotificationEventService) push(ctx context.Context, event notification.Event) {
+	if s.pusher == nil {
+		return
+	}
+	unread, err := s.repo.UnreadCount(ctx, event.UserID)
+	if err != nil {
+		log.Printf("获取未读通知数失败, 用户ID: %d, %v", event.UserID, err)
+		return
+	}
+	msg, err := push.NewMessage(event.UserID, push.TypeNotification, notificationPush{
+		Type:      event.Type,
+		Biz:       event.Biz,
+		BizID:     event.BizID,
+		ArticleID: event.ArticleID,
+		ActorID:   event.ActorID,
+		Content:   event.Content,
+		Amount:    event.Amount,
+		Unread:    unread,
+		Ctime:     event.Ctime,
+	})
+	if err == nil {
+		err = s.pusher.Publish(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("推送通知失败, 用户ID: %d, %v", event.UserID, err)
+	}
+}
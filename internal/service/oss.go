@@ -1,149 +1,508 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"mime/multipart"
-	"path/filepath"
-	"strings"
+	"sort"
 	"time"
 
-	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+	"github.com/Fairy-nn/inspora/pkg/imaging"
 	"github.com/google/uuid"
-	"github.com/spf13/viper"
 )
 
+var (
+	// ErrUploadPermissionDenied 只能删除自己上传的文件
+	ErrUploadPermissionDenied = errors.New("只能删除自己上传的文件")
+	// ErrInvalidFileURL 文件地址不属于当前存储
+	ErrInvalidFileURL = errors.New("URL does not belong to this storage")
+	// ErrFileTooLarge 文件超过大小限制
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrUnsupportedFileType 文件内容不是支持的图片格式
+	ErrUnsupportedFileType = errors.New("unsupported file type")
+	// ErrInvalidImage 图片无法解码，或者尺寸超过限制
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrTooManyFiles 一次上传的文件数量超过限制
+	ErrTooManyFiles = errors.New("too many files")
+	// ErrPresignNotSupported 当前存储不支持客户端直传
+	ErrPresignNotSupported = errors.New("presigned upload is not supported by this storage")
+	// ErrUploadCompleted 直传的文件已经确认过
+	ErrUploadCompleted = errors.New("upload already completed")
+	// ErrUploadObjectMissing 确认上传时文件还没有上传到存储
+	ErrUploadObjectMissing = errors.New("uploaded object not found")
+)
+
+// UploadOptions 上传限制和图片处理配置
+type UploadOptions struct {
+	// MaxSize 单个文件的最大字节数
+	MaxSize int64
+	// MaxPixels 图片的最大像素数，防止小文件解码出超大图片
+	MaxPixels int
+	// VariantWidths 需要生成的缩略图宽度
+	VariantWidths []int
+	// PresignExpire 直传地址的有效期
+	PresignExpire time.Duration
+}
+
 type OSSServiceInterface interface {
-	// UploadFile 上传单个文件
-	UploadFile(ctx context.Context, file *multipart.FileHeader) (string, error)
-	// UploadFiles 上传多个文件
-	UploadFiles(ctx context.Context, files []*multipart.FileHeader, maxFiles int) ([]string, error)
-	// DeleteFile 根据文件URL删除文件
+	// UploadFile 上传单张图片，记录上传者，返回原图和缩略图
+	UploadFile(ctx context.Context, uploaderID int64, file *multipart.FileHeader) (domain.UploadedImage, error)
+	// UploadFiles 上传多张图片
+	UploadFiles(ctx context.Context, uploaderID int64, files []*multipart.FileHeader, maxFiles int) ([]domain.UploadedImage, error)
+	// PresignUpload 签发客户端直传的上传地址
+	PresignUpload(ctx context.Context, uploaderID int64, contentType string, size int64) (domain.PresignedUpload, error)
+	// CompleteUpload 确认直传的文件上传完成，校验文件并登记到上传者名下
+	CompleteUpload(ctx context.Context, uploaderID int64, fileURL string) (domain.UploadedImage, error)
+	// AcceptUpload 判断直传地址是否仍然可以上传，只有待确认的上传记录可以写入
+	AcceptUpload(ctx context.Context, objectKey string) error
+	// MaxFileSize 单个文件的最大字节数
+	MaxFileSize() int64
+	// DeleteFile 根据文件URL删除文件，不校验上传者，供系统内部清理使用
 	DeleteFile(ctx context.Context, fileURL string) error
+	// DeleteUserFile 用户删除自己上传的文件
+	DeleteUserFile(ctx context.Context, uid int64, fileURL string) error
+	// BindArticleImages 记录文章引用的图片，文章不再引用的图片解除引用
+	BindArticleImages(ctx context.Context, articleID int64, urls []string) error
+	// CleanupOrphans 删除超过 grace 仍没有被任何文章引用的文件，返回删除的数量
+	CleanupOrphans(ctx context.Context, grace time.Duration, limit int) (int, error)
 }
 
+// OSSService 文件上传服务，文件存放在配置的对象存储中
 type OSSService struct {
-	client     *oss.Client // 阿里云OSS客户端
-	bucket     *oss.Bucket // 操作的目标存储空间
-	bucketName string      // 存储空间名称
-	baseURL    string      // 文件访问的基础URL
+	store       storage.Storage
+	uploadRepo  repository.UploadRepository
+	articleRepo repository.ArticleRepository
+	opts        UploadOptions
 }
 
 // NewOSSService 服务初始化
-func NewOSSService() (OSSServiceInterface, error) {
-	// 从配置文件中获取OSS相关配置
-	endpoint := viper.GetString("oss.endpoint")
-	accessKeyID := viper.GetString("oss.access_key_id")
-	accessKeySecret := viper.GetString("oss.access_key_secret")
-	bucketName := viper.GetString("oss.bucket_name")
-	baseURL := viper.GetString("oss.base_url")
+func NewOSSService(store storage.Storage, uploadRepo repository.UploadRepository,
+	articleRepo repository.ArticleRepository, opts UploadOptions) OSSServiceInterface {
+	widths := append([]int(nil), opts.VariantWidths...)
+	sort.Ints(widths)
+	opts.VariantWidths = widths
+	return &OSSService{
+		store:       store,
+		uploadRepo:  uploadRepo,
+		articleRepo: articleRepo,
+		opts:        opts,
+	}
+}
 
-	// 创建OSS客户端
-	client, err := oss.New(endpoint, accessKeyID, accessKeySecret)
+// MaxFileSize 单个文件的最大字节数
+func (s *OSSService) MaxFileSize() int64 {
+	return s.opts.MaxSize
+}
+
+// UploadFile 上传单张图片
+// 文件类型按内容识别，不信任文件名和 Content-Type；图片会重新编码以去掉 EXIF 等元数据
+func (s *OSSService) UploadFile(ctx context.Context, uploaderID int64, file *multipart.FileHeader) (domain.UploadedImage, error) {
+	// 1. 校验文件大小，客户端声明的大小不可信，读取时再限制一次
+	if s.opts.MaxSize > 0 && file.Size > s.opts.MaxSize {
+		return domain.UploadedImage{}, ErrFileTooLarge
+	}
+	src, err := file.Open()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create OSS client: %w", err)
+		return domain.UploadedImage{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+	data, err := s.readLimited(src)
+	if err != nil {
+		return domain.UploadedImage{}, err
 	}
 
-	// 获取存储空间
-	bucket, err := client.Bucket(bucketName)
+	// 2. 按文件头识别格式，解码后重新编码并生成缩略图
+	original, variants, err := s.processImage(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bucket: %w", err)
+		return domain.UploadedImage{}, err
 	}
 
-	return &OSSService{
-		client:     client,
-		bucket:     bucket,
-		bucketName: bucketName,
-		baseURL:    baseURL,
+	// 3. 生成唯一文件名和存储路径（按日期组织）
+	// 使用 UUID 生成唯一文件名，避免冲突
+	// 按日期（年 / 月 / 日）组织文件，提高存储可读性
+	base := s.newObjectBase()
+
+	// 4. 上传原图和缩略图
+	result, keys, err := s.storeImage(ctx, base, original, variants)
+	if err != nil {
+		return domain.UploadedImage{}, err
+	}
+
+	// 5. 记录上传者，没有被文章引用的文件会在宽限期之后被清理
+	_, err = s.uploadRepo.Create(ctx, domain.Upload{
+		UploaderID: uploaderID,
+		ObjectKey:  keys[0],
+		Size:       result.Size,
+		MimeType:   result.MimeType,
+		Width:      result.Width,
+		Height:     result.Height,
+		Variants:   keys[1:],
+	})
+	if err != nil {
+		// 没有记录的文件无法追踪，直接删除
+		s.deleteObjects(ctx, keys)
+		return domain.UploadedImage{}, fmt.Errorf("failed to record upload: %w", err)
+	}
+
+	// 6. 返回原图和缩略图的公共URL
+	return result, nil
+}
+
+// PresignUpload 签发直传地址，客户端把文件直接上传到存储，不经过 API 服务
+// 先记录一条待确认的上传记录，确认之前不会被当作可用的图片；超时没有确认的记录由孤儿清理任务删除
+func (s *OSSService) PresignUpload(ctx context.Context, uploaderID int64, contentType string, size int64) (domain.PresignedUpload, error) {
+	presigner, ok := s.store.(storage.Presigner)
+	if !ok {
+		return domain.PresignedUpload{}, ErrPresignNotSupported
+	}
+	ext, ok := imaging.Extension(contentType)
+	if !ok {
+		return domain.PresignedUpload{}, ErrUnsupportedFileType
+	}
+	if size <= 0 || (s.opts.MaxSize > 0 && size > s.opts.MaxSize) {
+		return domain.PresignedUpload{}, ErrFileTooLarge
+	}
+
+	objectKey := s.newObjectBase() + ext
+	put, err := presigner.PresignPut(ctx, objectKey, contentType, s.opts.MaxSize, s.opts.PresignExpire)
+	if err != nil {
+		return domain.PresignedUpload{}, fmt.Errorf("failed to presign upload: %w", err)
+	}
+	_, err = s.uploadRepo.Create(ctx, domain.Upload{
+		UploaderID: uploaderID,
+		ObjectKey:  objectKey,
+		Size:       size,
+		MimeType:   contentType,
+		Pending:    true,
+	})
+	if err != nil {
+		return domain.PresignedUpload{}, fmt.Errorf("failed to record upload: %w", err)
+	}
+	return domain.PresignedUpload{
+		UploadURL: put.URL,
+		Method:    put.Method,
+		Headers:   put.Headers,
+		FileURL:   s.store.URL(objectKey),
+		MaxSize:   s.opts.MaxSize,
+		ExpireAt:  put.ExpireAt,
 	}, nil
 }
 
-// UploadFile 上传单个文件
-func (s *OSSService) UploadFile(ctx context.Context, file *multipart.FileHeader) (string, error) {
-	// 1. 打开上传的文件
-	src, err := file.Open()
+// CompleteUpload 确认直传的文件上传完成
+// 存储无法保证客户端上传的内容，这里和普通上传一样校验大小和文件头，并重新编码、生成缩略图
+// 校验失败的文件和记录会被删除，客户端需要重新申请上传地址
+func (s *OSSService) CompleteUpload(ctx context.Context, uploaderID int64, fileURL string) (domain.UploadedImage, error) {
+	objectKey, err := s.getObjectKeyFromURL(fileURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return domain.UploadedImage{}, err
+	}
+	upload, err := s.uploadRepo.FindByKey(ctx, objectKey)
+	if err != nil {
+		return domain.UploadedImage{}, err
+	}
+	if upload.UploaderID != uploaderID {
+		return domain.UploadedImage{}, ErrUploadPermissionDenied
+	}
+	if !upload.Pending {
+		return domain.UploadedImage{}, ErrUploadCompleted
+	}
+
+	// 1. 确认文件已经上传，先检查大小，避免下载超大的文件
+	info, err := s.store.Stat(ctx, objectKey)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return domain.UploadedImage{}, ErrUploadObjectMissing
+	}
+	if err != nil {
+		return domain.UploadedImage{}, err
+	}
+	if s.opts.MaxSize > 0 && info.Size > s.opts.MaxSize {
+		s.discardUpload(ctx, upload)
+		return domain.UploadedImage{}, ErrFileTooLarge
 	}
-	defer src.Close()
 
-	// 2. 校验文件类型（仅允许图片）
-	fileExt := filepath.Ext(file.Filename)
-	// 通过isValidImageExtension函数限制仅允许上传图片格式（jpg、png 等）
-	if !isValidImageExtension(fileExt) {
-		return "", errors.New("invalid file type")
+	// 2. 读取文件内容，校验文件头和申请上传时声明的类型一致
+	body, err := s.store.Get(ctx, objectKey)
+	if err != nil {
+		return domain.UploadedImage{}, err
+	}
+	data, err := s.readLimited(body)
+	body.Close()
+	if err != nil {
+		if errors.Is(err, ErrFileTooLarge) {
+			s.discardUpload(ctx, upload)
+		}
+		return domain.UploadedImage{}, err
+	}
+	original, variants, err := s.processImage(data)
+	if err == nil && original.MimeType != upload.MimeType {
+		err = ErrUnsupportedFileType
+	}
+	if errors.Is(err, ErrUnsupportedFileType) || errors.Is(err, ErrInvalidImage) {
+		s.discardUpload(ctx, upload)
+	}
+	if err != nil {
+		return domain.UploadedImage{}, err
 	}
 
-	// 3. 生成唯一文件名和存储路径（按日期组织）
-	// 使用 UUID 生成唯一文件名，避免冲突
-	// 按日期（年 / 月 / 日）组织文件，提高存储可读性
-	filename := uuid.New().String() + fileExt
-	objectKey := fmt.Sprintf("articles/%s/%s", time.Now().Format("2006/01/02"), filename)
+	// 3. 处理后的图片写到服务端生成的新对象键下，直传地址在过期之前仍然可以使用，
+	// 如果覆盖原来的对象，客户端可以重新上传未经处理的内容替换掉处理后的图片
+	result, keys, err := s.storeImage(ctx, s.newObjectBase(), original, variants)
+	if err != nil {
+		return domain.UploadedImage{}, err
+	}
 
-	// 4. 上传文件到OSS
-	err = s.bucket.PutObject(objectKey, src)
+	// 4. 更新上传记录，之后和普通上传的文件一样由文章引用或者被清理
+	err = s.uploadRepo.Complete(ctx, domain.Upload{
+		ID:        upload.ID,
+		ObjectKey: keys[0],
+		Size:      result.Size,
+		MimeType:  result.MimeType,
+		Width:     result.Width,
+		Height:    result.Height,
+		Variants:  keys[1:],
+	})
+	if err != nil {
+		// 并发确认时另一个请求已经完成，本次生成的文件没有被记录，直接删除
+		s.deleteObjects(ctx, keys)
+		if errors.Is(err, repository.ErrUploadNotFound) {
+			return domain.UploadedImage{}, ErrUploadCompleted
+		}
+		return domain.UploadedImage{}, fmt.Errorf("failed to complete upload: %w", err)
+	}
+	// 5. 删除客户端上传的原始文件，记录已经不是待确认状态，之后的直传请求会被拒绝
+	s.deleteObjects(ctx, []string{objectKey})
+	return result, nil
+}
+
+// AcceptUpload 判断直传地址是否仍然可以上传
+// 上传记录确认之后对象键已经更换，原来的对象键找不到记录，同样拒绝
+func (s *OSSService) AcceptUpload(ctx context.Context, objectKey string) error {
+	upload, err := s.uploadRepo.FindByKey(ctx, objectKey)
+	if err != nil {
+		return err
+	}
+	if !upload.Pending {
+		return ErrUploadCompleted
+	}
+	return nil
+}
+
+// readLimited 读取文件内容，超过大小限制时返回 ErrFileTooLarge
+func (s *OSSService) readLimited(r io.Reader) ([]byte, error) {
+	if s.opts.MaxSize > 0 {
+		r = io.LimitReader(r, s.opts.MaxSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if s.opts.MaxSize > 0 && int64(len(data)) > s.opts.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+	return data, nil
+}
 
-	// 5. 返回文件公共URL
-	return fmt.Sprintf("%s/%s", s.baseURL, objectKey), nil
+// processImage 按文件头识别格式，重新编码并生成缩略图，图片处理的错误转换为服务层的错误
+func (s *OSSService) processImage(data []byte) (imaging.Image, []imaging.Image, error) {
+	original, variants, err := imaging.Process(data, s.opts.MaxPixels, s.opts.VariantWidths)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return imaging.Image{}, nil, ErrUnsupportedFileType
+	case errors.Is(err, imaging.ErrInvalidImage), errors.Is(err, imaging.ErrTooManyPixels),
+		errors.Is(err, imaging.ErrTooManyFrames):
+		return imaging.Image{}, nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	case err != nil:
+		return imaging.Image{}, nil, fmt.Errorf("failed to process image: %w", err)
+	}
+	return original, variants, nil
+}
+
+// newObjectBase 生成不带扩展名的对象键，按日期组织
+func (s *OSSService) newObjectBase() string {
+	return fmt.Sprintf("articles/%s/%s", time.Now().Format("2006/01/02"), uuid.New().String())
+}
+
+// storeImage 上传原图和缩略图，缩略图在文件名后加上宽度
+// 返回的 keys 第一个是原图，任何一个上传失败都删除已经上传的文件
+func (s *OSSService) storeImage(ctx context.Context, base string, original imaging.Image,
+	variants []imaging.Image) (domain.UploadedImage, []string, error) {
+	keys := make([]string, 0, len(variants)+1)
+	put := func(key string, img imaging.Image) error {
+		if err := s.store.Put(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.MimeType); err != nil {
+			s.deleteObjects(ctx, keys)
+			return fmt.Errorf("failed to put object: %w", err)
+		}
+		keys = append(keys, key)
+		return nil
+	}
+	objectKey := base + original.Ext
+	if err := put(objectKey, original); err != nil {
+		return domain.UploadedImage{}, nil, err
+	}
+	result := domain.UploadedImage{
+		URL:      s.store.URL(objectKey),
+		Width:    original.Width,
+		Height:   original.Height,
+		MimeType: original.MimeType,
+		Size:     int64(len(original.Data)),
+		Variants: make([]domain.ImageVariant, 0, len(variants)),
+	}
+	for _, v := range variants {
+		key := fmt.Sprintf("%s_%d%s", base, v.Width, v.Ext)
+		if err := put(key, v); err != nil {
+			return domain.UploadedImage{}, nil, err
+		}
+		result.Variants = append(result.Variants, domain.ImageVariant{
+			URL:    s.store.URL(key),
+			Width:  v.Width,
+			Height: v.Height,
+		})
+	}
+	return result, keys, nil
+}
+
+// discardUpload 删除校验失败的直传文件和它的上传记录
+func (s *OSSService) discardUpload(ctx context.Context, upload domain.Upload) {
+	s.deleteObjects(ctx, []string{upload.ObjectKey})
+	if err := s.uploadRepo.Delete(ctx, upload.ID); err != nil {
+		log.Println("Failed to delete upload record:", upload.ID, err)
+	}
 }
 
 // UploadFiles 上传多个文件
-func (s *OSSService) UploadFiles(ctx context.Context, files []*multipart.FileHeader, maxFiles int) ([]string, error) {
+func (s *OSSService) UploadFiles(ctx context.Context, uploaderID int64, files []*multipart.FileHeader, maxFiles int) ([]domain.UploadedImage, error) {
 	// 校验文件数量是否超过限制
 	if len(files) > maxFiles {
-		return nil, fmt.Errorf("too many files")
+		return nil, ErrTooManyFiles
 	}
-	// 存储上传的文件URL
-	urls := make([]string, 0, len(files))
+	// 存储上传的图片
+	images := make([]domain.UploadedImage, 0, len(files))
 	for _, file := range files {
 		// 上传每个文件
-		url, err := s.UploadFile(ctx, file)
+		img, err := s.UploadFile(ctx, uploaderID, file)
 		if err != nil {
-			return nil, fmt.Errorf("failed to upload file: %w", err)
+			return nil, fmt.Errorf("failed to upload file %s: %w", file.Filename, err)
 		}
-		urls = append(urls, url)
+		images = append(images, img)
 	}
-	// 返回所有文件的公共URL
-	return urls, nil
+	return images, nil
 }
 
 // DeleteFile 根据文件URL删除文件
 func (s *OSSService) DeleteFile(ctx context.Context, fileURL string) error {
 	// 从URL中提取ObjectKey
 	objectKey, err := s.getObjectKeyFromURL(fileURL)
+	if err != nil {
+		return err
+	}
 
 	// 删除文件
-	err = s.bucket.DeleteObject(objectKey)
+	if err = s.store.Delete(ctx, objectKey); err != nil {
+		return err
+	}
+	return s.deleteRecord(ctx, objectKey)
+}
 
-	return err
+// DeleteUserFile 用户删除自己上传的文件，没有上传记录的历史文件无法确认上传者，不允许删除
+func (s *OSSService) DeleteUserFile(ctx context.Context, uid int64, fileURL string) error {
+	objectKey, err := s.getObjectKeyFromURL(fileURL)
+	if err != nil {
+		return err
+	}
+	upload, err := s.uploadRepo.FindByKey(ctx, objectKey)
+	if err != nil {
+		return err
+	}
+	if upload.UploaderID != uid {
+		return ErrUploadPermissionDenied
+	}
+	if err = s.store.Delete(ctx, objectKey); err != nil {
+		return err
+	}
+	s.deleteObjects(ctx, upload.Variants)
+	return s.uploadRepo.Delete(ctx, upload.ID)
 }
 
-// isValidImageExtension 校验文件扩展名是否为图片格式
-func isValidImageExtension(ext string) bool {
-	// 允许的图片扩展名
-	allowedExtensions := []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp"}
-	for _, allowedExt := range allowedExtensions {
-		if ext == allowedExt {
-			return true
+// BindArticleImages 记录文章引用的图片，外链图片不属于当前存储，直接忽略
+func (s *OSSService) BindArticleImages(ctx context.Context, articleID int64, urls []string) error {
+	keys := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if key, err := s.getObjectKeyFromURL(url); err == nil {
+			keys = append(keys, key)
 		}
 	}
-	return false
+	return s.uploadRepo.BindArticle(ctx, articleID, keys)
+}
 
+// CleanupOrphans 删除超过 grace 仍没有被任何文章引用的文件
+// 引用关系可能因为多篇文章共用同一张图片而不准确，删除前再按文章内容确认一次
+func (s *OSSService) CleanupOrphans(ctx context.Context, grace time.Duration, limit int) (int, error) {
+	uploads, err := s.uploadRepo.FindUnreferenced(ctx, time.Now().Add(-grace), limit)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, upload := range uploads {
+		url := s.store.URL(upload.ObjectKey)
+		inUse, err := s.articleRepo.ImgUrlInUse(ctx, url, 0)
+		if err != nil {
+			return deleted, err
+		}
+		if inUse {
+			// 仍然被其他文章引用，推迟到下一个宽限期再检查
+			if err := s.uploadRepo.Touch(ctx, upload.ID); err != nil {
+				return deleted, err
+			}
+			continue
+		}
+		if err := s.store.Delete(ctx, upload.ObjectKey); err != nil {
+			log.Println("Failed to delete orphan object:", upload.ObjectKey, err)
+			continue
+		}
+		s.deleteObjects(ctx, upload.Variants)
+		if err := s.uploadRepo.Delete(ctx, upload.ID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
 }
 
-// getObjectKeyFromURL 从文件URL中提取ObjectKey
-// 只处理属于当前 Bucket 的 URL，防止越权删除
-func (s *OSSService) getObjectKeyFromURL(fileURL string) (string, error) {
-	if !strings.HasPrefix(fileURL, s.baseURL) {
-		return "", errors.New("URL does not belong to this OSS bucket")
+// deleteRecord 删除文件的上传记录，历史文件没有上传记录
+func (s *OSSService) deleteRecord(ctx context.Context, objectKey string) error {
+	upload, err := s.uploadRepo.FindByKey(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrUploadNotFound) {
+			return nil
+		}
+		return err
 	}
+	s.deleteObjects(ctx, upload.Variants)
+	return s.uploadRepo.Delete(ctx, upload.ID)
+}
 
-	objectKey := strings.TrimPrefix(fileURL, s.baseURL+"/")
-	if objectKey == "" {
-		return "", errors.New("invalid file URL")
+// deleteObjects 尽力删除一组文件，失败只记录日志，残留的文件不影响使用
+func (s *OSSService) deleteObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Println("Failed to delete object:", key, err)
+		}
 	}
+}
 
+// getObjectKeyFromURL 从文件URL中提取ObjectKey
+// 只处理属于当前存储的 URL，防止越权删除
+func (s *OSSService) getObjectKeyFromURL(fileURL string) (string, error) {
+	objectKey, err := s.store.Key(fileURL)
+	if err != nil || objectKey == "" {
+		return "", ErrInvalidFileURL
+	}
 	return objectKey, nil
-
 }
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+	"github.com/Fairy-nn/inspora/internal/service/storage/local"
+	"github.com/Fairy-nn/inspora/internal/service/storage/memory"
+)
+
+// fakeUploadRepo 内存中的上传记录，用于离线测试
+type fakeUploadRepo struct {
+	mu      sync.Mutex
+	nextID  int64
+	uploads map[int64]domain.Upload
+}
+
+func newFakeUploadRepo() *fakeUploadRepo {
+	return &fakeUploadRepo{uploads: make(map[int64]domain.Upload)}
+}
+
+func (r *fakeUploadRepo) Create(ctx context.Context, u domain.Upload) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	u.ID = r.nextID
+	r.uploads[u.ID] = u
+	return u.ID, nil
+}
+
+func (r *fakeUploadRepo) FindByKey(ctx context.Context, key string) (domain.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.uploads {
+		if u.ObjectKey == key {
+			return u, nil
+		}
+	}
+	return domain.Upload{}, repository.ErrUploadNotFound
+}
+
+func (r *fakeUploadRepo) BindArticle(ctx context.Context, articleID int64, keys []string) error {
+	return nil
+}
+
+func (r *fakeUploadRepo) FindUnreferenced(ctx context.Context, before time.Time, limit int) ([]domain.Upload, error) {
+	return nil, nil
+}
+
+func (r *fakeUploadRepo) Complete(ctx context.Context, u domain.Upload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.uploads[u.ID]
+	if !ok || !old.Pending {
+		return repository.ErrUploadNotFound
+	}
+	old.ObjectKey = u.ObjectKey
+	old.Size, old.MimeType, old.Width, old.Height = u.Size, u.MimeType, u.Width, u.Height
+	old.Variants = u.Variants
+	old.Pending = false
+	r.uploads[u.ID] = old
+	return nil
+}
+
+func (r *fakeUploadRepo) Touch(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (r *fakeUploadRepo) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.uploads, id)
+	return nil
+}
+
+func (r *fakeUploadRepo) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.uploads)
+}
+
+// testPNG 生成一张纯色的 PNG 图片
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// fileHeader 把数据包装成表单上传的文件
+func fileHeader(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	fw.Write(data)
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("parse multipart form: %v", err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
+
+func testUploadOptions() UploadOptions {
+	return UploadOptions{
+		MaxSize:       64 * 1024,
+		MaxPixels:     1_000_000,
+		VariantWidths: []int{20},
+		PresignExpire: time.Minute,
+	}
+}
+
+func TestOSSServiceUploadFile(t *testing.T) {
+	testCases := []struct {
+		name         string
+		data         []byte
+		wantErr      error
+		wantObjects  int // 存储中的对象数量，原图加缩略图
+		wantVariants int
+	}{
+		{
+			name:         "上传图片并生成缩略图",
+			data:         testPNG(t, 40, 30),
+			wantObjects:  2,
+			wantVariants: 1,
+		},
+		{
+			name:        "比缩略图还小的图片不生成缩略图",
+			data:        testPNG(t, 10, 10),
+			wantObjects: 1,
+		},
+		{
+			name:    "不是图片",
+			data:    []byte("<html><script>alert(1)</script></html>"),
+			wantErr: ErrUnsupportedFileType,
+		},
+		{
+			name:    "超过大小限制",
+			data:    bytes.Repeat([]byte{0x89}, 64*1024+1),
+			wantErr: ErrFileTooLarge,
+		},
+		{
+			name:    "像素超过限制",
+			data:    testPNG(t, 1001, 1000),
+			wantErr: ErrInvalidImage,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := memory.NewStorage("memory://uploads")
+			repo := newFakeUploadRepo()
+			svc := NewOSSService(store, repo, nil, testUploadOptions())
+
+			img, err := svc.UploadFile(context.Background(), 1, fileHeader(t, "a.png", tc.data))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("UploadFile error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				if repo.count() != 0 {
+					t.Errorf("failed upload should not be recorded")
+				}
+				return
+			}
+			if len(img.Variants) != tc.wantVariants {
+				t.Errorf("variants = %d, want %d", len(img.Variants), tc.wantVariants)
+			}
+			key, err := store.Key(img.URL)
+			if err != nil {
+				t.Fatalf("Key(%q) error = %v", img.URL, err)
+			}
+			upload, err := repo.FindByKey(context.Background(), key)
+			if err != nil {
+				t.Fatalf("upload should be recorded: %v", err)
+			}
+			if upload.UploaderID != 1 || upload.Pending || len(upload.Variants)+1 != tc.wantObjects {
+				t.Errorf("upload record = %+v", upload)
+			}
+			if _, err := store.Stat(context.Background(), key); err != nil {
+				t.Errorf("original should be stored: %v", err)
+			}
+		})
+	}
+}
+
+func TestOSSServicePresignUpload(t *testing.T) {
+	testCases := []struct {
+		name        string
+		store       func(t *testing.T) storage.Storage
+		contentType string
+		size        int64
+		wantErr     error
+	}{
+		{
+			name: "内存存储不支持直传",
+			store: func(t *testing.T) storage.Storage {
+				return memory.NewStorage("memory://uploads")
+			},
+			contentType: "image/png",
+			size:        100,
+			wantErr:     ErrPresignNotSupported,
+		},
+		{
+			name:        "不支持的类型",
+			store:       newLocalStorage,
+			contentType: "text/html",
+			size:        100,
+			wantErr:     ErrUnsupportedFileType,
+		},
+		{
+			name:        "超过大小限制",
+			store:       newLocalStorage,
+			contentType: "image/png",
+			size:        64*1024 + 1,
+			wantErr:     ErrFileTooLarge,
+		},
+		{
+			name:        "没有声明大小",
+			store:       newLocalStorage,
+			contentType: "image/png",
+			wantErr:     ErrFileTooLarge,
+		},
+		{
+			name:        "签发成功",
+			store:       newLocalStorage,
+			contentType: "image/png",
+			size:        100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakeUploadRepo()
+			svc := NewOSSService(tc.store(t), repo, nil, testUploadOptions())
+			presigned, err := svc.PresignUpload(context.Background(), 1, tc.contentType, tc.size)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("PresignUpload error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if presigned.Method != http.MethodPut || presigned.UploadURL == "" || presigned.FileURL == "" {
+				t.Errorf("presigned = %+v", presigned)
+			}
+			if repo.count() != 1 {
+				t.Errorf("pending upload should be recorded")
+			}
+		})
+	}
+}
+
+func newLocalStorage(t *testing.T) storage.Storage {
+	s, err := local.NewStorage(t.TempDir(), "http://localhost:8080", []byte("secret"))
+	if err != nil {
+		t.Fatalf("NewStorage error = %v", err)
+	}
+	return s
+}
+
+// putPresigned 按照签发的地址把数据上传到本地存储，和线上一样只有待确认的上传可以写入
+func putPresigned(t *testing.T, svc OSSServiceInterface, store *local.Storage, presigned domain.PresignedUpload, data []byte) int {
+	t.Helper()
+	u, err := url.Parse(presigned.UploadURL)
+	if err != nil {
+		t.Fatalf("parse upload url: %v", err)
+	}
+	req := httptest.NewRequest(presigned.Method, u.RequestURI(), bytes.NewReader(data))
+	for k, v := range presigned.Headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	http.StripPrefix(local.PathPrefix, store.UploadHandler(svc.AcceptUpload)).ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestOSSServiceCompleteUpload(t *testing.T) {
+	testCases := []struct {
+		name string
+		// upload 为空时不上传文件
+		upload     []byte
+		uploaderID int64
+		wantErr    error
+		// wantRecord 确认之后上传记录是否还存在
+		wantRecord bool
+	}{
+		{
+			name:       "确认成功",
+			upload:     testPNG(t, 40, 30),
+			uploaderID: 1,
+			wantRecord: true,
+		},
+		{
+			name:       "不是上传者",
+			upload:     testPNG(t, 40, 30),
+			uploaderID: 2,
+			wantErr:    ErrUploadPermissionDenied,
+			wantRecord: true,
+		},
+		{
+			name:       "还没有上传",
+			uploaderID: 1,
+			wantErr:    ErrUploadObjectMissing,
+			wantRecord: true,
+		},
+		{
+			name:       "内容不是图片",
+			upload:     []byte("<svg onload=alert(1)>"),
+			uploaderID: 1,
+			wantErr:    ErrUnsupportedFileType,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newLocalStorage(t).(*local.Storage)
+			repo := newFakeUploadRepo()
+			svc := NewOSSService(store, repo, nil, testUploadOptions())
+
+			presigned, err := svc.PresignUpload(ctx, 1, "image/png", 100)
+			if err != nil {
+				t.Fatalf("PresignUpload error = %v", err)
+			}
+			if tc.upload != nil {
+				if code := putPresigned(t, svc, store, presigned, tc.upload); code != http.StatusOK {
+					t.Fatalf("upload status = %d", code)
+				}
+			}
+
+			img, err := svc.CompleteUpload(ctx, tc.uploaderID, presigned.FileURL)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("CompleteUpload error = %v, want %v", err, tc.wantErr)
+			}
+			if got := repo.count() == 1; got != tc.wantRecord {
+				t.Fatalf("record exists = %v, want %v", got, tc.wantRecord)
+			}
+			if err != nil {
+				return
+			}
+			if img.MimeType != "image/png" || img.Width != 40 || len(img.Variants) != 1 {
+				t.Errorf("image = %+v", img)
+			}
+			// 处理后的图片放在新的对象键下，客户端上传的原始文件被删除
+			if img.URL == presigned.FileURL {
+				t.Fatalf("processed image should not reuse the presigned key")
+			}
+			rawKey, _ := store.Key(presigned.FileURL)
+			if _, err := store.Stat(ctx, rawKey); !errors.Is(err, storage.ErrObjectNotFound) {
+				t.Errorf("raw upload should be deleted, Stat error = %v", err)
+			}
+			newKey, _ := store.Key(img.URL)
+			if upload, err := repo.FindByKey(ctx, newKey); err != nil || upload.Pending {
+				t.Errorf("upload record = %+v, %v", upload, err)
+			}
+			// 签名地址还没有过期，但是确认之后不能再上传
+			if code := putPresigned(t, svc, store, presigned, testPNG(t, 40, 30)); code != http.StatusForbidden {
+				t.Errorf("upload after completion status = %d, want %d", code, http.StatusForbidden)
+			}
+			if _, err := store.Stat(ctx, rawKey); !errors.Is(err, storage.ErrObjectNotFound) {
+				t.Errorf("upload after completion should not be stored, Stat error = %v", err)
+			}
+			// 重复确认
+			if _, err := svc.CompleteUpload(ctx, 1, presigned.FileURL); !errors.Is(err, repository.ErrUploadNotFound) {
+				t.Errorf("second CompleteUpload error = %v, want %v", err, repository.ErrUploadNotFound)
+			}
+		})
+	}
+}
@@ -69,7 +69,8 @@ func (b *BatchRankService) TopN(ctx context.Context) error {
 func (b *BatchRankService) topN(ctx context.Context) ([]domain.Article, error) {
 	// 只拿前7天的文章
 	now := time.Now()
-	offset := 0
+	// 从当前时间开始按 (utime, id) 游标向前翻页
+	cursor := domain.ArticleCursor{Utime: now}
 	type Score struct {
 		art   domain.Article
 		score float64
@@ -95,13 +96,13 @@ func (b *BatchRankService) topN(ctx context.Context) ([]domain.Article, error) {
 
 	// 分页获取文章及交互信息
 	for {
-		articles, err := b.artSvc.ListPublic(ctx, now, offset, b.batchsize)
+		articles, err := b.artSvc.ListPublic(ctx, cursor, b.batchsize)
 		if err != nil {
 			//println("【排行榜计算】获取文章列表失败:", err.Error())
 			return nil, err
 		}
 
-		println("【排行榜计算】获取到文章", len(articles), "篇，偏移量:", offset)
+		println("【排行榜计算】获取到文章", len(articles), "篇")
 
 		if len(articles) == 0 {
 			//println("【排行榜计算】没有更多文章了")
@@ -161,7 +162,7 @@ func (b *BatchRankService) topN(ctx context.Context) ([]domain.Article, error) {
 			break
 		}
 
-		offset += b.batchsize
+		cursor = domain.NextArticleCursor(articles, b.batchsize)
 	}
 
 	println("【排行榜计算】总共处理文章:", totalArticles, "篇，有交互的文章:", articlesWithInteractions, "篇")
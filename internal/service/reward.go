@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/events/notification"
 	"github.com/Fairy-nn/inspora/internal/repository"
 )
 
@@ -21,17 +23,23 @@ type RewardServiceInterface interface {
 }
 
 type WechatNativeRewardService struct {
-	svc     NativePaymentService // 支付服务
-	repo    repository.RewardRepositoryInterface
-	userSvc UserServiceInterface // 用户服务
+	svc        NativePaymentService // 支付服务
+	repo       repository.RewardRepositoryInterface
+	userSvc    UserServiceInterface  // 用户服务
+	notifyProd notification.Producer // 通知被打赏的作者
+	blockSvc   BlockServiceInterface // 双方之间存在拉黑关系时不能打赏
 }
 
-func NewWechatNativeRewardService(svc NativePaymentService, repo repository.RewardRepositoryInterface, userSvc UserServiceInterface) RewardServiceInterface {
-	return &WechatNativeRewardService{svc: svc, repo: repo, userSvc: userSvc}
+func NewWechatNativeRewardService(svc NativePaymentService, repo repository.RewardRepositoryInterface, userSvc UserServiceInterface,
+	notifyProd notification.Producer, blockSvc BlockServiceInterface) RewardServiceInterface {
+	return &WechatNativeRewardService{svc: svc, repo: repo, userSvc: userSvc, notifyProd: notifyProd, blockSvc: blockSvc}
 }
 
 // PreReward 预打赏，生成二维码
 func (w *WechatNativeRewardService) PreReward(ctx context.Context, r domain.Reward) (domain.CodeURL, error) {
+	if err := w.blockSvc.CheckInteraction(ctx, r.UserID, r.Target.UserID); err != nil {
+		return domain.CodeURL{}, err
+	}
 	// 如果在缓存中查到，则直接返回
 	code, err := w.repo.GetCachedCodeURL(ctx, r)
 	if err == nil {
@@ -139,11 +147,35 @@ func (w *WechatNativeRewardService) UpdateReward(ctx context.Context, bizTradeNo
 			// 这里可以记录日志，但不影响流程
 			fmt.Println("update user balance failed:", err)
 		}
+		w.notifyRewarded(ctx, reward)
 	}
 
 	return nil
 }
 
+// notifyRewarded 通知作者收到了打赏，失败只记录日志
+func (w *WechatNativeRewardService) notifyRewarded(ctx context.Context, reward domain.Reward) {
+	if w.notifyProd == nil {
+		return
+	}
+	event := notification.Event{
+		Type:    notification.TypeReward,
+		UserID:  reward.Target.UserID,
+		ActorID: reward.UserID,
+		Biz:     reward.Target.Biz,
+		BizID:   reward.Target.BizId,
+		Content: reward.Target.BizName,
+		Amount:  reward.Amt,
+		Ctime:   time.Now().UnixMilli(),
+	}
+	if reward.Target.Biz == "article" {
+		event.ArticleID = reward.Target.BizId
+	}
+	if err := w.notifyProd.ProduceEvent(ctx, event); err != nil {
+		fmt.Println("produce reward notification failed:", err)
+	}
+}
+
 // toRid 将 bizTradeNo 转换为 rid
 func (w *WechatNativeRewardService) toRid(bizTradeNo string) int64 {
 	ridStr := strings.Split(bizTradeNo, "-")
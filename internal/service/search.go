@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Fairy-nn/inspora/internal/domain"
 	"github.com/Fairy-nn/inspora/pkg/elasticsearch"
@@ -9,12 +10,12 @@ import (
 
 // SearchService 搜索服务接口
 type SearchService interface {
-	// SearchUsers 搜索用户
-	SearchUsers(ctx context.Context, query string, page, pageSize int) ([]domain.User, int64, error)
-	// SearchArticles 搜索文章
-	SearchArticles(ctx context.Context, query string, page, pageSize int) ([]elasticsearch.ArticleSearchResult, int64, error)
-	// SearchArticlesByAuthor 按作者搜索文章
-	SearchArticlesByAuthor(ctx context.Context, query string, authorID int64, page, pageSize int) ([]elasticsearch.ArticleSearchResult, int64, error)
+	// SearchUsers 搜索用户，uid 是当前用户，结果中不包含 uid 拉黑或屏蔽的人
+	SearchUsers(ctx context.Context, query string, page, pageSize int, uid int64) ([]domain.User, int64, error)
+	// SearchArticles 搜索文章，tag 不为空时只返回带有该标签的文章，结果中不包含 uid 拉黑或屏蔽的人的文章
+	SearchArticles(ctx context.Context, query, tag string, page, pageSize int, uid int64) ([]elasticsearch.ArticleSearchResult, int64, error)
+	// SearchArticlesByAuthor 按作者搜索文章，作者被 uid 拉黑或屏蔽时没有结果
+	SearchArticlesByAuthor(ctx context.Context, query string, authorID int64, page, pageSize int, uid int64) ([]elasticsearch.ArticleSearchResult, int64, error)
 	// IndexUser 索引用户
 	IndexUser(ctx context.Context, user domain.User) error
 	// IndexArticle 索引文章
@@ -28,21 +29,24 @@ type SearchService interface {
 type searchService struct {
 	userSearchService    *elasticsearch.UserSearchService
 	articleSearchService *elasticsearch.ArticleSearchService
+	blockSvc             BlockServiceInterface
 }
 
 // NewSearchService 创建搜索服务
 func NewSearchService(
 	userSearchService *elasticsearch.UserSearchService,
 	articleSearchService *elasticsearch.ArticleSearchService,
+	blockSvc BlockServiceInterface,
 ) SearchService {
 	return &searchService{
 		userSearchService:    userSearchService,
 		articleSearchService: articleSearchService,
+		blockSvc:             blockSvc,
 	}
 }
 
 // SearchUsers 搜索用户
-func (s *searchService) SearchUsers(ctx context.Context, query string, page, pageSize int) ([]domain.User, int64, error) {
+func (s *searchService) SearchUsers(ctx context.Context, query string, page, pageSize int, uid int64) ([]domain.User, int64, error) {
 	// 计算当前页的起始位置（用于数据库查询的offset）
 	from := (page - 1) * pageSize
 	// 调用用户搜索服务的Search方法进行搜索
@@ -51,22 +55,56 @@ func (s *searchService) SearchUsers(ctx context.Context, query string, page, pag
 		return nil, 0, err
 	}
 	// 处理搜索结果
-	return s.userSearchService.ProcessSearchResult(result)
+	users, total, err := s.userSearchService.ProcessSearchResult(result)
+	if err != nil {
+		return nil, 0, err
+	}
+	hidden := s.hiddenUsers(ctx, uid)
+	if len(hidden) == 0 {
+		return users, total, nil
+	}
+	res := users[:0]
+	for _, u := range users {
+		if !hidden[u.ID] {
+			res = append(res, u)
+		}
+	}
+	return res, total, nil
 }
 
-// SearchArticles 搜索文章
-func (s *searchService) SearchArticles(ctx context.Context, query string, page, pageSize int) ([]elasticsearch.ArticleSearchResult, int64, error) {
+// SearchArticles 搜索文章，tag 不为空时只返回带有该标签的文章
+func (s *searchService) SearchArticles(ctx context.Context, query, tag string, page, pageSize int, uid int64) ([]elasticsearch.ArticleSearchResult, int64, error) {
 	from := (page - 1) * pageSize
+	// 索引中的标签是标准化之后的，查询时也要标准化
+	if tag = domain.NormalizeTag(tag); tag != "" {
+		result, err := s.articleSearchService.SearchByTag(ctx, query, tag, from, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		articles, total, err := s.articleSearchService.ProcessSearchResult(result)
+		if err != nil {
+			return nil, 0, err
+		}
+		return s.filterArticles(ctx, articles, uid), total, nil
+	}
+
 	result, err := s.articleSearchService.Search(ctx, query, from, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return s.articleSearchService.ProcessSearchResult(result)
+	articles, total, err := s.articleSearchService.ProcessSearchResult(result)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.filterArticles(ctx, articles, uid), total, nil
 }
 
 // SearchArticlesByAuthor 按作者搜索文章
-func (s *searchService) SearchArticlesByAuthor(ctx context.Context, query string, authorID int64, page, pageSize int) ([]elasticsearch.ArticleSearchResult, int64, error) {
+func (s *searchService) SearchArticlesByAuthor(ctx context.Context, query string, authorID int64, page, pageSize int, uid int64) ([]elasticsearch.ArticleSearchResult, int64, error) {
+	if s.hiddenUsers(ctx, uid)[authorID] {
+		return []elasticsearch.ArticleSearchResult{}, 0, nil
+	}
 	from := (page - 1) * pageSize
 	result, err := s.articleSearchService.SearchByAuthor(ctx, query, authorID, from, pageSize)
 	if err != nil {
@@ -81,8 +119,15 @@ func (s *searchService) IndexUser(ctx context.Context, user domain.User) error {
 	return s.userSearchService.IndexUser(ctx, user)
 }
 
-// IndexArticle 索引文章
+// IndexArticle 索引文章，索引的是纯文本，避免 Markdown/HTML 标记影响搜索和摘要
+// 只有已发布的文章可以被搜索到，私密、撤回等其他状态的文章从索引中删除
 func (s *searchService) IndexArticle(ctx context.Context, article domain.Article) error {
+	if article.Status != domain.ArticleStatusPublished {
+		return s.articleSearchService.DeleteArticle(ctx, article.ID)
+	}
+	article.Content = article.PlainText()
+	article.Format = domain.ContentFormatPlain
+	article.HTML = ""
 	return s.articleSearchService.IndexArticle(ctx, article)
 }
 
@@ -95,3 +140,29 @@ func (s *searchService) DeleteUserIndex(ctx context.Context, userID int64) error
 func (s *searchService) DeleteArticleIndex(ctx context.Context, articleID int64) error {
 	return s.articleSearchService.DeleteArticle(ctx, articleID)
 }
+
+// hiddenUsers 获取用户拉黑和屏蔽的人，获取失败时不过滤搜索结果
+func (s *searchService) hiddenUsers(ctx context.Context, uid int64) map[int64]bool {
+	hidden, err := s.blockSvc.HiddenUsers(ctx, uid)
+	if err != nil {
+		fmt.Printf("获取拉黑和屏蔽的用户失败: %v\n", err)
+		return nil
+	}
+	return hidden
+}
+
+// filterArticles 去掉搜索结果中用户拉黑或屏蔽的人的文章
+// 过滤在分页之后进行，总数仍然是搜索引擎返回的命中数
+func (s *searchService) filterArticles(ctx context.Context, articles []elasticsearch.ArticleSearchResult, uid int64) []elasticsearch.ArticleSearchResult {
+	hidden := s.hiddenUsers(ctx, uid)
+	if len(hidden) == 0 {
+		return articles
+	}
+	res := articles[:0]
+	for _, a := range articles {
+		if !hidden[a.Author.ID] {
+			res = append(res, a)
+		}
+	}
+	return res
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+var (
+	ErrInvalidSeries          = errors.New("invalid series")
+	ErrSeriesNotFound         = errors.New("series not found")
+	ErrSeriesPermissionDenied = errors.New("series permission denied")
+)
+
+// SeriesServiceInterface 文章系列服务接口
+type SeriesServiceInterface interface {
+	// Create 创建系列
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	// ListByAuthor 获取作者的所有系列
+	ListByAuthor(ctx context.Context, authorID int64) ([]domain.Series, error)
+	// AddArticle 作者将自己的文章添加到系列末尾
+	AddArticle(ctx context.Context, uid, seriesID, articleID int64) error
+	// RemoveArticle 作者从系列中移除文章
+	RemoveArticle(ctx context.Context, uid, seriesID, articleID int64) error
+	// Reorder 作者重新排列系列中的文章
+	Reorder(ctx context.Context, uid, seriesID int64, articleIDs []int64) error
+	// GetPublic 获取系列和其中按顺序排列的已发布文章
+	GetPublic(ctx context.Context, seriesID int64) (domain.Series, []domain.Article, error)
+}
+
+type SeriesService struct {
+	repo        repository.SeriesRepository
+	articleRepo repository.ArticleRepository
+}
+
+func NewSeriesService(repo repository.SeriesRepository, articleRepo repository.ArticleRepository) SeriesServiceInterface {
+	return &SeriesService{
+		repo:        repo,
+		articleRepo: articleRepo,
+	}
+}
+
+// Create 创建系列
+func (s *SeriesService) Create(ctx context.Context, series domain.Series) (int64, error) {
+	series.Title = strings.TrimSpace(series.Title)
+	if series.Title == "" || series.AuthorID <= 0 {
+		return 0, ErrInvalidSeries
+	}
+	return s.repo.Create(ctx, series)
+}
+
+// ListByAuthor 获取作者的所有系列
+func (s *SeriesService) ListByAuthor(ctx context.Context, authorID int64) ([]domain.Series, error) {
+	return s.repo.FindByAuthor(ctx, authorID)
+}
+
+// AddArticle 作者将自己的文章添加到系列末尾
+func (s *SeriesService) AddArticle(ctx context.Context, uid, seriesID, articleID int64) error {
+	if err := s.checkOwner(ctx, uid, seriesID); err != nil {
+		return err
+	}
+	// 只能添加自己的文章
+	if _, err := s.articleRepo.FindById(ctx, articleID, uid); err != nil {
+		return ErrSeriesPermissionDenied
+	}
+	return s.repo.AddArticle(ctx, seriesID, articleID)
+}
+
+// RemoveArticle 作者从系列中移除文章
+func (s *SeriesService) RemoveArticle(ctx context.Context, uid, seriesID, articleID int64) error {
+	if err := s.checkOwner(ctx, uid, seriesID); err != nil {
+		return err
+	}
+	return s.repo.RemoveArticle(ctx, seriesID, articleID)
+}
+
+// Reorder 作者重新排列系列中的文章
+func (s *SeriesService) Reorder(ctx context.Context, uid, seriesID int64, articleIDs []int64) error {
+	if err := s.checkOwner(ctx, uid, seriesID); err != nil {
+		return err
+	}
+	return s.repo.Reorder(ctx, seriesID, articleIDs)
+}
+
+// GetPublic 获取系列和其中按顺序排列的已发布文章
+func (s *SeriesService) GetPublic(ctx context.Context, seriesID int64) (domain.Series, []domain.Article, error) {
+	series, err := s.repo.FindByID(ctx, seriesID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSeriesNotFound) {
+			return domain.Series{}, nil, ErrSeriesNotFound
+		}
+		return domain.Series{}, nil, err
+	}
+
+	published, err := s.articleRepo.FindPublicByIds(ctx, series.ArticleIDs)
+	if err != nil {
+		return domain.Series{}, nil, err
+	}
+
+	// 按系列中的顺序排列，跳过未发布的文章
+	byID := make(map[int64]domain.Article, len(published))
+	for _, a := range published {
+		byID[a.ID] = a
+	}
+	articles := make([]domain.Article, 0, len(published))
+	for _, id := range series.ArticleIDs {
+		if a, ok := byID[id]; ok {
+			articles = append(articles, a)
+		}
+	}
+	return series, articles, nil
+}
+
+// checkOwner 检查系列是否属于当前用户
+func (s *SeriesService) checkOwner(ctx context.Context, uid, seriesID int64) error {
+	series, err := s.repo.FindByID(ctx, seriesID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSeriesNotFound) {
+			return ErrSeriesNotFound
+		}
+		return err
+	}
+	if series.AuthorID != uid {
+		return ErrSeriesPermissionDenied
+	}
+	return nil
+}
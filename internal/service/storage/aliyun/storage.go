@@ -0,0 +1,91 @@
+package aliyun
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Storage 阿里云 OSS 存储
+type Storage struct {
+	bucket  *oss.Bucket
+	baseURL string // 文件访问的基础URL
+}
+
+func NewStorage(bucket *oss.Bucket, baseURL string) *Storage {
+	return &Storage{
+		bucket:  bucket,
+		baseURL: baseURL,
+	}
+}
+
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	var opts []oss.Option
+	if contentType != "" {
+		opts = append(opts, oss.ContentType(contentType))
+	}
+	return s.bucket.PutObject(key, r, opts...)
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	body, err := s.bucket.GetObject(key)
+	var srvErr oss.ServiceError
+	if errors.As(err, &srvErr) && srvErr.StatusCode == http.StatusNotFound {
+		return nil, storage.ErrObjectNotFound
+	}
+	return body, err
+}
+
+func (s *Storage) Stat(ctx context.Context, key string) (storage.ObjectInfo, error) {
+	header, err := s.bucket.GetObjectDetailedMeta(key)
+	if err != nil {
+		var srvErr oss.ServiceError
+		if errors.As(err, &srvErr) && srvErr.StatusCode == http.StatusNotFound {
+			return storage.ObjectInfo{}, storage.ErrObjectNotFound
+		}
+		return storage.ObjectInfo{}, err
+	}
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	return storage.ObjectInfo{
+		Size:        size,
+		ContentType: header.Get("Content-Type"),
+	}, nil
+}
+
+// PresignPut 签名中包含 Content-Type，客户端上传时必须带上相同的头
+// OSS 的预签名地址无法限制文件大小，由调用方在上传完成后检查
+func (s *Storage) PresignPut(ctx context.Context, key, contentType string, maxSize int64, expire time.Duration) (storage.PresignedPut, error) {
+	expireAt := time.Now().Add(expire)
+	url, err := s.bucket.SignURL(key, oss.HTTPPut, int64(expire/time.Second), oss.ContentType(contentType))
+	if err != nil {
+		return storage.PresignedPut{}, err
+	}
+	return storage.PresignedPut{
+		Method:   http.MethodPut,
+		URL:      url,
+		Headers:  map[string]string{"Content-Type": contentType},
+		ExpireAt: expireAt,
+	}, nil
+}
+
+// Delete OSS 删除不存在的对象不会返回错误
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	return s.bucket.DeleteObject(key)
+}
+
+func (s *Storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *Storage) Key(url string) (string, error) {
+	return storage.KeyFromURL(s.baseURL, url)
+}
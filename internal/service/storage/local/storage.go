@@ -0,0 +1,221 @@
+package local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+)
+
+// PathPrefix 本地文件通过 Gin 的静态路由对外提供访问的路径前缀
+const PathPrefix = "/uploads"
+
+// UploadGuard 写入直传的文件之前再次确认对象键是否允许上传，返回错误时拒绝
+// 签名在过期之前一直有效，上传完成之后需要由调用方拒绝重复的上传
+type UploadGuard func(ctx context.Context, key string) error
+
+// Storage 本地文件系统存储，用于开发和没有对象存储的部署环境
+type Storage struct {
+	root    string // 文件存放的根目录
+	baseURL string // 文件访问的基础URL，即服务地址加上 PathPrefix
+	secret  []byte // 预签名上传地址使用的密钥
+}
+
+// NewStorage origin 为服务对外的地址，例如 http://localhost:8080
+// secret 用于签名直传地址，为空时不支持预签名上传
+func NewStorage(root, origin string, secret []byte) (*Storage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Storage{
+		root:    root,
+		baseURL: strings.TrimSuffix(origin, "/") + PathPrefix,
+		secret:  secret,
+	}, nil
+}
+
+// Root 文件存放的根目录
+func (s *Storage) Root() string {
+	return s.root
+}
+
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, storage.ErrObjectNotFound
+	}
+	return f, err
+}
+
+func (s *Storage) Stat(ctx context.Context, key string) (storage.ObjectInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	info, err := os.Stat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return storage.ObjectInfo{}, storage.ErrObjectNotFound
+	}
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	// 文件系统不保存 Content-Type，按扩展名推断
+	return storage.ObjectInfo{
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(p)),
+	}, nil
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *Storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *Storage) Key(url string) (string, error) {
+	return storage.KeyFromURL(s.baseURL, url)
+}
+
+// PresignPut 生成指向本服务 PUT {PathPrefix}/{key} 的签名地址，由 UploadHandler 校验后写入
+func (s *Storage) PresignPut(ctx context.Context, key, contentType string, maxSize int64, expire time.Duration) (storage.PresignedPut, error) {
+	if len(s.secret) == 0 {
+		return storage.PresignedPut{}, errors.New("local storage presign secret is not configured")
+	}
+	if _, err := s.path(key); err != nil {
+		return storage.PresignedPut{}, err
+	}
+	expireAt := time.Now().Add(expire)
+	expires := strconv.FormatInt(expireAt.Unix(), 10)
+	size := strconv.FormatInt(maxSize, 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("max_size", size)
+	query.Set("signature", s.sign(key, contentType, size, expires))
+	return storage.PresignedPut{
+		Method:   http.MethodPut,
+		URL:      s.URL(key) + "?" + query.Encode(),
+		Headers:  map[string]string{"Content-Type": contentType},
+		ExpireAt: expireAt,
+	}, nil
+}
+
+// UploadHandler 处理预签名地址的上传请求，需要挂载在 PathPrefix 下并去掉前缀
+// 签名覆盖对象键、Content-Type、大小上限和过期时间，任何一个被修改都会校验失败；
+// guard 不为空时，签名校验通过之后还需要 guard 允许才会写入
+func (s *Storage) UploadHandler(guard UploadGuard) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		query := r.URL.Query()
+		expires, size := query.Get("expires"), query.Get("max_size")
+		contentType := r.Header.Get("Content-Type")
+		expected := s.sign(key, contentType, size, expires)
+		if len(s.secret) == 0 || !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+		expireAt, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > expireAt {
+			http.Error(w, "upload url expired", http.StatusForbidden)
+			return
+		}
+		maxSize, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+		if r.ContentLength > maxSize {
+			http.Error(w, "file too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if guard != nil {
+			if err := guard(r.Context(), key); err != nil {
+				http.Error(w, "upload is no longer accepted", http.StatusForbidden)
+				return
+			}
+		}
+		body := http.MaxBytesReader(w, r.Body, maxSize)
+		err = s.Put(r.Context(), key, body, r.ContentLength, contentType)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "file too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, storage.ErrInvalidKey):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			http.Error(w, "failed to store file", http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+func (s *Storage) sign(key, contentType, maxSize, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + contentType + "\n" + maxSize + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path 将对象键转换为根目录下的文件路径，不允许访问根目录之外的文件
+func (s *Storage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean != "/"+key {
+		return "", storage.ErrInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,251 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+)
+
+func newTestStorage(t *testing.T, secret string) *Storage {
+	t.Helper()
+	s, err := NewStorage(t.TempDir(), "http://localhost:8080/", []byte(secret))
+	if err != nil {
+		t.Fatalf("NewStorage error = %v", err)
+	}
+	return s
+}
+
+func TestStoragePath(t *testing.T) {
+	s := newTestStorage(t, "secret")
+	testCases := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{name: "正常的对象键", key: "articles/2024/01/02/a.png"},
+		{name: "空对象键", key: "", wantErr: storage.ErrInvalidKey},
+		{name: "上级目录", key: "../etc/passwd", wantErr: storage.ErrInvalidKey},
+		{name: "中间的上级目录", key: "articles/../../etc/passwd", wantErr: storage.ErrInvalidKey},
+		{name: "绝对路径", key: "/etc/passwd", wantErr: storage.ErrInvalidKey},
+		{name: "多余的斜杠", key: "articles//a.png", wantErr: storage.ErrInvalidKey},
+		{name: "当前目录", key: "./a.png", wantErr: storage.ErrInvalidKey},
+		{name: "结尾的斜杠", key: "articles/", wantErr: storage.ErrInvalidKey},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := s.path(tc.key)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("path(%q) error = %v, want %v", tc.key, err, tc.wantErr)
+			}
+			if err == nil && !strings.HasPrefix(p, s.Root()) {
+				t.Errorf("path(%q) = %q, should be under %q", tc.key, p, s.Root())
+			}
+		})
+	}
+}
+
+func TestStorageObjects(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, "secret")
+
+	if err := s.Put(ctx, "a/b.png", strings.NewReader("hello"), 5, "image/png"); err != nil {
+		t.Fatalf("Put error = %v", err)
+	}
+	info, err := s.Stat(ctx, "a/b.png")
+	if err != nil {
+		t.Fatalf("Stat error = %v", err)
+	}
+	if info.Size != 5 || info.ContentType != "image/png" {
+		t.Errorf("Stat = %+v, want size 5 and image/png", info)
+	}
+	r, err := s.Get(ctx, "a/b.png")
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "hello" {
+		t.Errorf("Get = %q, want %q", data, "hello")
+	}
+
+	if got := s.URL("a/b.png"); got != "http://localhost:8080/uploads/a/b.png" {
+		t.Errorf("URL = %q", got)
+	}
+	if key, err := s.Key("http://localhost:8080/uploads/a/b.png"); err != nil || key != "a/b.png" {
+		t.Errorf("Key = %q, %v, want %q", key, err, "a/b.png")
+	}
+
+	if err := s.Delete(ctx, "a/b.png"); err != nil {
+		t.Fatalf("Delete error = %v", err)
+	}
+	if err := s.Delete(ctx, "a/b.png"); err != nil {
+		t.Fatalf("Delete missing object error = %v", err)
+	}
+	if _, err := s.Get(ctx, "a/b.png"); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Errorf("Get deleted object error = %v, want %v", err, storage.ErrObjectNotFound)
+	}
+}
+
+func TestPresignPutWithoutSecret(t *testing.T) {
+	s := newTestStorage(t, "")
+	if _, err := s.PresignPut(context.Background(), "a.png", "image/png", 10, time.Minute); err == nil {
+		t.Fatal("PresignPut without secret should fail")
+	}
+}
+
+func TestUploadHandler(t *testing.T) {
+	const key = "articles/a.png"
+	testCases := []struct {
+		name   string
+		expire time.Duration
+		// mutate 修改签名之后的请求
+		mutate   func(req *http.Request)
+		method   string
+		guard    UploadGuard
+		body     string
+		wantCode int
+		wantBody string // 为空时表示文件不应该被写入
+	}{
+		{
+			name:     "上传成功",
+			expire:   time.Minute,
+			body:     "hello",
+			wantCode: http.StatusOK,
+			wantBody: "hello",
+		},
+		{
+			name:     "请求方法错误",
+			expire:   time.Minute,
+			method:   http.MethodPost,
+			body:     "hello",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "修改 Content-Type",
+			expire: time.Minute,
+			body:   "hello",
+			mutate: func(req *http.Request) {
+				req.Header.Set("Content-Type", "text/html")
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "修改对象键",
+			expire: time.Minute,
+			body:   "hello",
+			mutate: func(req *http.Request) {
+				req.URL.Path = PathPrefix + "/articles/b.png"
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "修改大小上限",
+			expire: time.Minute,
+			body:   "hello",
+			mutate: func(req *http.Request) {
+				q := req.URL.Query()
+				q.Set("max_size", "1000000")
+				req.URL.RawQuery = q.Encode()
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "地址已过期",
+			expire:   -time.Minute,
+			body:     "hello",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "超过大小上限",
+			expire:   time.Minute,
+			body:     strings.Repeat("x", 11),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "不再允许上传",
+			expire: time.Minute,
+			body:   "hello",
+			guard: func(ctx context.Context, key string) error {
+				return errors.New("upload completed")
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "允许上传",
+			expire: time.Minute,
+			body:   "hello",
+			guard: func(ctx context.Context, k string) error {
+				if k != key {
+					return errors.New("unexpected key")
+				}
+				return nil
+			},
+			wantCode: http.StatusOK,
+			wantBody: "hello",
+		},
+		{
+			name:   "没有声明长度时读取中超过大小上限",
+			expire: time.Minute,
+			body:   strings.Repeat("x", 11),
+			mutate: func(req *http.Request) {
+				req.ContentLength = -1
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestStorage(t, "secret")
+			put, err := s.PresignPut(ctx, key, "image/png", 10, tc.expire)
+			if err != nil {
+				t.Fatalf("PresignPut error = %v", err)
+			}
+			u, err := url.Parse(put.URL)
+			if err != nil {
+				t.Fatalf("parse presigned url: %v", err)
+			}
+			method := tc.method
+			if method == "" {
+				method = put.Method
+			}
+			req := httptest.NewRequest(method, u.RequestURI(), strings.NewReader(tc.body))
+			for k, v := range put.Headers {
+				req.Header.Set(k, v)
+			}
+			if tc.mutate != nil {
+				tc.mutate(req)
+			}
+
+			rec := httptest.NewRecorder()
+			http.StripPrefix(PathPrefix, s.UploadHandler(tc.guard)).ServeHTTP(rec, req)
+			if rec.Code != tc.wantCode {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tc.wantCode, rec.Body.String())
+			}
+
+			r, err := s.Get(ctx, key)
+			if tc.wantBody == "" {
+				if !errors.Is(err, storage.ErrObjectNotFound) {
+					t.Fatalf("object should not be stored, Get error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get error = %v", err)
+			}
+			data, _ := io.ReadAll(r)
+			r.Close()
+			if string(data) != tc.wantBody {
+				t.Errorf("stored = %q, want %q", data, tc.wantBody)
+			}
+		})
+	}
+}
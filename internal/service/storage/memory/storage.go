@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+)
+
+// Storage 内存存储，进程退出后数据丢失，用于测试和本地调试
+type Storage struct {
+	mu      sync.RWMutex
+	objects map[string]object
+	baseURL string
+}
+
+type object struct {
+	data        []byte
+	contentType string
+}
+
+func NewStorage(baseURL string) *Storage {
+	return &Storage{
+		objects: make(map[string]object),
+		baseURL: baseURL,
+	}
+}
+
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if key == "" {
+		return storage.ErrInvalidKey
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = object{data: data, contentType: contentType}
+	return nil
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, storage.ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (s *Storage) Stat(ctx context.Context, key string) (storage.ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return storage.ObjectInfo{}, storage.ErrObjectNotFound
+	}
+	return storage.ObjectInfo{
+		Size:        int64(len(obj.data)),
+		ContentType: obj.contentType,
+	}, nil
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *Storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *Storage) Key(url string) (string, error) {
+	return storage.KeyFromURL(s.baseURL, url)
+}
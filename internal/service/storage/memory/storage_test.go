@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewStorage("memory://uploads")
+
+	if err := s.Put(ctx, "", strings.NewReader("x"), 1, "text/plain"); !errors.Is(err, storage.ErrInvalidKey) {
+		t.Fatalf("Put with empty key error = %v, want %v", err, storage.ErrInvalidKey)
+	}
+	if err := s.Put(ctx, "a/b.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put error = %v", err)
+	}
+
+	info, err := s.Stat(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatalf("Stat error = %v", err)
+	}
+	if info.Size != 5 || info.ContentType != "text/plain" {
+		t.Errorf("Stat = %+v, want size 5 and text/plain", info)
+	}
+
+	r, err := s.Get(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "hello" {
+		t.Errorf("Get = %q, want %q", data, "hello")
+	}
+
+	key, err := s.Key(s.URL("a/b.txt"))
+	if err != nil || key != "a/b.txt" {
+		t.Errorf("Key(URL) = %q, %v, want %q", key, err, "a/b.txt")
+	}
+
+	if err := s.Delete(ctx, "a/b.txt"); err != nil {
+		t.Fatalf("Delete error = %v", err)
+	}
+	// 重复删除不返回错误
+	if err := s.Delete(ctx, "a/b.txt"); err != nil {
+		t.Fatalf("Delete missing object error = %v", err)
+	}
+	if _, err := s.Get(ctx, "a/b.txt"); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Errorf("Get deleted object error = %v, want %v", err, storage.ErrObjectNotFound)
+	}
+	if _, err := s.Stat(ctx, "a/b.txt"); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Errorf("Stat deleted object error = %v, want %v", err, storage.ErrObjectNotFound)
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrInvalidURL 地址不属于当前存储
+	ErrInvalidURL = errors.New("URL does not belong to this storage")
+	// ErrInvalidKey 对象键不合法
+	ErrInvalidKey = errors.New("invalid object key")
+	// ErrObjectNotFound 对象不存在
+	ErrObjectNotFound = errors.New("object not found")
+)
+
+// ObjectInfo 对象的元信息
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// Storage 对象存储，对象通过 key 定位，通过 URL 公开访问
+type Storage interface {
+	// Put 上传对象，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，对象不存在时返回 ErrObjectNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Stat 读取对象的元信息，对象不存在时返回 ErrObjectNotFound
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// URL 对象的公开访问地址
+	URL(key string) string
+	// Key 从公开访问地址中解析出对象键，地址不属于当前存储时返回 ErrInvalidURL
+	Key(url string) (string, error)
+}
+
+// PresignedPut 预签名的上传请求，客户端按照 Method、URL 并带上 Headers 直接上传到存储
+type PresignedPut struct {
+	Method   string
+	URL      string
+	Headers  map[string]string
+	ExpireAt time.Time
+}
+
+// Presigner 支持客户端直传的存储
+type Presigner interface {
+	// PresignPut 生成上传 key 的预签名地址，上传时必须使用 contentType
+	// 不是所有存储都能在签名中限制大小，调用方在上传完成后仍需要检查 maxSize
+	PresignPut(ctx context.Context, key, contentType string, maxSize int64, expire time.Duration) (PresignedPut, error)
+}
+
+// KeyFromURL 按照 baseURL + "/" + key 的规则解析对象键，供各个实现复用
+func KeyFromURL(baseURL, url string) (string, error) {
+	prefix := baseURL + "/"
+	if len(url) <= len(prefix) || url[:len(prefix)] != prefix {
+		return "", ErrInvalidURL
+	}
+	return url[len(prefix):], nil
+}
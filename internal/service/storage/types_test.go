@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKeyFromURL(t *testing.T) {
+	testCases := []struct {
+		name    string
+		baseURL string
+		url     string
+		wantKey string
+		wantErr error
+	}{
+		{
+			name:    "正常地址",
+			baseURL: "http://localhost:8080/uploads",
+			url:     "http://localhost:8080/uploads/articles/2024/01/02/a.png",
+			wantKey: "articles/2024/01/02/a.png",
+		},
+		{
+			name:    "其他存储的地址",
+			baseURL: "http://localhost:8080/uploads",
+			url:     "https://cdn.example.com/articles/a.png",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "前缀相同但不是子路径",
+			baseURL: "http://localhost:8080/uploads",
+			url:     "http://localhost:8080/uploads2/a.png",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "没有对象键",
+			baseURL: "http://localhost:8080/uploads",
+			url:     "http://localhost:8080/uploads/",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "空地址",
+			baseURL: "http://localhost:8080/uploads",
+			url:     "",
+			wantErr: ErrInvalidURL,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := KeyFromURL(tc.baseURL, tc.url)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("KeyFromURL(%q) error = %v, want %v", tc.url, err, tc.wantErr)
+			}
+			if key != tc.wantKey {
+				t.Errorf("KeyFromURL(%q) = %q, want %q", tc.url, key, tc.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+)
+
+// 热门标签统计的时间窗口
+const trendingTagWindow = time.Hour * 24 * 7
+
+// TagServiceInterface 标签服务接口
+type TagServiceInterface interface {
+	// ListArticlesByTag 获取某个标签下已发布的文章
+	ListArticlesByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, error)
+	// Trending 获取最近一段时间的热门标签
+	Trending(ctx context.Context, limit int) ([]domain.Tag, error)
+	// Suggest 标签自动补全
+	Suggest(ctx context.Context, prefix string, limit int) ([]domain.Tag, error)
+}
+
+type TagService struct {
+	repo        repository.TagRepository
+	articleRepo repository.ArticleRepository
+}
+
+func NewTagService(repo repository.TagRepository, articleRepo repository.ArticleRepository) TagServiceInterface {
+	return &TagService{
+		repo:        repo,
+		articleRepo: articleRepo,
+	}
+}
+
+// ListArticlesByTag 获取某个标签下已发布的文章
+func (s *TagService) ListArticlesByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, error) {
+	tag = domain.NormalizeTag(tag)
+	if tag == "" {
+		return []domain.Article{}, nil
+	}
+	return s.articleRepo.ListPublicByTag(ctx, tag, offset, limit)
+}
+
+// Trending 获取最近一段时间的热门标签
+func (s *TagService) Trending(ctx context.Context, limit int) ([]domain.Tag, error) {
+	since := time.Now().Add(-trendingTagWindow).Truncate(time.Hour)
+	return s.repo.FindTrending(ctx, since, limit)
+}
+
+// Suggest 标签自动补全
+func (s *TagService) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	prefix = domain.NormalizeTag(prefix)
+	if prefix == "" {
+		return []domain.Tag{}, nil
+	}
+	return s.repo.FindByPrefix(ctx, prefix, limit)
+}
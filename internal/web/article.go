@@ -1,12 +1,16 @@
 package web
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
 	"github.com/Fairy-nn/inspora/internal/service"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/sync/errgroup"
@@ -35,47 +39,68 @@ func NewArticleHandler(svc service.ArticleServiceInterface,
 
 // RegisterRoutes 注册路由
 func (a *ArticleHandler) RegisterRoutes(r *gin.Engine) {
-	ag := r.Group("/article")        // 文章相关路由
-	ag.POST("/edit", a.Edit)         // 创建文章
-	ag.POST("/publish", a.Publish)   // 发布文章
-	ag.POST("/withdraw", a.Withdraw) // 撤回文章
-	ag.POST("/list", a.List)         // 文章列表
-	ag.GET("/detail/:id", a.Detail)  // 文章详情,用户查看自己所有状态的文章
+	ag := r.Group("/article")               // 文章相关路由
+	ag.POST("/edit", a.Edit)                // 创建文章
+	ag.POST("/publish", a.Publish)          // 发布文章
+	ag.POST("/schedule", a.Schedule)        // 定时发布文章
+	ag.POST("/withdraw", a.Withdraw)        // 撤回文章
+	ag.POST("/delete", a.Delete)            // 删除文章
+	ag.POST("/restore", a.Restore)          // 恢复已删除的文章
+	ag.POST("/private", a.Private)          // 将文章设为私密
+	ag.POST("/share", a.Share)              // 创建分享链接
+	ag.GET("/share/list", a.ShareList)      // 文章的分享链接列表
+	ag.POST("/share/revoke", a.RevokeShare) // 撤销分享链接
+	ag.POST("/list", a.List)                // 文章列表
+	ag.GET("/detail/:id", a.Detail)         // 文章详情,用户查看自己所有状态的文章
 
-	pub := r.Group("/pub")          // 公开文章相关路由
-	pub.GET("/:id", a.PubDetail)    // 发布文章详情，用户查看所有已公布的文章
-	pub.POST("/like", a.Like)       // 点赞文章
-	pub.POST("/collect", a.Collect) // 收藏文章
-	pub.GET("/rank", a.Ranking)     // 文章排行榜
+	pub := r.Group("/pub")                             // 公开文章相关路由
+	pub.GET("/:id", a.PubDetail)                       // 发布文章详情，用户查看所有已公布的文章，携带 share 参数时可以不登录阅读私密文章
+	pub.POST("/like", a.Like)                          // 点赞文章
+	pub.POST("/collect", a.Collect)                    // 收藏文章
+	pub.GET("/rank", a.Ranking)                        // 文章排行榜
+	pub.GET("/latest", a.Latest)                       // 最新发布的文章
+	pub.GET("/authors/:id/articles", a.AuthorArticles) // 作者主页，作者已发布的文章
 
 }
 
 // 前端的请求体
 type Request struct {
-	ID      int64  `json:"id"` //文章ID
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	ID      int64    `json:"id"` //文章ID
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Format  string   `json:"format"`   // 内容格式：plain/markdown/html，默认为 plain
 	ImgUrls []string `json:"img_urls"` // 图片地址
+	Tags    []string `json:"tags"`     // 标签
+	Version int64    `json:"version"`  // 编辑时读到的版本号，用于检测协作编辑冲突，修改已有文章时必须携带
 }
 
 // 文章列表请求体
 type ListRequest struct {
-	Limit  int `json:"limit"`  // 每页数量
-	Offset int `json:"offset"` // 偏移量
+	Limit  int    `json:"limit"`  // 每页数量
+	Cursor string `json:"cursor"` // 分页游标，第一页为空，之后传上一页返回的 next_cursor
 }
 
 type ArticleV0 struct {
-	ID         int64  `json:"id"`          //文章ID
-	Title      string `json:"title"`       // 文章标题
-	Abstract   string `json:"abstract"`    // 文章摘要
-	Content    string `json:"content"`     // 文章内容
-	AuthorID   int64  `json:"author_id"`   // 作者ID
-	AuthorName string `json:"author_name"` // 作者名称
-	Status     uint8  `json:"status"`      // 文章状态
-	Ctime      int64  `json:"ctime"`       // 创建时间
-	Utime      int64  `json:"utime"`       // 更新时间
-	ViewCount  int64  `json:"view_count"`  // 浏览量
-	ImgUrls    []string `json:"img_urls"`    // 图片地址
+	ID              int64        `json:"id"`                          //文章ID
+	Title           string       `json:"title"`                       // 文章标题
+	Abstract        string       `json:"abstract"`                    // 文章摘要
+	Content         string       `json:"content"`                     // 文章内容
+	Format          string       `json:"format"`                      // 内容格式
+	HTML            string       `json:"html,omitempty"`              // 渲染并清洗后的 HTML
+	AuthorID        int64        `json:"author_id"`                   // 作者ID
+	AuthorName      string       `json:"author_name"`                 // 作者名称
+	Status          uint8        `json:"status"`                      // 文章状态
+	Ctime           int64        `json:"ctime"`                       // 创建时间
+	Utime           int64        `json:"utime"`                       // 更新时间
+	ViewCount       int64        `json:"view_count"`                  // 浏览量
+	UniqueViewCount int64        `json:"unique_view_count,omitempty"` // 独立浏览量
+	LikeCount       int64        `json:"like_count,omitempty"`        // 点赞量
+	CollectCount    int64        `json:"collect_count,omitempty"`     // 收藏量
+	ImgUrls         []string     `json:"img_urls"`                    // 图片地址
+	PublishAt       int64        `json:"publish_at,omitempty"`        // 定时发布时间
+	Tags            []string     `json:"tags"`                        // 标签
+	Series          *SeriesNavVO `json:"series,omitempty"`            // 系列导航
+	Version         int64        `json:"version,omitempty"`           // 版本号，编辑时原样传回
 }
 
 // Edit 编辑文章
@@ -90,26 +115,44 @@ func (a *ArticleHandler) Edit(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "title and content are required"})
 		return
 	}
+	format, ok := domain.ParseContentFormat(req.Format)
+	if !ok {
+		c.JSON(400, gin.H{"error": "unsupported content format"})
+		return
+	}
+	if !checkVersion(c, req) {
+		return
+	}
 	// 获取用户ID
 	userID, ok := c.Get("userID")
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	userIDfloat, _ := userID.(float64)
+	userIDInt64, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid user ID type"})
+		return
+	}
 
 	// 调用服务层保存文章
 	articleID, err := a.svc.Save(c, domain.Article{
 		Title:   req.Title,
 		Content: req.Content,
+		Format:  format,
 		ImgUrls: req.ImgUrls,
+		Tags:    req.Tags,
 		Author: domain.Author{
-			ID: int64(userIDfloat), //作者ID
+			ID: userIDInt64, // 当前编辑的用户，可能是作者也可能是协作者
 		},
-		ID: req.ID, // 文章ID
+		ID:      req.ID, // 文章ID
+		Version: req.Version,
 	})
 
 	if err != nil {
+		if writeCollabError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save article"})
 		return
 	}
@@ -132,6 +175,14 @@ func (a *ArticleHandler) Publish(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "title and content are required"})
 		return
 	}
+	format, ok := domain.ParseContentFormat(req.Format)
+	if !ok {
+		c.JSON(400, gin.H{"error": "unsupported content format"})
+		return
+	}
+	if !checkVersion(c, req) {
+		return
+	}
 	// 获取用户ID
 	userID, ok := c.Get("userID")
 	if !ok {
@@ -149,13 +200,19 @@ func (a *ArticleHandler) Publish(c *gin.Context) {
 		ID:      req.ID,
 		Title:   req.Title,
 		Content: req.Content,
+		Format:  format,
 		ImgUrls: req.ImgUrls,
+		Tags:    req.Tags,
 		Author: domain.Author{
 			ID: userIDInt64, //作者ID
 		},
+		Version: req.Version,
 	})
 	// 保存失败
 	if err != nil {
+		if writeCollabError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save article"})
 		return
 	}
@@ -166,6 +223,75 @@ func (a *ArticleHandler) Publish(c *gin.Context) {
 	})
 }
 
+// Schedule 定时发布文章
+func (a *ArticleHandler) Schedule(c *gin.Context) {
+	type Req struct {
+		Request
+		PublishAt int64 `json:"publish_at"` // 定时发布时间，毫秒时间戳
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	// 文章标题和内容不能为空
+	if req.Title == "" || req.Content == "" {
+		c.JSON(400, gin.H{"error": "title and content are required"})
+		return
+	}
+	format, ok := domain.ParseContentFormat(req.Format)
+	if !ok {
+		c.JSON(400, gin.H{"error": "unsupported content format"})
+		return
+	}
+	if !checkVersion(c, req.Request) {
+		return
+	}
+	if req.PublishAt <= 0 {
+		c.JSON(400, gin.H{"error": "publish_at is required"})
+		return
+	}
+	// 获取用户ID
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userIDInt64, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid user ID type"})
+		return
+	}
+
+	articleID, err := a.svc.Schedule(c, domain.Article{
+		ID:        req.ID,
+		Title:     req.Title,
+		Content:   req.Content,
+		Format:    format,
+		ImgUrls:   req.ImgUrls,
+		Tags:      req.Tags,
+		Author:    domain.Author{ID: userIDInt64},
+		PublishAt: time.UnixMilli(req.PublishAt),
+		Version:   req.Version,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPublishTime) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if writeCollabError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to schedule article"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "success",
+		"article_id": articleID,
+		"publish_at": req.PublishAt,
+	})
+}
+
 // Withdraw 撤回文章
 func (a *ArticleHandler) Withdraw(c *gin.Context) {
 	// 请求体结构体
@@ -191,14 +317,23 @@ func (a *ArticleHandler) Withdraw(c *gin.Context) {
 		return
 	}
 
+	userIDInt64, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid user ID type"})
+		return
+	}
+
 	// 调用服务层撤回文章方法
 	err := a.svc.Withdraw(c, domain.Article{
 		ID: req.ID,
 		Author: domain.Author{
-			ID: int64(userID.(float64)), // 作者ID
+			ID: userIDInt64, // 当前操作的用户，可能是作者也可能是编辑者
 		},
 	})
 	if err != nil {
+		if writeCollabError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to withdraw article"})
 		return
 	}
@@ -209,6 +344,265 @@ func (a *ArticleHandler) Withdraw(c *gin.Context) {
 	})
 }
 
+// Delete 删除文章，permanent 为 true 时彻底删除，否则软删除
+func (a *ArticleHandler) Delete(c *gin.Context) {
+	type Req struct {
+		ID        int64 `json:"id"`        // 文章ID
+		Permanent bool  `json:"permanent"` // 是否彻底删除
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userIDInt64, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid user ID type"})
+		return
+	}
+
+	err := a.svc.Delete(c, req.ID, userIDInt64, req.Permanent)
+	if err != nil {
+		// 文章不存在或者不属于当前用户
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "article not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete article"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "success",
+		"action":     "delete",
+		"article_id": req.ID,
+		"permanent":  req.Permanent,
+	})
+}
+
+// Restore 恢复软删除的文章，恢复后文章为草稿
+func (a *ArticleHandler) Restore(c *gin.Context) {
+	type Req struct {
+		ID int64 `json:"id"` // 文章ID
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	uid, ok := a.authorID(c)
+	if !ok {
+		return
+	}
+
+	if err := a.svc.Restore(c, req.ID, uid); err != nil {
+		switch {
+		// 文章不存在或者不属于当前用户
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		case errors.Is(err, service.ErrArticleNotDeleted):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore article"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "success",
+		"action":     "restore",
+		"article_id": req.ID,
+	})
+}
+
+// Private 将文章设为私密，私密文章只有作者和持有分享链接的人可以阅读
+func (a *ArticleHandler) Private(c *gin.Context) {
+	type Req struct {
+		ID int64 `json:"id"` // 文章ID
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	uid, ok := a.authorID(c)
+	if !ok {
+		return
+	}
+
+	if err := a.svc.MakePrivate(c, req.ID, uid); err != nil {
+		// 文章不存在或者不属于当前用户
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "article not found"})
+			return
+		}
+		if errors.Is(err, service.ErrArticleNotPublished) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to make article private"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "success",
+		"action":     "private",
+		"article_id": req.ID,
+	})
+}
+
+// ShareVO 分享链接VO
+type ShareVO struct {
+	ID        int64  `json:"id"`
+	ArticleID int64  `json:"article_id"`
+	Token     string `json:"token"`
+	ExpireAt  int64  `json:"expire_at"`
+	Expired   bool   `json:"expired"`
+	Ctime     int64  `json:"ctime"`
+}
+
+// Share 为文章创建分享链接，expire_in 为有效期（秒），不传时默认 7 天
+func (a *ArticleHandler) Share(c *gin.Context) {
+	type Req struct {
+		ID       int64 `json:"id"`        // 文章ID
+		ExpireIn int64 `json:"expire_in"` // 有效期，单位秒
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	if req.ExpireIn < 0 {
+		c.JSON(400, gin.H{"error": service.ErrInvalidShareExpire.Error()})
+		return
+	}
+	uid, ok := a.authorID(c)
+	if !ok {
+		return
+	}
+
+	share, err := a.svc.CreateShare(c, req.ID, uid, time.Duration(req.ExpireIn)*time.Second)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidShareExpire), errors.Is(err, service.ErrArticleNotShareable):
+			c.JSON(400, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create share link"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"share":   toShareVO(share, time.Now()),
+	})
+}
+
+// ShareList 获取文章的分享链接，包括已过期的
+func (a *ArticleHandler) ShareList(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Query("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "文章ID不合法"})
+		return
+	}
+	uid, ok := a.authorID(c)
+	if !ok {
+		return
+	}
+
+	shares, err := a.svc.ListShares(c, id, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list share links"})
+		return
+	}
+	now := time.Now()
+	vos := make([]ShareVO, 0, len(shares))
+	for _, share := range shares {
+		vos = append(vos, toShareVO(share, now))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "success",
+		"article_id": id,
+		"shares":     vos,
+	})
+}
+
+// RevokeShare 撤销分享链接
+func (a *ArticleHandler) RevokeShare(c *gin.Context) {
+	type Req struct {
+		ID int64 `json:"id"` // 分享链接ID
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	uid, ok := a.authorID(c)
+	if !ok {
+		return
+	}
+
+	if err := a.svc.RevokeShare(c, req.ID, uid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "share link not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke share link"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success", "action": "revoke", "share_id": req.ID})
+}
+
+// authorID 获取当前登录用户的ID
+func (a *ArticleHandler) authorID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid user ID type"})
+		return 0, false
+	}
+	return uid, true
+}
+
+func toShareVO(share domain.ArticleShare, now time.Time) ShareVO {
+	return ShareVO{
+		ID:        share.ID,
+		ArticleID: share.ArticleID,
+		Token:     share.Token,
+		ExpireAt:  share.ExpireAt.UnixMilli(),
+		Expired:   share.Expired(now),
+		Ctime:     share.Ctime.UnixMilli(),
+	}
+}
+
 // List 文章列表
 func (a *ArticleHandler) List(c *gin.Context) {
 	var req ListRequest
@@ -220,6 +614,11 @@ func (a *ArticleHandler) List(c *gin.Context) {
 	if req.Limit <= 0 {
 		req.Limit = 10 // 默认值
 	}
+	cursor, err := domain.ParseArticleCursor(req.Cursor)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid cursor"})
+		return
+	}
 
 	// 获取用户ID
 	userID, ok := c.Get("userID")
@@ -229,7 +628,7 @@ func (a *ArticleHandler) List(c *gin.Context) {
 	}
 
 	// 调用服务层获取文章列表
-	articles, err := a.svc.List(c, userID.(int64), req.Limit, req.Offset)
+	articles, err := a.svc.List(c, userID.(int64), cursor, req.Limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get article list"})
 		return
@@ -247,7 +646,107 @@ func (a *ArticleHandler) List(c *gin.Context) {
 		"articles": articleVOs,
 		"total":    len(articleVOs),
 		//	"page":      req.Offset / req.Limit,
-		"page_size": req.Limit,
+		"page_size":   req.Limit,
+		"next_cursor": domain.NextArticleCursor(articles, req.Limit).Encode(),
+	})
+}
+
+// Latest 最新发布的文章，按更新时间倒序，使用游标翻页
+func (a *ArticleHandler) Latest(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	cursor, err := domain.ParseArticleCursor(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+
+	articles, err := a.svc.ListPublic(c, cursor, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get latest articles"})
+		return
+	}
+
+	articleVOs := make([]ArticleV0, 0, len(articles))
+	for _, article := range articles {
+		// 列表中不返回正文
+		vo := toArticleVO(article)
+		vo.Content = ""
+		vo.HTML = ""
+		articleVOs = append(articleVOs, vo)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "success",
+		"articles":    articleVOs,
+		"next_cursor": domain.NextArticleCursor(articles, limit).Encode(),
+	})
+}
+
+// AuthorArticles 作者主页，列出作者已发布的文章，附带摘要和互动计数
+func (a *ArticleHandler) AuthorArticles(c *gin.Context) {
+	authorID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || authorID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid author id"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	cursor, err := domain.ParseArticleCursor(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+
+	author, articles, err := a.svc.ListPublicByAuthor(c, authorID, cursor, limit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "author not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get author articles"})
+		return
+	}
+
+	// 批量获取互动计数
+	ids := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	interactions := map[int64]domain.Interaction{}
+	if len(ids) > 0 {
+		interactions, err = a.interactionSvc.GetByIds(c, a.biz, ids)
+		if err != nil {
+			// 计数获取失败不影响列表展示
+			fmt.Println("获取文章互动信息失败", err)
+		}
+	}
+
+	articleVOs := make([]ArticleV0, 0, len(articles))
+	for _, article := range articles {
+		// 列表中只返回摘要
+		vo := toArticleVO(article)
+		vo.Content = ""
+		vo.HTML = ""
+		if intr, ok := interactions[article.ID]; ok {
+			vo.ViewCount = intr.ViewCnt
+			vo.UniqueViewCount = intr.UniqueViewCnt
+			vo.LikeCount = intr.LikeCnt
+			vo.CollectCount = intr.CollectCnt
+		}
+		articleVOs = append(articleVOs, vo)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"author": gin.H{
+			"id":   author.ID,
+			"name": author.Name,
+		},
+		"articles":    articleVOs,
+		"next_cursor": domain.NextArticleCursor(articles, limit).Encode(),
 	})
 }
 
@@ -257,14 +756,52 @@ func toArticleVO(article domain.Article) ArticleV0 {
 		ID:         article.ID,
 		Title:      article.Title,
 		Content:    article.Content,
+		Format:     article.Format.String(),
+		HTML:       article.HTML,
 		AuthorID:   article.Author.ID,
 		AuthorName: article.Author.Name,
 		Status:     article.Status.ToUint8(),
 		Abstract:   article.GenerateAbstract(),
 		Ctime:      article.Ctime.UnixMilli(),
 		Utime:      article.Utime.UnixMilli(),
-		ImgUrls:   article.ImgUrls,
+		ImgUrls:    article.ImgUrls,
+		PublishAt:  publishAtMilli(article.PublishAt),
+		Tags:       article.Tags,
+		Series:     toSeriesNavVO(article.SeriesNav),
+		Version:    article.Version,
+	}
+}
+
+// checkVersion 修改已有文章时必须携带读到的版本号，缺少时按冲突处理，返回 false 表示已经响应
+func checkVersion(c *gin.Context, req Request) bool {
+	if req.ID > 0 && req.Version <= 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "version is required"})
+		return false
 	}
+	return true
+}
+
+// writeCollabError 处理协作编辑相关的错误，已处理时返回 true
+func writeCollabError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, repository.ErrArticleVersionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrArticlePermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "article not found"})
+	default:
+		return false
+	}
+	return true
+}
+
+// publishAtMilli 未设置定时发布时返回 0
+func publishAtMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
 }
 
 // Detail 文章详情
@@ -320,9 +857,13 @@ func (a *ArticleHandler) PubDetail(c *gin.Context) {
 		return
 	}
 
-	// 获取用户ID
+	// 获取用户ID，通过分享链接阅读时可以不登录，此时用户ID为 0
+	share := c.Query("share")
+	var uid int64
 	userID, exists := c.Get("userID")
-	if !exists {
+	if exists {
+		uid, _ = userID.(int64)
+	} else if share == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
 		return
 	}
@@ -337,14 +878,18 @@ func (a *ArticleHandler) PubDetail(c *gin.Context) {
 	// 并发获取文章信息
 	eg.Go(func() error {
 		var err error
-		art, err = a.svc.FindPublicArticleById(c, id, userID.(int64))
+		if share != "" {
+			art, err = a.svc.FindSharedArticle(c, id, share, uid)
+		} else {
+			art, err = a.svc.FindPublicArticleById(c, id, uid)
+		}
 		return err
 	})
 
 	// 并发获取交互信息
 	eg.Go(func() error {
 		// 根据文章 ID 和用户 ID 获取交互信息
-		interaction, err = a.interactionSvc.Get(c, a.biz, id, userID.(int64))
+		interaction, err = a.interactionSvc.Get(c, a.biz, id, uid)
 		fmt.Println("interaction:", interaction)
 		if err != gorm.ErrRecordNotFound {
 			return err
@@ -363,21 +908,37 @@ func (a *ArticleHandler) PubDetail(c *gin.Context) {
 		}
 		return
 	}
+	// 只有成功阅读文章详情才记录浏览
+	a.svc.RecordView(c, art.ID, a.viewer(c, uid))
 
 	c.JSON(200, gin.H{
 		"message":    "success",
 		"article_id": art.ID,
 		"article":    toArticleVO(art),
 		"interaction": gin.H{
-			"like_count":    interaction.LikeCnt,
-			"collect_count": interaction.CollectCnt,
-			"view_count":    interaction.ViewCnt,
-			"liked":         interaction.Liked,
-			"collected":     interaction.Collected,
+			"like_count":        interaction.LikeCnt,
+			"collect_count":     interaction.CollectCnt,
+			"view_count":        interaction.ViewCnt,
+			"unique_view_count": interaction.UniqueViewCnt,
+			"liked":             interaction.Liked,
+			"collected":         interaction.Collected,
 		},
 	})
 }
 
+// viewer 获取阅读文章的访客，未登录访客的设备标识由服务端根据 IP 和 User-Agent 生成
+// 不使用客户端上报的设备标识，否则每次请求换一个标识就能绕过去重刷浏览量
+func (a *ArticleHandler) viewer(c *gin.Context, uid int64) domain.Viewer {
+	ua := c.Request.UserAgent()
+	sum := sha256.Sum256([]byte(c.ClientIP() + "|" + ua))
+	device := hex.EncodeToString(sum[:16])
+	return domain.Viewer{
+		Uid:       uid,
+		DeviceID:  device,
+		UserAgent: ua,
+	}
+}
+
 // Like 点赞文章
 func (a *ArticleHandler) Like(c *gin.Context) {
 	type LikeRequest struct {
@@ -428,12 +989,20 @@ func (a *ArticleHandler) Like(c *gin.Context) {
 	}
 
 	if req.Like { // true
-		err = a.interactionSvc.Like(c, a.biz, req.ID, userID.(int64))
+		_, err = a.interactionSvc.Like(c, a.biz, req.ID, userID.(int64))
 	} else { // false
-		err = a.interactionSvc.CancelLike(c, a.biz, req.ID, userID.(int64))
+		_, err = a.interactionSvc.CancelLike(c, a.biz, req.ID, userID.(int64))
 	}
 
 	if err != nil {
+		if errors.Is(err, service.ErrUserBlocked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrArticleUnavailable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
 		c.JSON(500, gin.H{"error": "Failed to like/dislike article"})
 		return
 	}
@@ -449,6 +1018,7 @@ func (a *ArticleHandler) Collect(c *gin.Context) {
 	type CollectRequest struct {
 		ID      int64 `json:"id"`
 		Collect bool  `json:"collect"`
+		Cid     int64 `json:"cid"` // 收藏夹ID，不传时收藏到默认收藏夹，取消收藏时不需要
 	}
 
 	var req CollectRequest
@@ -471,12 +1041,24 @@ func (a *ArticleHandler) Collect(c *gin.Context) {
 
 	var err error
 	if req.Collect {
-		err = a.interactionSvc.Collect(c, a.biz, req.ID, 0, userID.(int64))
+		err = a.interactionSvc.Collect(c, a.biz, req.ID, req.Cid, userID.(int64))
 	} else {
-		err = a.interactionSvc.CancelCollect(c, a.biz, req.ID, 0, userID.(int64))
+		err = a.interactionSvc.CancelCollect(c, a.biz, req.ID, userID.(int64))
 	}
 
 	if err != nil {
+		if errors.Is(err, service.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+			return
+		}
+		if errors.Is(err, service.ErrCollectedInOtherCollection) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrArticleUnavailable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
 		c.JSON(500, gin.H{"error": "Failed to collect/uncollect article"})
 		return
 	}
@@ -0,0 +1,232 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CollaborationHandler 文章协作处理器
+type CollaborationHandler struct {
+	svc service.CollaborationServiceInterface
+}
+
+// NewCollaborationHandler 创建文章协作处理器
+func NewCollaborationHandler(svc service.CollaborationServiceInterface) *CollaborationHandler {
+	return &CollaborationHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *CollaborationHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/article/collab")
+	g.POST("/invite", h.Invite)          // 邀请协作者或修改协作者的角色
+	g.POST("/respond", h.Respond)        // 接受或拒绝邀请
+	g.POST("/remove", h.Remove)          // 移除协作者或退出协作
+	g.GET("/members", h.Members)         // 文章的协作者
+	g.GET("/invitations", h.Invitations) // 我收到的邀请
+	g.GET("/articles", h.Articles)       // 我参与协作的文章
+}
+
+// CollaboratorVO 协作者VO
+type CollaboratorVO struct {
+	ArticleID int64  `json:"article_id"`
+	UserID    int64  `json:"user_id"`
+	Role      string `json:"role"`
+	Accepted  bool   `json:"accepted"`
+	InviterID int64  `json:"inviter_id"`
+	Ctime     int64  `json:"ctime"`
+}
+
+// Invite 邀请协作者，role 为 editor 或 viewer
+func (h *CollaborationHandler) Invite(c *gin.Context) {
+	type Req struct {
+		ArticleID int64  `json:"article_id"`
+		UserID    int64  `json:"user_id"`
+		Role      string `json:"role"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ArticleID <= 0 || req.UserID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "article_id and user_id are required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.Invite(c, uid, req.ArticleID, req.UserID, domain.ParseCollaboratorRole(req.Role))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Respond 接受或拒绝邀请
+func (h *CollaborationHandler) Respond(c *gin.Context) {
+	type Req struct {
+		ArticleID int64 `json:"article_id"`
+		Accept    bool  `json:"accept"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ArticleID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "article_id is required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Respond(c, uid, req.ArticleID, req.Accept); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Remove 作者移除协作者，user_id 为自己时表示退出协作
+func (h *CollaborationHandler) Remove(c *gin.Context) {
+	type Req struct {
+		ArticleID int64 `json:"article_id"`
+		UserID    int64 `json:"user_id"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ArticleID <= 0 || req.UserID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "article_id and user_id are required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Remove(c, uid, req.ArticleID, req.UserID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Members 获取文章的协作者
+func (h *CollaborationHandler) Members(c *gin.Context) {
+	articleID, err := strconv.ParseInt(c.Query("id"), 10, 64)
+	if err != nil || articleID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid article id"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.svc.Members(c, uid, articleID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toCollaboratorVOs(members)})
+}
+
+// Invitations 获取我收到的还没有处理的邀请
+func (h *CollaborationHandler) Invitations(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	invitations, err := h.svc.Invitations(c, uid)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toCollaboratorVOs(invitations)})
+}
+
+// Articles 获取我参与协作的文章，按更新时间倒序
+func (h *CollaborationHandler) Articles(c *gin.Context) {
+	cursor, err := domain.ParseArticleCursor(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid cursor"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	articles, err := h.svc.ListArticles(c, uid, cursor, limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	vos := make([]ArticleV0, 0, len(articles))
+	for _, article := range articles {
+		// 列表中不返回正文
+		vo := toArticleVO(article)
+		vo.Content = ""
+		vo.HTML = ""
+		vos = append(vos, vo)
+	}
+	c.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "success",
+		Data: gin.H{
+			"articles":    vos,
+			"next_cursor": domain.NextArticleCursor(articles, limit).Encode(),
+		},
+	})
+}
+
+func (h *CollaborationHandler) userID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
+
+func (h *CollaborationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCollaboratorRole), errors.Is(err, service.ErrInvalidCollaborator):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	case errors.Is(err, service.ErrArticlePermissionDenied):
+		c.JSON(http.StatusForbidden, Result{Code: 403, Msg: err.Error()})
+	case errors.Is(err, repository.ErrArticleNotFound):
+		// 文章、用户或者邀请不存在
+		c.JSON(http.StatusNotFound, Result{Code: 404, Msg: "not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+func toCollaboratorVOs(cs []domain.Collaborator) []CollaboratorVO {
+	res := make([]CollaboratorVO, 0, len(cs))
+	for _, co := range cs {
+		res = append(res, CollaboratorVO{
+			ArticleID: co.ArticleID,
+			UserID:    co.UserID,
+			Role:      co.Role.String(),
+			Accepted:  co.Active(),
+			InviterID: co.InviterID,
+			Ctime:     co.Ctime.UnixMilli(),
+		})
+	}
+	return res
+}
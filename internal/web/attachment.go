@@ -0,0 +1,266 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PartChecksumHeader 上传分片时携带分片 SHA-256 的请求头
+const PartChecksumHeader = "X-Content-SHA256"
+
+// AttachmentHandler 文章附件处理器，附件通过分片上传，网络中断之后可以续传
+type AttachmentHandler struct {
+	svc service.AttachmentServiceInterface
+}
+
+// NewAttachmentHandler 创建文章附件处理器
+func NewAttachmentHandler(svc service.AttachmentServiceInterface) *AttachmentHandler {
+	return &AttachmentHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *AttachmentHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/attachment")
+	g.POST("/upload/init", h.Initiate)             // 申请分片上传
+	g.PUT("/upload/:id/parts/:part", h.UploadPart) // 上传分片，请求体为分片内容
+	g.GET("/upload/:id", h.Status)                 // 已上传的分片，断点续传时使用
+	g.POST("/upload/:id/complete", h.Complete)     // 合并分片
+	g.POST("/upload/:id/abort", h.Abort)           // 放弃上传
+	g.GET("/list", h.List)                         // 文章的附件
+	g.POST("/delete", h.Delete)                    // 删除附件
+}
+
+// AttachmentVO 附件VO
+type AttachmentVO struct {
+	ID        int64  `json:"id"`
+	ArticleID int64  `json:"article_id"`
+	Filename  string `json:"filename"`
+	URL       string `json:"url"`
+	Size      int64  `json:"size"`
+	MimeType  string `json:"mime_type"`
+	SHA256    string `json:"sha256"`
+	Ctime     int64  `json:"ctime"`
+}
+
+// ChunkUploadVO 分片上传任务VO
+type ChunkUploadVO struct {
+	UploadID      string `json:"upload_id"`
+	ArticleID     int64  `json:"article_id"`
+	Filename      string `json:"filename"`
+	Size          int64  `json:"size"`
+	ChunkSize     int64  `json:"chunk_size"`
+	TotalParts    int    `json:"total_parts"`
+	UploadedParts []int  `json:"uploaded_parts"`
+	ExpireAt      int64  `json:"expire_at"`
+}
+
+// Initiate 申请分片上传，sha256 为整个文件的校验和，chunk_size 不传时使用默认值
+func (h *AttachmentHandler) Initiate(c *gin.Context) {
+	type Req struct {
+		ArticleID int64  `json:"article_id"`
+		Filename  string `json:"filename"`
+		Size      int64  `json:"size"`
+		SHA256    string `json:"sha256"`
+		ChunkSize int64  `json:"chunk_size"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ArticleID <= 0 || req.Filename == "" || req.Size <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "article_id, filename and size are required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	u, err := h.svc.InitiateUpload(c, uid, req.ArticleID, req.Filename, req.Size, req.SHA256, req.ChunkSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toChunkUploadVO(u)})
+}
+
+// UploadPart 上传分片，分片序号从 1 开始，分片的 SHA-256 放在 X-Content-SHA256 请求头中
+func (h *AttachmentHandler) UploadPart(c *gin.Context) {
+	part, err := strconv.Atoi(c.Param("part"))
+	if err != nil || part <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid part number"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	err = h.svc.UploadPart(c, uid, c.Param("id"), part, c.Request.Body, c.GetHeader(PartChecksumHeader))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Status 获取上传任务和已上传的分片
+func (h *AttachmentHandler) Status(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	u, err := h.svc.UploadStatus(c, uid, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toChunkUploadVO(u)})
+}
+
+// Complete 合并分片，返回附件
+func (h *AttachmentHandler) Complete(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	a, err := h.svc.CompleteUpload(c, uid, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toAttachmentVO(a)})
+}
+
+// Abort 放弃上传
+func (h *AttachmentHandler) Abort(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.AbortUpload(c, uid, c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// List 获取文章的附件
+func (h *AttachmentHandler) List(c *gin.Context) {
+	articleID, err := strconv.ParseInt(c.Query("article_id"), 10, 64)
+	if err != nil || articleID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid article id"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.svc.List(c, uid, articleID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	vos := make([]AttachmentVO, 0, len(attachments))
+	for _, a := range attachments {
+		vos = append(vos, toAttachmentVO(a))
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: vos})
+}
+
+// Delete 删除附件
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	type Req struct {
+		ID int64 `json:"id"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "id is required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(c, uid, req.ID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+func (h *AttachmentHandler) userID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
+
+func (h *AttachmentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAttachment), errors.Is(err, service.ErrInvalidChecksum),
+		errors.Is(err, service.ErrInvalidPart), errors.Is(err, service.ErrChecksumMismatch),
+		errors.Is(err, service.ErrChunkUploadIncomplete):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	case errors.Is(err, service.ErrUnsupportedAttachmentType):
+		c.JSON(http.StatusUnsupportedMediaType, Result{Code: 415, Msg: err.Error()})
+	case errors.Is(err, service.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, Result{Code: 413, Msg: err.Error()})
+	case errors.Is(err, service.ErrChunkUploadInProgress):
+		c.JSON(http.StatusConflict, Result{Code: 409, Msg: err.Error()})
+	case errors.Is(err, service.ErrArticlePermissionDenied):
+		c.JSON(http.StatusForbidden, Result{Code: 403, Msg: err.Error()})
+	case errors.Is(err, repository.ErrChunkUploadNotFound):
+		c.JSON(http.StatusNotFound, Result{Code: 404, Msg: "upload not found or expired"})
+	case errors.Is(err, repository.ErrArticleNotFound), errors.Is(err, repository.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, Result{Code: 404, Msg: "not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+func toAttachmentVO(a domain.Attachment) AttachmentVO {
+	return AttachmentVO{
+		ID:        a.ID,
+		ArticleID: a.ArticleID,
+		Filename:  a.Filename,
+		URL:       a.URL,
+		Size:      a.Size,
+		MimeType:  a.MimeType,
+		SHA256:    a.SHA256,
+		Ctime:     a.Ctime.UnixMilli(),
+	}
+}
+
+func toChunkUploadVO(u domain.ChunkUpload) ChunkUploadVO {
+	uploaded := make([]int, 0, len(u.Parts))
+	for n := 1; n <= u.TotalParts; n++ {
+		if _, ok := u.Parts[n]; ok {
+			uploaded = append(uploaded, n)
+		}
+	}
+	return ChunkUploadVO{
+		UploadID:      u.ID,
+		ArticleID:     u.ArticleID,
+		Filename:      u.Filename,
+		Size:          u.Size,
+		ChunkSize:     u.ChunkSize,
+		TotalParts:    u.TotalParts,
+		UploadedParts: uploaded,
+		ExpireAt:      u.ExpireAt.UnixMilli(),
+	}
+}
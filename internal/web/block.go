@@ -0,0 +1,179 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// BlockHandler 拉黑和屏蔽处理器
+type BlockHandler struct {
+	svc service.BlockServiceInterface
+}
+
+// NewBlockHandler 创建拉黑和屏蔽处理器
+func NewBlockHandler(svc service.BlockServiceInterface) *BlockHandler {
+	return &BlockHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *BlockHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/blocks")
+	g.POST("", h.Block)         // 拉黑用户
+	g.DELETE("/:id", h.Unblock) // 取消拉黑
+	g.GET("", h.List)           // 我拉黑的人
+
+	mg := server.Group("/mutes")
+	mg.POST("", h.Mute)         // 屏蔽用户
+	mg.DELETE("/:id", h.Unmute) // 取消屏蔽
+	mg.GET("", h.ListMuted)     // 我屏蔽的人
+}
+
+// BlockVO 拉黑或屏蔽记录VO
+type BlockVO struct {
+	UserID int64 `json:"user_id"` // 被拉黑或屏蔽的用户
+	Ctime  int64 `json:"ctime"`
+}
+
+// Block 拉黑用户
+func (h *BlockHandler) Block(c *gin.Context) {
+	type Req struct {
+		UserID int64 `json:"user_id"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	err := h.svc.Block(c, uid, req.UserID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+	case errors.Is(err, service.ErrInvalidBlock):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+// Unblock 取消拉黑
+func (h *BlockHandler) Unblock(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	target, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || target <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid user id"})
+		return
+	}
+	if err := h.svc.Unblock(c, uid, target); err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// List 获取我拉黑的人
+func (h *BlockHandler) List(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	offset, limit := extractPaginationParams(c)
+	blocks, err := h.svc.List(c, uid, int(offset), int(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	vos := make([]BlockVO, 0, len(blocks))
+	for _, b := range blocks {
+		vos = append(vos, BlockVO{UserID: b.BlockedID, Ctime: b.Ctime})
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: vos})
+}
+
+// Mute 屏蔽用户
+func (h *BlockHandler) Mute(c *gin.Context) {
+	type Req struct {
+		UserID int64 `json:"user_id"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	err := h.svc.Mute(c, uid, req.UserID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+	case errors.Is(err, service.ErrInvalidBlock):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+// Unmute 取消屏蔽
+func (h *BlockHandler) Unmute(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	target, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || target <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid user id"})
+		return
+	}
+	if err := h.svc.Unmute(c, uid, target); err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// ListMuted 获取我屏蔽的人
+func (h *BlockHandler) ListMuted(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	offset, limit := extractPaginationParams(c)
+	mutes, err := h.svc.ListMuted(c, uid, int(offset), int(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	vos := make([]BlockVO, 0, len(mutes))
+	for _, m := range mutes {
+		vos = append(vos, BlockVO{UserID: m.MutedID, Ctime: m.Ctime})
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: vos})
+}
+
+func (h *BlockHandler) userID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
@@ -0,0 +1,304 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CollectionHandler 收藏夹处理器
+type CollectionHandler struct {
+	svc service.CollectionServiceInterface
+}
+
+// NewCollectionHandler 创建收藏夹处理器
+func NewCollectionHandler(svc service.CollectionServiceInterface) *CollectionHandler {
+	return &CollectionHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由，收藏夹ID为 0 表示自己的默认收藏夹
+func (h *CollectionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/collections")
+	g.POST("", h.Create)            // 创建收藏夹
+	g.GET("", h.List)               // 用户的收藏夹，默认是自己的
+	g.GET("/:id", h.Get)            // 收藏夹详情
+	g.PUT("/:id", h.Update)         // 修改收藏夹
+	g.DELETE("/:id", h.Delete)      // 删除收藏夹
+	g.GET("/:id/items", h.Items)    // 收藏夹中的文章
+	g.POST("/:id/items", h.AddItem) // 收藏文章到收藏夹
+	g.POST("/:id/move", h.MoveItem) // 把已收藏的文章移到收藏夹
+}
+
+// CollectionVO 收藏夹VO
+type CollectionVO struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	Uid         int64  `json:"uid"`
+	ItemCnt     int64  `json:"item_cnt"`
+	Ctime       int64  `json:"ctime"`
+	Utime       int64  `json:"utime"`
+}
+
+// CollectionItemVO 收藏夹中的一篇文章
+type CollectionItemVO struct {
+	ID           int64     `json:"id"`
+	CollectionID int64     `json:"collection_id"`
+	Ctime        int64     `json:"ctime"` // 收藏时间
+	Article      ArticleV0 `json:"article"`
+}
+
+type collectionReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+}
+
+// Create 创建收藏夹
+func (h *CollectionHandler) Create(c *gin.Context) {
+	var req collectionReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	id, err := h.svc.Create(c, domain.Collection{
+		Name:        req.Name,
+		Description: req.Description,
+		Public:      req.Public,
+		Uid:         uid,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{"collection_id": id}})
+}
+
+// List 获取用户的收藏夹，uid 为空时获取自己的，查看别人的只返回公开的收藏夹
+func (h *CollectionHandler) List(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	owner := uid
+	if s := c.Query("uid"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid user id"})
+			return
+		}
+		owner = id
+	}
+	offset, limit := extractPaginationParams(c)
+	collections, err := h.svc.List(c, owner, uid, int(offset), int(limit))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	vos := make([]CollectionVO, 0, len(collections))
+	for _, col := range collections {
+		vos = append(vos, toCollectionVO(col))
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: vos})
+}
+
+// Get 获取收藏夹详情
+func (h *CollectionHandler) Get(c *gin.Context) {
+	id, ok := h.collectionID(c, false)
+	if !ok {
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	col, err := h.svc.Get(c, id, uid)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toCollectionVO(col)})
+}
+
+// Update 修改收藏夹的名称、描述和公开状态
+func (h *CollectionHandler) Update(c *gin.Context) {
+	var req collectionReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	id, ok := h.collectionID(c, false)
+	if !ok {
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	err := h.svc.Update(c, domain.Collection{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Public:      req.Public,
+		Uid:         uid,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Delete 删除收藏夹，其中的文章移到默认收藏夹
+func (h *CollectionHandler) Delete(c *gin.Context) {
+	id, ok := h.collectionID(c, false)
+	if !ok {
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c, uid, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Items 获取收藏夹中的文章，cursor 为上一页返回的 next_cursor
+func (h *CollectionHandler) Items(c *gin.Context) {
+	id, ok := h.collectionID(c, true)
+	if !ok {
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	cursor, _ := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	items, next, err := h.svc.Items(c, id, uid, cursor, limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	vos := make([]CollectionItemVO, 0, len(items))
+	for _, item := range items {
+		// 列表中不返回正文
+		av := toArticleVO(item.Article)
+		av.Content = ""
+		av.HTML = ""
+		vos = append(vos, CollectionItemVO{
+			ID:           item.ID,
+			CollectionID: item.CollectionID,
+			Ctime:        item.Ctime.UnixMilli(),
+			Article:      av,
+		})
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{
+		"items":       vos,
+		"next_cursor": next,
+	}})
+}
+
+// AddItem 收藏文章到收藏夹
+func (h *CollectionHandler) AddItem(c *gin.Context) {
+	h.changeItem(c, h.svc.AddItem)
+}
+
+// MoveItem 把已收藏的文章移到收藏夹
+func (h *CollectionHandler) MoveItem(c *gin.Context) {
+	h.changeItem(c, func(ctx context.Context, uid, cid, articleID int64) error {
+		return h.svc.MoveItem(ctx, uid, articleID, cid)
+	})
+}
+
+func (h *CollectionHandler) changeItem(c *gin.Context,
+	fn func(ctx context.Context, uid, cid, articleID int64) error) {
+	type Req struct {
+		ArticleID int64 `json:"article_id"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ArticleID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "article_id is required"})
+		return
+	}
+	cid, ok := h.collectionID(c, true)
+	if !ok {
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	if err := fn(c, uid, cid, req.ArticleID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// collectionID 解析路径中的收藏夹ID，allowDefault 为 true 时允许默认收藏夹
+func (h *CollectionHandler) collectionID(c *gin.Context, allowDefault bool) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 || (id == domain.DefaultCollectionID && !allowDefault) {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid collection id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *CollectionHandler) userID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
+
+func (h *CollectionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCollection):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	case errors.Is(err, service.ErrCollectionNotFound), errors.Is(err, service.ErrCollectionItemNotFound),
+		errors.Is(err, service.ErrArticleUnavailable):
+		c.JSON(http.StatusNotFound, Result{Code: 404, Msg: err.Error()})
+	case errors.Is(err, service.ErrCollectedInOtherCollection):
+		c.JSON(http.StatusConflict, Result{Code: 409, Msg: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+func toCollectionVO(c domain.Collection) CollectionVO {
+	return CollectionVO{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		Public:      c.Public,
+		Uid:         c.Uid,
+		ItemCnt:     c.ItemCnt,
+		Ctime:       c.Ctime.UnixMilli(),
+		Utime:       c.Utime.UnixMilli(),
+	}
+}
@@ -32,11 +32,14 @@ func NewCommentHandler(svc service.CommentService) *CommentHandler {
 func (h *CommentHandler) RegisterRoutes(server *gin.Engine) {
 	g := server.Group("/comments")
 	g.POST("", h.CreateComment)
+	g.PUT("/:id", h.EditComment)
 	g.DELETE("/:id", h.DeleteComment)
+	g.GET("/:id/history", h.GetCommentHistory)
 	// GET /articles/456?min_id=10&limit=15 HTTP/1.1
 	g.GET("/articles/:articleId", h.GetArticleComments)
 	g.GET("/:id/children", h.GetChildrenComments)
 	g.GET("/hot/articles/:articleId", h.GetHotComments)
+	g.POST("/:id/like", h.LikeComment)
 }
 
 // 创建评论的请求参数
@@ -91,6 +94,20 @@ func (h *CommentHandler) CreateComment(ctx *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrUserBlocked) {
+			ctx.JSON(http.StatusForbidden, Result{
+				Code: 403,
+				Msg:  err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrArticleUnavailable) {
+			ctx.JSON(http.StatusNotFound, Result{
+				Code: 404,
+				Msg:  "文章不存在",
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
 			Msg:  "系统错误",
@@ -102,6 +119,115 @@ func (h *CommentHandler) CreateComment(ctx *gin.Context) {
 	})
 }
 
+// EditCommentReq 编辑评论的请求参数
+type EditCommentReq struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// EditComment 编辑评论
+func (h *CommentHandler) EditComment(ctx *gin.Context) {
+	// 获取用户ID
+	userID, ok := ctx.Get("userID")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	commentID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "无效的评论ID",
+		})
+		return
+	}
+	var req EditCommentReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "invalid request",
+		})
+		return
+	}
+
+	comment, err := h.svc.EditComment(ctx, commentID, userID.(int64), req.Content)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidComment):
+			ctx.JSON(http.StatusBadRequest, Result{
+				Code: 400,
+				Msg:  "评论内容不能为空",
+			})
+		case errors.Is(err, service.ErrCommentNotFound):
+			ctx.JSON(http.StatusNotFound, Result{
+				Code: 404,
+				Msg:  "评论不存在",
+			})
+		case errors.Is(err, service.ErrCommentPermissionDenied):
+			ctx.JSON(http.StatusForbidden, Result{
+				Code: 403,
+				Msg:  "只能编辑自己的评论",
+			})
+		case errors.Is(err, service.ErrCommentEditExpired):
+			ctx.JSON(http.StatusForbidden, Result{
+				Code: 403,
+				Msg:  "评论已超过可编辑时间",
+			})
+		default:
+			ctx.JSON(http.StatusInternalServerError, Result{
+				Code: 500,
+				Msg:  "系统错误",
+			})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: toCommentResp(comment),
+	})
+}
+
+// CommentRevisionResp 评论历史版本响应
+type CommentRevisionResp struct {
+	Content string `json:"content"`
+	Ctime   int64  `json:"ctime"`
+}
+
+// GetCommentHistory 获取评论的历史版本
+func (h *CommentHandler) GetCommentHistory(ctx *gin.Context) {
+	commentID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "无效的评论ID",
+		})
+		return
+	}
+	history, err := h.svc.GetCommentHistory(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, service.ErrCommentNotFound) {
+			ctx.JSON(http.StatusNotFound, Result{
+				Code: 404,
+				Msg:  "评论不存在",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	resp := make([]CommentRevisionResp, 0, len(history))
+	for _, r := range history {
+		resp = append(resp, CommentRevisionResp{
+			Content: r.Content,
+			Ctime:   r.Ctime,
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: resp,
+	})
+}
+
 // DeleteComment 删除评论
 func (h *CommentHandler) DeleteComment(ctx *gin.Context) {
 	// 获取用户ID
@@ -129,6 +255,13 @@ func (h *CommentHandler) DeleteComment(ctx *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrCommentPermissionDenied) {
+			ctx.JSON(http.StatusForbidden, Result{
+				Code: 403,
+				Msg:  "没有权限删除该评论",
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
 			Msg:  "系统错误",
@@ -142,14 +275,48 @@ func (h *CommentHandler) DeleteComment(ctx *gin.Context) {
 
 // CommentResp 评论响应
 type CommentResp struct {
-	ID       int64         `json:"id"`
-	Content  string        `json:"content"`
-	UserID   int64         `json:"user_id"`
-	UserName string        `json:"user_name"`
-	ParentID int64         `json:"parent_id,omitempty"`
-	RootID   int64         `json:"root_id,omitempty"`
-	Ctime    int64         `json:"ctime"`
-	Children []CommentResp `json:"children,omitempty"`
+	ID       int64            `json:"id"`
+	Content  string           `json:"content"`
+	HTML     string           `json:"html"` // 提及的用户渲染为链接之后的 HTML
+	Mentions []domain.Mention `json:"mentions,omitempty"`
+	UserID   int64            `json:"user_id"`
+	UserName string           `json:"user_name"`
+	ParentID int64            `json:"parent_id,omitempty"`
+	RootID   int64            `json:"root_id,omitempty"`
+	Ctime    int64            `json:"ctime"`
+	LikeCnt  int64            `json:"like_cnt"`
+	Liked    bool             `json:"liked"`
+	Edited   bool             `json:"edited"`
+	Utime    int64            `json:"utime,omitempty"`   // 最后一次编辑的时间
+	Deleted  bool             `json:"deleted,omitempty"` // 已删除的评论只作为占位展示
+	Children []CommentResp    `json:"children,omitempty"`
+}
+
+func toCommentResp(comment domain.Comment) CommentResp {
+	resp := CommentResp{
+		ID:       comment.ID,
+		Content:  comment.Content,
+		HTML:     comment.RenderHTML(),
+		Mentions: comment.Mentions,
+		UserID:   comment.UserID,
+		UserName: comment.UserName,
+		ParentID: comment.ParentID,
+		RootID:   comment.RootID,
+		Ctime:    comment.Ctime,
+		LikeCnt:  comment.LikeCnt,
+		Liked:    comment.Liked,
+		Edited:   comment.IsEdited(),
+		Utime:    comment.Utime,
+		Deleted:  comment.IsDeleted(),
+	}
+	// 处理子评论
+	if len(comment.Children) > 0 {
+		resp.Children = make([]CommentResp, 0, len(comment.Children))
+		for _, child := range comment.Children {
+			resp.Children = append(resp.Children, toCommentResp(*child))
+		}
+	}
+	return resp
 }
 
 // GetArticleComments 获取文章评论
@@ -194,7 +361,9 @@ func (h *CommentHandler) GetArticleComments(ctx *gin.Context) {
 	}
 
 	// 获取评论列表
-	comments, err := h.svc.GetComments(ctx, "article", articleId, minID, limit)
+	userID, _ := ctx.Get("userID")
+	uid, _ := userID.(int64)
+	comments, err := h.svc.GetComments(ctx, "article", articleId, minID, limit, uid)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
@@ -206,33 +375,7 @@ func (h *CommentHandler) GetArticleComments(ctx *gin.Context) {
 	// 转换为响应格式
 	resp := make([]CommentResp, 0, len(comments))
 	for _, comment := range comments {
-		commentResp := CommentResp{
-			ID:       comment.ID,
-			Content:  comment.Content,
-			UserID:   comment.UserID,
-			UserName: comment.UserName,
-			ParentID: comment.ParentID,
-			RootID:   comment.RootID,
-			Ctime:    comment.Ctime,
-		}
-		// 处理子评论
-		if len(comment.Children) > 0 {
-			children := make([]CommentResp, 0, len(comment.Children))
-			for _, child := range comment.Children {
-				children = append(children, CommentResp{
-					ID:       child.ID,
-					Content:  child.Content,
-					UserID:   child.UserID,
-					UserName: child.UserName,
-					ParentID: child.ParentID,
-					RootID:   child.RootID,
-					Ctime:    child.Ctime,
-				})
-			}
-			commentResp.Children = children
-		}
-
-		resp = append(resp, commentResp)
+		resp = append(resp, toCommentResp(comment))
 	}
 
 	ctx.JSON(http.StatusOK, Result{
@@ -280,7 +423,9 @@ func (h *CommentHandler) GetChildrenComments(ctx *gin.Context) {
 		limit = int(limit64)
 	}
 	// 获取子评论列表
-	childrenComments, err := h.svc.GetChildrenComments(ctx, commentID, minID, limit)
+	userID, _ := ctx.Get("userID")
+	uid, _ := userID.(int64)
+	childrenComments, err := h.svc.GetChildrenComments(ctx, commentID, minID, limit, uid)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
@@ -291,15 +436,7 @@ func (h *CommentHandler) GetChildrenComments(ctx *gin.Context) {
 	// 转换为响应格式
 	resp := make([]CommentResp, len(childrenComments))
 	for i, child := range childrenComments {
-		resp[i] = CommentResp{
-			ID:       child.ID,
-			Content:  child.Content,
-			UserID:   child.UserID,
-			UserName: child.UserName,
-			Ctime:    child.Ctime,
-			ParentID: child.ParentID,
-			RootID:   child.RootID,
-		}
+		resp[i] = toCommentResp(child)
 	}
 
 	ctx.JSON(http.StatusOK, Result{
@@ -320,7 +457,9 @@ func (h *CommentHandler) GetHotComments(ctx *gin.Context) {
 		return
 	}
 	// 获取热门评论列表
-	hotComments, err := h.svc.GetHotComments(ctx, "article", articleId, 3)
+	userID, _ := ctx.Get("userID")
+	uid, _ := userID.(int64)
+	hotComments, err := h.svc.GetHotComments(ctx, "article", articleId, 3, uid)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
@@ -331,34 +470,67 @@ func (h *CommentHandler) GetHotComments(ctx *gin.Context) {
 	// 转换为响应格式
 	resp := make([]CommentResp, len(hotComments))
 	for i, hotComment := range hotComments {
-		resp[i] = CommentResp{
-			ID:       hotComment.ID,
-			Content:  hotComment.Content,
-			UserID:   hotComment.UserID,
-			UserName: hotComment.UserName,
-			Ctime:    hotComment.Ctime,
-			ParentID: hotComment.ParentID,
-			RootID:   hotComment.RootID,
-		}
-		// 处理子评论
-		if len(hotComment.Children) > 0 {
-			children := make([]CommentResp, 0, len(hotComment.Children))
-			for _, child := range hotComment.Children {
-				children = append(children, CommentResp{
-					ID:       child.ID,
-					Content:  child.Content,
-					UserID:   child.UserID,
-					UserName: child.UserName,
-					ParentID: child.ParentID,
-					RootID:   child.RootID,
-					Ctime:    child.Ctime,
-				})
-			}
-			resp[i].Children = children
-		}
+		resp[i] = toCommentResp(hotComment)
 	}
 
 	ctx.JSON(http.StatusOK, Result{
 		Data: resp,
 	})
 }
+
+// LikeCommentReq 点赞评论的请求参数
+type LikeCommentReq struct {
+	Like bool `json:"like"`
+}
+
+// LikeComment 点赞或取消点赞评论
+func (h *CommentHandler) LikeComment(ctx *gin.Context) {
+	// 获取用户ID
+	userID, ok := ctx.Get("userID")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	commentID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "无效的评论ID",
+		})
+		return
+	}
+	var req LikeCommentReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "invalid request",
+		})
+		return
+	}
+
+	err = h.svc.LikeComment(ctx, commentID, userID.(int64), req.Like)
+	if err != nil {
+		if errors.Is(err, service.ErrCommentNotFound) {
+			ctx.JSON(http.StatusNotFound, Result{
+				Code: 404,
+				Msg:  "评论不存在",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrUserBlocked) {
+			ctx.JSON(http.StatusForbidden, Result{
+				Code: 403,
+				Msg:  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: gin.H{"liked": req.Like},
+	})
+}
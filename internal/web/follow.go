@@ -1,6 +1,7 @@
 package web
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -48,6 +49,10 @@ func (h *FollowHandler) Follow(c *gin.Context) {
 
 	// 关注用户
 	if err := h.svc.Follow(c, userID.(int64), req.Followee); err != nil {
+		if errors.Is(err, service.ErrUserBlocked) {
+			c.JSON(403, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(500, gin.H{"error": "Failed to follow user"})
 		return
 	}
@@ -0,0 +1,242 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// MessageHandler 私信处理器
+type MessageHandler struct {
+	svc service.MessageServiceInterface
+}
+
+// NewMessageHandler 创建私信处理器
+func NewMessageHandler(svc service.MessageServiceInterface) *MessageHandler {
+	return &MessageHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *MessageHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/messages")
+	g.POST("", h.Send)                              // 发送私信
+	g.GET("/conversations", h.Conversations)        // 会话列表
+	g.GET("/conversations/:peer", h.History)        // 和某个用户的私信记录
+	g.POST("/conversations/:peer/read", h.MarkRead) // 标记会话为已读
+	g.GET("/unread_count", h.UnreadCount)           // 未读私信数
+	g.GET("/settings", h.Settings)                  // 获取私信设置
+	g.PUT("/settings", h.SetPolicy)                 // 设置谁可以给我发私信
+}
+
+// MessageVO 私信VO
+type MessageVO struct {
+	ID         int64  `json:"id"`
+	SenderID   int64  `json:"sender_id"`
+	ReceiverID int64  `json:"receiver_id"`
+	Content    string `json:"content"`
+	Ctime      int64  `json:"ctime"`
+}
+
+// ConversationVO 会话VO
+type ConversationVO struct {
+	Peer        AuthorVO  `json:"peer"`
+	LastMessage MessageVO `json:"last_message"`
+	Unread      int64     `json:"unread"`
+	Utime       int64     `json:"utime"`
+}
+
+// Send 发送私信
+func (h *MessageHandler) Send(c *gin.Context) {
+	type Req struct {
+		To      int64  `json:"to"`
+		Content string `json:"content"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.To <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	msg, err := h.svc.Send(c, uid, req.To, req.Content)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toMessageVO(msg)})
+}
+
+// Conversations 获取会话列表
+func (h *MessageHandler) Conversations(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	offset, limit := extractPaginationParams(c)
+	convs, err := h.svc.Conversations(c, uid, int(offset), int(limit))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	vos := make([]ConversationVO, 0, len(convs))
+	for _, conv := range convs {
+		vos = append(vos, ConversationVO{
+			Peer:        AuthorVO{ID: conv.Peer.ID, Name: conv.Peer.Name},
+			LastMessage: toMessageVO(conv.LastMessage),
+			Unread:      conv.Unread,
+			Utime:       conv.Utime,
+		})
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: vos})
+}
+
+// History 获取和某个用户的私信记录，before 为上一页返回的 next_cursor
+func (h *MessageHandler) History(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	peerID, err := strconv.ParseInt(c.Param("peer"), 10, 64)
+	if err != nil || peerID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid user id"})
+		return
+	}
+	before, _ := strconv.ParseInt(c.DefaultQuery("before", "0"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	msgs, err := h.svc.History(c, uid, peerID, before, limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	vos := make([]MessageVO, 0, len(msgs))
+	for _, msg := range msgs {
+		vos = append(vos, toMessageVO(msg))
+	}
+	// 最后一条是本页最早的私信，作为下一页的游标
+	var next int64
+	if len(msgs) > 0 {
+		next = msgs[len(msgs)-1].ID
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{
+		"messages":    vos,
+		"next_cursor": next,
+	}})
+}
+
+// MarkRead 标记和某个用户的会话为已读
+func (h *MessageHandler) MarkRead(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	peerID, err := strconv.ParseInt(c.Param("peer"), 10, 64)
+	if err != nil || peerID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid user id"})
+		return
+	}
+	if err := h.svc.MarkRead(c, uid, peerID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// UnreadCount 获取未读私信数
+func (h *MessageHandler) UnreadCount(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	cnt, err := h.svc.UnreadCount(c, uid)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{"unread": cnt}})
+}
+
+// Settings 获取私信设置
+func (h *MessageHandler) Settings(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	policy, err := h.svc.Policy(c, uid)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{"policy": policy.String()}})
+}
+
+// SetPolicy 设置谁可以给我发私信：everyone、following 或 nobody
+func (h *MessageHandler) SetPolicy(c *gin.Context) {
+	type Req struct {
+		Policy string `json:"policy"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	policy, valid := domain.ParseDMPolicy(req.Policy)
+	if !valid {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "policy 只能是 everyone、following 或 nobody"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.SetPolicy(c, uid, policy); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+func (h *MessageHandler) userID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
+
+func (h *MessageHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMessage), errors.Is(err, service.ErrMessageToSelf):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	case errors.Is(err, service.ErrMessageReceiverNotFound):
+		c.JSON(http.StatusNotFound, Result{Code: 404, Msg: err.Error()})
+	case errors.Is(err, service.ErrMessageNotAllowed):
+		c.JSON(http.StatusForbidden, Result{Code: 403, Msg: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+func toMessageVO(msg domain.Message) MessageVO {
+	return MessageVO{
+		ID:         msg.ID,
+		SenderID:   msg.SenderID,
+		ReceiverID: msg.ReceiverID,
+		Content:    msg.Content,
+		Ctime:      msg.Ctime,
+	}
+}
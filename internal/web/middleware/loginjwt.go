@@ -10,7 +10,9 @@ import (
 )
 
 type LoginMiddlewareJWT struct {
-	paths []string
+	paths      []string
+	optional   []func(c *gin.Context) bool
+	queryPaths []string
 }
 type Config struct {
 	Secret string `yaml:"secret"`
@@ -26,6 +28,19 @@ func (b *LoginMiddlewareJWT) IgnorePaths(paths ...string) *LoginMiddlewareJWT {
 	return b
 }
 
+// OptionalIf 满足条件的请求可以不登录访问，携带了token时仍然解析出用户ID
+func (b *LoginMiddlewareJWT) OptionalIf(fn func(c *gin.Context) bool) *LoginMiddlewareJWT {
+	b.optional = append(b.optional, fn)
+	return b
+}
+
+// AllowQueryToken 这些路径可以通过 access_token 查询参数传递token
+// 浏览器的 EventSource 不能设置请求头，SSE 连接只能把token放在地址中
+func (b *LoginMiddlewareJWT) AllowQueryToken(paths ...string) *LoginMiddlewareJWT {
+	b.queryPaths = append(b.queryPaths, paths...)
+	return b
+}
+
 // loginMiddleware 中间件函数
 func (b *LoginMiddlewareJWT) Build() gin.HandlerFunc {
 	cfg := Config{
@@ -50,6 +65,12 @@ func (b *LoginMiddlewareJWT) Build() gin.HandlerFunc {
 
 		// 获取请求头中的Authorization字段
 		token := c.Request.Header.Get("Authorization")
+		if token == "" {
+			token = b.queryToken(c)
+		}
+		if token == "" && b.isOptional(c) {
+			return
+		}
 		if token == "" {
 			c.JSON(401, gin.H{"error": "未登录或无效的token"})
 			return
@@ -89,3 +110,27 @@ func (b *LoginMiddlewareJWT) Build() gin.HandlerFunc {
 		}
 	}
 }
+
+// queryToken 从查询参数中获取token，转换为和请求头相同的格式
+func (b *LoginMiddlewareJWT) queryToken(c *gin.Context) string {
+	token := c.Query("access_token")
+	if token == "" {
+		return ""
+	}
+	for _, p := range b.queryPaths {
+		if c.Request.URL.Path == p {
+			return "Bearer " + token
+		}
+	}
+	return ""
+}
+
+// isOptional 请求是否允许不登录访问
+func (b *LoginMiddlewareJWT) isOptional(c *gin.Context) bool {
+	for _, fn := range b.optional {
+		if fn(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 站内通知处理器
+type NotificationHandler struct {
+	svc service.NotificationServiceInterface
+}
+
+// NewNotificationHandler 创建站内通知处理器
+func NewNotificationHandler(svc service.NotificationServiceInterface) *NotificationHandler {
+	return &NotificationHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *NotificationHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/notifications")
+	g.GET("", h.List)                     // 通知列表
+	g.GET("/unread_count", h.UnreadCount) // 未读通知数
+	g.POST("/:id/read", h.MarkRead)       // 标记通知为已读
+	g.POST("/read_all", h.MarkAllRead)    // 标记所有通知为已读
+	g.GET("/settings", h.Settings)        // 获取免打扰设置
+	g.PUT("/settings", h.SetMuted)        // 关闭或打开某类通知
+}
+
+// NotificationVO 通知VO
+type NotificationVO struct {
+	ID        int64    `json:"id"`
+	Type      string   `json:"type"`
+	Biz       string   `json:"biz"`
+	BizID     int64    `json:"biz_id"`
+	ArticleID int64    `json:"article_id,omitempty"`
+	Actor     AuthorVO `json:"actor"`     // 最近一次触发通知的用户
+	ActorCnt  int64    `json:"actor_cnt"` // 触发通知的人数，大于 1 时展示为 "xx 等 n 人"
+	Content   string   `json:"content,omitempty"`
+	Amount    int64    `json:"amount,omitempty"` // 打赏金额，单位为分
+	Read      bool     `json:"read"`
+	Ctime     int64    `json:"ctime"`
+	Utime     int64    `json:"utime"`
+}
+
+// List 获取通知列表
+func (h *NotificationHandler) List(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	offset, limit := extractPaginationParams(c)
+	notifications, err := h.svc.List(c, uid, int(offset), int(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	vos := make([]NotificationVO, 0, len(notifications))
+	for _, n := range notifications {
+		vos = append(vos, toNotificationVO(n))
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: vos})
+}
+
+// UnreadCount 获取未读通知数
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	cnt, err := h.svc.UnreadCount(c, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{"unread": cnt}})
+}
+
+// MarkRead 标记通知为已读
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid notification id"})
+		return
+	}
+	if err := h.svc.MarkRead(c, uid, id); err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// MarkAllRead 标记所有通知为已读
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.MarkAllRead(c, uid); err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Settings 获取每种通知是否关闭
+func (h *NotificationHandler) Settings(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	settings, err := h.svc.Settings(c, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{"muted": settings}})
+}
+
+// SetMuted 关闭或打开某类通知
+func (h *NotificationHandler) SetMuted(c *gin.Context) {
+	type Req struct {
+		Type  string `json:"type"`
+		Muted bool   `json:"muted"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	err := h.svc.SetMuted(c, uid, req.Type, req.Muted)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+	case errors.Is(err, service.ErrInvalidNotificationType):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+func (h *NotificationHandler) userID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
+
+func toNotificationVO(n domain.Notification) NotificationVO {
+	return NotificationVO{
+		ID:        n.ID,
+		Type:      n.Type,
+		Biz:       n.Biz,
+		BizID:     n.BizID,
+		ArticleID: n.ArticleID,
+		Actor:     AuthorVO{ID: n.Actor.ID, Name: n.Actor.Name},
+		ActorCnt:  n.ActorCnt,
+		Content:   n.Content,
+		Amount:    n.Amount,
+		Read:      n.Read,
+		Ctime:     n.Ctime,
+		Utime:     n.Utime,
+	}
+}
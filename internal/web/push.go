@@ -0,0 +1,74 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/events/push"
+	"github.com/gin-gonic/gin"
+)
+
+// PushStreamPath SSE 连接的地址，允许通过 access_token 查询参数登录
+const PushStreamPath = "/push/stream"
+
+// 心跳间隔，避免连接被代理因为空闲而断开
+const pushHeartbeatInterval = 30 * time.Second
+
+// PushHandler 通过 SSE 向在线用户推送新通知和新 Feed
+type PushHandler struct {
+	sub push.Subscriber
+}
+
+// NewPushHandler 创建推送处理器
+func NewPushHandler(sub push.Subscriber) *PushHandler {
+	return &PushHandler{
+		sub: sub,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *PushHandler) RegisterRoutes(server *gin.Engine) {
+	server.GET(PushStreamPath, h.Stream) // 建立 SSE 连接
+}
+
+// Stream 保持 SSE 连接，事件名为消息类型，数据为消息内容
+func (h *PushHandler) Stream(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return
+	}
+
+	msgs, cancel := h.sub.Subscribe(uid)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// 关闭 Nginx 的响应缓冲，消息才能立即送达
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(pushHeartbeatInterval)
+	defer ticker.Stop()
+	c.SSEvent("ready", gin.H{"user_id": uid})
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case msg := <-msgs:
+			c.SSEvent(msg.Type, msg.Data)
+			return true
+		case <-ticker.C:
+			// 以冒号开头的行是 SSE 注释，客户端会忽略
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
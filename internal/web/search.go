@@ -51,7 +51,7 @@ func (h *SearchHandler) SearchUsers(ctx *gin.Context) {
 	// 解析分页参数
 	page, pageSize := h.parsePagination(ctx)
 	// 调用服务层执行搜索逻辑
-	users, total, err := h.svc.SearchUsers(ctx, query, page, pageSize)
+	users, total, err := h.svc.SearchUsers(ctx, query, page, pageSize, ctx.GetInt64("userID"))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
@@ -83,7 +83,9 @@ func (h *SearchHandler) SearchUsers(ctx *gin.Context) {
 
 func (h *SearchHandler) SearchArticles(ctx *gin.Context) {
 	query := ctx.Query("query")
-	if query == "" {
+	// 可选的标签过滤
+	tag := ctx.Query("tag")
+	if query == "" && tag == "" {
 		ctx.JSON(http.StatusBadRequest, Result{
 			Code: 400,
 			Msg:  "Search query is required",
@@ -94,7 +96,7 @@ func (h *SearchHandler) SearchArticles(ctx *gin.Context) {
 	// 解析分页参数
 	page, pageSize := h.parsePagination(ctx)
 
-	articles, total, err := h.svc.SearchArticles(ctx, query, page, pageSize)
+	articles, total, err := h.svc.SearchArticles(ctx, query, tag, page, pageSize, ctx.GetInt64("userID"))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
@@ -147,7 +149,7 @@ func (h *SearchHandler) SearchArticlesByAuthor(ctx *gin.Context) {
 	// 解析分页参数
 	page, pageSize := h.parsePagination(ctx)
 
-	articles, total, err := h.svc.SearchArticlesByAuthor(ctx, query, authorID, page, pageSize)
+	articles, total, err := h.svc.SearchArticlesByAuthor(ctx, query, authorID, page, pageSize, ctx.GetInt64("userID"))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
@@ -0,0 +1,261 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesHandler 文章系列处理器
+type SeriesHandler struct {
+	svc service.SeriesServiceInterface
+}
+
+// NewSeriesHandler 创建文章系列处理器
+func NewSeriesHandler(svc service.SeriesServiceInterface) *SeriesHandler {
+	return &SeriesHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *SeriesHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/series")
+	g.POST("/create", h.Create)             // 创建系列
+	g.GET("/mine", h.ListMine)              // 我的系列
+	g.POST("/:id/add", h.AddArticle)        // 添加文章到系列
+	g.POST("/:id/remove", h.RemoveArticle)  // 从系列中移除文章
+	g.POST("/:id/reorder", h.Reorder)       // 调整系列中文章的顺序
+	server.GET("/pub/series/:id", h.Public) // 公开的系列详情
+}
+
+// SeriesVO 系列VO
+type SeriesVO struct {
+	ID          int64       `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	AuthorID    int64       `json:"author_id"`
+	Ctime       int64       `json:"ctime"`
+	Utime       int64       `json:"utime"`
+	Articles    []ArticleV0 `json:"articles,omitempty"`
+}
+
+// SeriesNavVO 文章在系列中的导航
+type SeriesNavVO struct {
+	SeriesID    int64            `json:"series_id"`
+	SeriesTitle string           `json:"series_title"`
+	Position    int              `json:"position"`
+	Total       int              `json:"total"`
+	Prev        *SeriesNavItemVO `json:"prev,omitempty"`
+	Next        *SeriesNavItemVO `json:"next,omitempty"`
+}
+
+// SeriesNavItemVO 上一篇/下一篇
+type SeriesNavItemVO struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// Create 创建系列
+func (h *SeriesHandler) Create(c *gin.Context) {
+	type Req struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	id, err := h.svc.Create(c, domain.Series{
+		Title:       req.Title,
+		Description: req.Description,
+		AuthorID:    uid,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: gin.H{"series_id": id}})
+}
+
+// ListMine 获取当前用户的系列
+func (h *SeriesHandler) ListMine(c *gin.Context) {
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	series, err := h.svc.ListByAuthor(c, uid)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	vos := make([]SeriesVO, 0, len(series))
+	for _, s := range series {
+		vos = append(vos, toSeriesVO(s, nil))
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: vos})
+}
+
+// AddArticle 添加文章到系列
+func (h *SeriesHandler) AddArticle(c *gin.Context) {
+	h.changeArticle(c, h.svc.AddArticle)
+}
+
+// RemoveArticle 从系列中移除文章
+func (h *SeriesHandler) RemoveArticle(c *gin.Context) {
+	h.changeArticle(c, h.svc.RemoveArticle)
+}
+
+func (h *SeriesHandler) changeArticle(c *gin.Context,
+	fn func(ctx context.Context, uid, seriesID, articleID int64) error) {
+	type Req struct {
+		ArticleID int64 `json:"article_id"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ArticleID <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "article_id is required"})
+		return
+	}
+	seriesID, ok := h.seriesID(c)
+	if !ok {
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := fn(c, uid, seriesID, req.ArticleID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Reorder 调整系列中文章的顺序
+func (h *SeriesHandler) Reorder(c *gin.Context) {
+	type Req struct {
+		ArticleIDs []int64 `json:"article_ids"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid request"})
+		return
+	}
+	seriesID, ok := h.seriesID(c)
+	if !ok {
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Reorder(c, uid, seriesID, req.ArticleIDs); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success"})
+}
+
+// Public 获取公开的系列详情，只包含已发布的文章
+func (h *SeriesHandler) Public(c *gin.Context) {
+	seriesID, ok := h.seriesID(c)
+	if !ok {
+		return
+	}
+	series, articles, err := h.svc.GetPublic(c, seriesID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, Result{Code: 200, Msg: "success", Data: toSeriesVO(series, articles)})
+}
+
+func (h *SeriesHandler) seriesID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: "invalid series id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *SeriesHandler) userID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Result{Code: 401, Msg: "unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
+
+func (h *SeriesHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSeries),
+		errors.Is(err, repository.ErrSeriesArticleExists),
+		errors.Is(err, repository.ErrSeriesArticleInvalid):
+		c.JSON(http.StatusBadRequest, Result{Code: 400, Msg: err.Error()})
+	case errors.Is(err, service.ErrSeriesNotFound), errors.Is(err, repository.ErrSeriesNotFound):
+		c.JSON(http.StatusNotFound, Result{Code: 404, Msg: "series not found"})
+	case errors.Is(err, service.ErrSeriesPermissionDenied):
+		c.JSON(http.StatusForbidden, Result{Code: 403, Msg: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, Result{Code: 500, Msg: "系统错误"})
+	}
+}
+
+func toSeriesVO(s domain.Series, articles []domain.Article) SeriesVO {
+	vo := SeriesVO{
+		ID:          s.ID,
+		Title:       s.Title,
+		Description: s.Description,
+		AuthorID:    s.AuthorID,
+		Ctime:       s.Ctime.UnixMilli(),
+		Utime:       s.Utime.UnixMilli(),
+	}
+	for _, a := range articles {
+		// 列表中不返回正文
+		av := toArticleVO(a)
+		av.Content = ""
+		av.HTML = ""
+		vo.Articles = append(vo.Articles, av)
+	}
+	return vo
+}
+
+func toSeriesNavVO(nav *domain.SeriesNav) *SeriesNavVO {
+	if nav == nil {
+		return nil
+	}
+	vo := &SeriesNavVO{
+		SeriesID:    nav.SeriesID,
+		SeriesTitle: nav.SeriesTitle,
+		Position:    nav.Position,
+		Total:       nav.Total,
+	}
+	if nav.Prev != nil {
+		vo.Prev = &SeriesNavItemVO{ID: nav.Prev.ID, Title: nav.Prev.Title}
+	}
+	if nav.Next != nil {
+		vo.Next = &SeriesNavItemVO{ID: nav.Next.ID, Title: nav.Next.Title}
+	}
+	return vo
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler 标签处理器
+type TagHandler struct {
+	svc service.TagServiceInterface
+}
+
+// NewTagHandler 创建标签处理器
+func NewTagHandler(svc service.TagServiceInterface) *TagHandler {
+	return &TagHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *TagHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/tags")
+	g.GET("/trending", h.Trending)           // 热门标签
+	g.GET("/suggest", h.Suggest)             // 标签自动补全
+	g.GET("/:name/articles", h.ListArticles) // 标签下的文章列表
+}
+
+// TagVO 标签VO
+type TagVO struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	ArticleCnt int64  `json:"article_cnt"`
+}
+
+// ListArticles 获取标签下已发布的文章
+func (h *TagHandler) ListArticles(c *gin.Context) {
+	name := c.Param("name")
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	articles, err := h.svc.ListArticlesByTag(c, name, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "获取标签文章失败",
+		})
+		return
+	}
+
+	articleVOs := make([]ArticleV0, 0, len(articles))
+	for _, article := range articles {
+		// 列表中不返回正文
+		vo := toArticleVO(article)
+		vo.Content = ""
+		vo.HTML = ""
+		articleVOs = append(articleVOs, vo)
+	}
+	c.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "success",
+		Data: gin.H{
+			"tag":      domain.NormalizeTag(name),
+			"articles": articleVOs,
+		},
+	})
+}
+
+// Trending 获取热门标签
+func (h *TagHandler) Trending(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	tags, err := h.svc.Trending(c, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "获取热门标签失败",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "success",
+		Data: toTagVOs(tags),
+	})
+}
+
+// Suggest 标签自动补全
+func (h *TagHandler) Suggest(c *gin.Context) {
+	prefix := c.Query("prefix")
+	if prefix == "" {
+		c.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "prefix is required",
+		})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 20 {
+		limit = 10
+	}
+
+	tags, err := h.svc.Suggest(c, prefix, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "获取标签失败",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "success",
+		Data: toTagVOs(tags),
+	})
+}
+
+func toTagVOs(tags []domain.Tag) []TagVO {
+	result := make([]TagVO, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, TagVO{
+			ID:         tag.ID,
+			Name:       tag.Name,
+			ArticleCnt: tag.ArticleCnt,
+		})
+	}
+	return result
+}
@@ -1,9 +1,12 @@
 package web
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/Fairy-nn/inspora/internal/domain"
+	"github.com/Fairy-nn/inspora/internal/repository"
 	"github.com/Fairy-nn/inspora/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -24,14 +27,16 @@ func (h *UploadHandler) RegisterRoutes(server *gin.Engine) {
 	g := server.Group("/upload")
 	g.POST("/article/image", h.UploadArticleImage)
 	g.POST("/article/images", h.UploadArticleImages)
+	g.POST("/presign", h.Presign)
+	g.POST("/complete", h.Complete)
+	g.POST("/delete", h.Delete)
 }
 
 // UploadArticleImages 单图上传处理
 func (h *UploadHandler) UploadArticleImage(c *gin.Context) {
 	// 1. 身份验证
-	_, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	uid, ok := h.userID(c)
+	if !ok {
 		return
 	}
 
@@ -42,32 +47,22 @@ func (h *UploadHandler) UploadArticleImage(c *gin.Context) {
 		return
 	}
 
-	// 3. 文件大小校验（10MB限制）
-	if file.Size > 10*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 10MB)"})
-		return
-	}
-
-	// 4. 调用OSS服务上传文件
-	url, err := h.svc.UploadFile(c, file)
+	// 3. 调用OSS服务上传文件，大小和格式由服务按文件内容校验
+	img, err := h.svc.UploadFile(c, uid, file)
 	if err != nil {
-		fmt.Println("Failed to upload file:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file"})
+		h.writeUploadError(c, err)
 		return
 	}
 
-	// 5. 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
-		"url": url,
-	})
+	// 4. 返回原图和缩略图
+	c.JSON(http.StatusOK, toImageVO(img))
 }
 
 // UploadArticleImages 多图上传处理
 func (h *UploadHandler) UploadArticleImages(c *gin.Context) {
 	// 1. 身份验证
-	_, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	uid, ok := h.userID(c)
+	if !ok {
 		return
 	}
 
@@ -85,7 +80,7 @@ func (h *UploadHandler) UploadArticleImages(c *gin.Context) {
 		return
 	}
 
-	// 4. 校验文件数量和单个文件大小
+	// 4. 校验文件数量
 	if len(files) > maxArticleImages {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": fmt.Sprintf("Too many files, maximum %d allowed", maxArticleImages),
@@ -93,23 +88,187 @@ func (h *UploadHandler) UploadArticleImages(c *gin.Context) {
 		return
 	}
 
-	for _, file := range files {
-		if file.Size > 10*1024*1024 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 10MB per file)"})
-			return
-		}
-	}
-
 	// 5. 批量上传文件
-	urls, err := h.svc.UploadFiles(c, files, maxArticleImages)
+	imgs, err := h.svc.UploadFiles(c, uid, files, maxArticleImages)
 	if err != nil {
-		fmt.Println("Failed to upload files:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload files"})
+		h.writeUploadError(c, err)
+		return
+	}
+
+	// 6. 返回成功响应，urls 保留给只需要原图的旧客户端
+	urls := make([]string, 0, len(imgs))
+	images := make([]ImageVO, 0, len(imgs))
+	for _, img := range imgs {
+		urls = append(urls, img.URL)
+		images = append(images, toImageVO(img))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"urls":   urls,
+		"images": images,
+	})
+}
+
+// Presign 申请直传地址，大文件由客户端直接上传到存储，不经过 API 服务
+func (h *UploadHandler) Presign(c *gin.Context) {
+	type Req struct {
+		ContentType string `json:"content_type"`
+		Size        int64  `json:"size"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.ContentType == "" || req.Size <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content_type and size are required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
 		return
 	}
 
-	// 6. 返回成功响应
+	p, err := h.svc.PresignUpload(c, uid, req.ContentType, req.Size)
+	if err != nil {
+		h.writeUploadError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"urls": urls,
+		"upload_url": p.UploadURL,
+		"method":     p.Method,
+		"headers":    p.Headers,
+		"url":        p.FileURL,
+		"max_size":   p.MaxSize,
+		"expire_at":  p.ExpireAt.UnixMilli(),
 	})
 }
+
+// Complete 直传完成后确认上传，url 为申请直传地址时返回的 url
+func (h *UploadHandler) Complete(c *gin.Context) {
+	type Req struct {
+		URL string `json:"url"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	img, err := h.svc.CompleteUpload(c, uid, req.URL)
+	if err != nil {
+		h.writeUploadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toImageVO(img))
+}
+
+// Delete 删除自己上传的文件
+func (h *UploadHandler) Delete(c *gin.Context) {
+	type Req struct {
+		URL string `json:"url"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil || req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+	uid, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeleteUserFile(c, uid, req.URL)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidFileURL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUploadPermissionDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrUploadNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		default:
+			fmt.Println("Failed to delete file:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// writeUploadError 把上传失败的原因转换成对应的状态码
+func (h *UploadHandler) writeUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("File too large (max %d bytes per file)", h.svc.MaxFileSize()),
+		})
+	case errors.Is(err, service.ErrUnsupportedFileType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported file type, only JPEG, PNG and GIF images are allowed"})
+	case errors.Is(err, service.ErrInvalidImage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or oversized image"})
+	case errors.Is(err, service.ErrInvalidFileURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUploadPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, repository.ErrUploadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found or expired"})
+	case errors.Is(err, service.ErrUploadObjectMissing):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File has not been uploaded yet"})
+	case errors.Is(err, service.ErrUploadCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload already completed"})
+	case errors.Is(err, service.ErrPresignNotSupported):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Direct upload is not supported"})
+	case errors.Is(err, service.ErrTooManyFiles):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Too many files, maximum %d allowed", maxArticleImages),
+		})
+	default:
+		fmt.Println("Failed to upload file:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file"})
+	}
+}
+
+func (h *UploadHandler) userID(c *gin.Context) (int64, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+	return uid, true
+}
+
+// ImageVO 上传的图片，variants 是按宽度从小到大排列的缩略图
+type ImageVO struct {
+	URL      string           `json:"url"`
+	Width    int              `json:"width"`
+	Height   int              `json:"height"`
+	Variants []ImageVariantVO `json:"variants"`
+}
+
+type ImageVariantVO struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+func toImageVO(img domain.UploadedImage) ImageVO {
+	variants := make([]ImageVariantVO, 0, len(img.Variants))
+	for _, v := range img.Variants {
+		variants = append(variants, ImageVariantVO{
+			URL:    v.URL,
+			Width:  v.Width,
+			Height: v.Height,
+		})
+	}
+	return ImageVO{
+		URL:      img.URL,
+		Width:    img.Width,
+		Height:   img.Height,
+		Variants: variants,
+	}
+}
@@ -0,0 +1,19 @@
+package ioc
+
+import (
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/spf13/viper"
+)
+
+// InitCommentOptions 读取评论配置，评论默认在发布后 15 分钟内可以编辑
+func InitCommentOptions() service.CommentOptions {
+	opts := service.CommentOptions{
+		EditWindow: viper.GetDuration("comment.edit_window"),
+	}
+	if opts.EditWindow <= 0 {
+		opts.EditWindow = 15 * time.Minute
+	}
+	return opts
+}
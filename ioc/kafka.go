@@ -6,7 +6,10 @@ import (
 
 	articleEvents "github.com/Fairy-nn/inspora/internal/events/article"
 	events "github.com/Fairy-nn/inspora/internal/events/article"
+	commentEvents "github.com/Fairy-nn/inspora/internal/events/comment"
 	feedEvents "github.com/Fairy-nn/inspora/internal/events/feed"
+	notificationEvents "github.com/Fairy-nn/inspora/internal/events/notification"
+	"github.com/Fairy-nn/inspora/internal/events/push"
 	"github.com/IBM/sarama"
 	"github.com/spf13/viper"
 )
@@ -17,10 +20,23 @@ type Consumer interface {
 }
 
 // NewConsumers 返回所有的消费者列表
-func NewConsumers(articleConsumer articleEvents.Consumer, feedConsumer feedEvents.Consumer) []Consumer {
+func NewConsumers(articleConsumer articleEvents.Consumer, feedConsumer feedEvents.Consumer,
+	deletedConsumer *articleEvents.DeletedConsumer,
+	visibilityConsumer *articleEvents.VisibilityConsumer,
+	countConsumer *articleEvents.InteractionCountConsumer,
+	engagementConsumer *commentEvents.EngagementConsumer,
+	notificationConsumer *notificationEvents.Consumer,
+	pushHub *push.Hub) []Consumer {
 	return []Consumer{
 		articleConsumer,
 		feedConsumer,
+		deletedConsumer,
+		visibilityConsumer,
+		countConsumer,
+		engagementConsumer,
+		notificationConsumer,
+		// 推送中心订阅 Redis 频道，和消费者一起随应用启动
+		pushHub,
 	}
 }
 
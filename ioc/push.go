@@ -0,0 +1,15 @@
+package ioc
+
+import (
+	"github.com/Fairy-nn/inspora/internal/events/push"
+	"github.com/redis/go-redis/v9"
+)
+
+// InitPushHub 创建推送中心，发布订阅需要完整的 Redis 客户端
+func InitPushHub(client redis.Cmdable) *push.Hub {
+	uc, ok := client.(redis.UniversalClient)
+	if !ok {
+		panic("推送中心需要支持发布订阅的 Redis 客户端")
+	}
+	return push.NewHub(uc)
+}
@@ -1,18 +1,36 @@
 package ioc
 
 import (
+	"time"
+
 	"github.com/Fairy-nn/inspora/internal/job"
 	"github.com/Fairy-nn/inspora/internal/repository"
 	"github.com/Fairy-nn/inspora/internal/repository/cache"
 	"github.com/Fairy-nn/inspora/internal/service"
 	"github.com/redis/go-redis/v9"
 	"github.com/robfig/cron/v3"
+	"github.com/spf13/viper"
 )
 
 func InitRankingJob(svc service.RankingServiceInterface) *job.RankingJob {
 	return job.NewRankingJob(svc)
 }
 
+// InitScheduledPublishJob 创建定时发布文章任务
+func InitScheduledPublishJob(svc service.ArticleServiceInterface) *job.ScheduledPublishJob {
+	return job.NewScheduledPublishJob(svc)
+}
+
+// InitUploadCleanupJob 创建上传文件清理任务，宽限期默认 24 小时
+func InitUploadCleanupJob(svc service.OSSServiceInterface,
+	attachmentSvc service.AttachmentServiceInterface) *job.UploadCleanupJob {
+	grace := viper.GetDuration("oss.orphan_grace")
+	if grace <= 0 {
+		grace = 24 * time.Hour
+	}
+	return job.NewUploadCleanupJob(svc, attachmentSvc, grace)
+}
+
 // InitRankingRepository 创建排行榜仓库
 func InitRankingRepository(cmdable redis.Cmdable) repository.RankingRepositoryInterface {
 	redisCache := cache.NewRedisRankingCache(cmdable)
@@ -21,12 +39,23 @@ func InitRankingRepository(cmdable redis.Cmdable) repository.RankingRepositoryIn
 }
 
 // 初始化定时任务，这里使用了robfig/cron库来实现定时任务
-func InitJobs(rankingJob *job.RankingJob) *cron.Cron {
+func InitJobs(rankingJob *job.RankingJob, scheduledPublishJob *job.ScheduledPublishJob,
+	uploadCleanupJob *job.UploadCleanupJob) *cron.Cron {
 	expr := cron.New(cron.WithSeconds())
 	// 每三分钟执行一次
 	_, err := expr.AddJob("0 */3 * * * *", job.NewCornJobBuilder().Build(rankingJob))
 	if err != nil {
 		panic(err)
 	}
+	// 每分钟检查一次到期的定时发布文章
+	_, err = expr.AddJob("0 * * * * *", job.NewCornJobBuilder().Build(scheduledPublishJob))
+	if err != nil {
+		panic(err)
+	}
+	// 每小时清理一次没有被引用的上传文件和过期的分片上传任务
+	_, err = expr.AddJob("0 30 * * * *", job.NewCornJobBuilder().Build(uploadCleanupJob))
+	if err != nil {
+		panic(err)
+	}
 	return expr
 }
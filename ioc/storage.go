@@ -0,0 +1,120 @@
+package ioc
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+	"github.com/Fairy-nn/inspora/internal/service/storage/aliyun"
+	"github.com/Fairy-nn/inspora/internal/service/storage/local"
+	"github.com/Fairy-nn/inspora/internal/service/storage/memory"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/spf13/viper"
+)
+
+// InitStorage 根据 storage.type 创建对象存储，可选 oss、local、memory
+// 没有配置时，配置了 OSS 就使用 OSS，否则使用本地文件系统，保证没有 OSS 凭证时也能启动
+func InitStorage() (storage.Storage, error) {
+	typ := viper.GetString("storage.type")
+	if typ == "" {
+		typ = "local"
+		if viper.GetString("oss.endpoint") != "" {
+			typ = "oss"
+		}
+	}
+
+	switch typ {
+	case "oss":
+		return initAliyunStorage()
+	case "local":
+		dir := viper.GetString("storage.local.dir")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		origin := viper.GetString("storage.local.base_url")
+		if origin == "" {
+			origin = "http://localhost:8080"
+		}
+		// 没有配置密钥时随机生成，重启之后之前签发的直传地址失效
+		secret := []byte(viper.GetString("storage.local.secret"))
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		return local.NewStorage(dir, origin, secret)
+	case "memory":
+		// 内存中的文件不对外提供访问，只用于测试
+		return memory.NewStorage("memory://uploads"), nil
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", typ)
+	}
+}
+
+func initAliyunStorage() (storage.Storage, error) {
+	// 从配置文件中获取OSS相关配置
+	endpoint := viper.GetString("oss.endpoint")
+	accessKeyID := viper.GetString("oss.access_key_id")
+	accessKeySecret := viper.GetString("oss.access_key_secret")
+	bucketName := viper.GetString("oss.bucket_name")
+	baseURL := viper.GetString("oss.base_url")
+
+	// 创建OSS客户端
+	client, err := oss.New(endpoint, accessKeyID, accessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OSS client: %w", err)
+	}
+
+	// 获取存储空间
+	bucket, err := client.Bucket(bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket: %w", err)
+	}
+	return aliyun.NewStorage(bucket, baseURL), nil
+}
+
+// InitUploadOptions 读取上传限制，默认单个文件 10MB、最多 4000 万像素，生成 200px 和 800px 两种缩略图
+// 直传地址默认 15 分钟后过期
+func InitUploadOptions() service.UploadOptions {
+	opts := service.UploadOptions{
+		MaxSize:       viper.GetInt64("upload.max_size"),
+		MaxPixels:     viper.GetInt("upload.max_pixels"),
+		VariantWidths: viper.GetIntSlice("upload.variant_widths"),
+		PresignExpire: viper.GetDuration("upload.presign_expire"),
+	}
+	if opts.PresignExpire <= 0 {
+		opts.PresignExpire = 15 * time.Minute
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 10 * 1024 * 1024
+	}
+	if opts.MaxPixels <= 0 {
+		opts.MaxPixels = 40_000_000
+	}
+	if len(opts.VariantWidths) == 0 {
+		opts.VariantWidths = []int{200, 800}
+	}
+	return opts
+}
+
+// InitAttachmentOptions 读取附件上传配置，默认单个附件 200MB、分片 5MB，上传任务 24 小时后过期
+func InitAttachmentOptions() service.AttachmentOptions {
+	opts := service.AttachmentOptions{
+		MaxSize:   viper.GetInt64("upload.attachment.max_size"),
+		ChunkSize: viper.GetInt64("upload.attachment.chunk_size"),
+		Expire:    viper.GetDuration("upload.attachment.expire"),
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 200 * 1024 * 1024
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 5 * 1024 * 1024
+	}
+	if opts.Expire <= 0 {
+		opts.Expire = 24 * time.Hour
+	}
+	return opts
+}
@@ -1,6 +1,13 @@
 package ioc
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Fairy-nn/inspora/internal/service"
+	"github.com/Fairy-nn/inspora/internal/service/storage"
+	"github.com/Fairy-nn/inspora/internal/service/storage/local"
 	"github.com/Fairy-nn/inspora/internal/web"
 	"github.com/Fairy-nn/inspora/internal/web/middleware"
 	"github.com/gin-gonic/gin"
@@ -12,7 +19,17 @@ func InitGin(middlewares []gin.HandlerFunc, u *web.UserHandler,
 	followHandler *web.FollowHandler,
 	searchHandler *web.SearchHandler,
 	feedHandler *web.FeedHandler,
-	uploadHandler *web.UploadHandler) *gin.Engine {
+	uploadHandler *web.UploadHandler,
+	tagHandler *web.TagHandler,
+	seriesHandler *web.SeriesHandler,
+	collaborationHandler *web.CollaborationHandler,
+	attachmentHandler *web.AttachmentHandler,
+	notificationHandler *web.NotificationHandler,
+	pushHandler *web.PushHandler,
+	blockHandler *web.BlockHandler,
+	messageHandler *web.MessageHandler,
+	collectionHandler *web.CollectionHandler,
+	store storage.Storage, ossSvc service.OSSServiceInterface) *gin.Engine {
 	r := gin.Default()
 	println("gin init")
 	r.Use(middlewares...)
@@ -24,6 +41,21 @@ func InitGin(middlewares []gin.HandlerFunc, u *web.UserHandler,
 	searchHandler.RegisterRoutes(r)
 	feedHandler.RegisterRoutes(r)
 	uploadHandler.RegisterRoutes(r)
+	tagHandler.RegisterRoutes(r)
+	seriesHandler.RegisterRoutes(r)
+	collaborationHandler.RegisterRoutes(r)
+	attachmentHandler.RegisterRoutes(r)
+	notificationHandler.RegisterRoutes(r)
+	pushHandler.RegisterRoutes(r)
+	blockHandler.RegisterRoutes(r)
+	messageHandler.RegisterRoutes(r)
+	collectionHandler.RegisterRoutes(r)
+	// 本地存储的文件由 Gin 的静态路由对外提供访问，直传的文件通过签名地址 PUT 上来，
+	// 上传确认之后签名地址虽然没有过期，也不再允许写入
+	if fs, ok := store.(*local.Storage); ok {
+		r.Static(local.PathPrefix, fs.Root())
+		r.PUT(local.PathPrefix+"/*filepath", gin.WrapH(http.StripPrefix(local.PathPrefix, fs.UploadHandler(ossSvc.AcceptUpload))))
+	}
 	return r
 }
 
@@ -40,9 +72,9 @@ func corsMiddleware() gin.HandlerFunc {
 		// 允许的域名
 		allowedOrigin := "https://localhost:8080"
 		c.Header("Access-Control-Allow-Origin", allowedOrigin)
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")     // 允许的请求头
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS") // 允许的请求方法
-		c.Header("Access-Control-Allow-Credentials", "true")                        // 允许携带凭证
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, "+web.PartChecksumHeader) // 允许的请求头
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")                      // 允许的请求方法
+		c.Header("Access-Control-Allow-Credentials", "true")                                             // 允许携带凭证
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -52,7 +84,18 @@ func corsMiddleware() gin.HandlerFunc {
 
 func jwtMiddleware() gin.HandlerFunc {
 	return middleware.NewLoginMiddlewareJWT().IgnorePaths("/user/login", "/user/signup",
-		"/wechat/authrul", "/wechat/callback").Build()
+		"/wechat/authrul", "/wechat/callback", local.PathPrefix+"/").
+		OptionalIf(isSharedArticleRequest).AllowQueryToken(web.PushStreamPath).Build()
+}
+
+// isSharedArticleRequest 通过分享链接阅读文章详情（GET /pub/:id?share=xxx）不需要登录
+func isSharedArticleRequest(c *gin.Context) bool {
+	id, ok := strings.CutPrefix(c.Request.URL.Path, "/pub/")
+	if !ok || c.Request.Method != http.MethodGet || c.Query("share") == "" {
+		return false
+	}
+	_, err := strconv.ParseInt(id, 10, 64)
+	return err == nil
 }
 
 // func sessionMiddleware() gin.HandlerFunc {
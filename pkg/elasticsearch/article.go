@@ -0,0 +1,173 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+)
+
+const articleIndex = "articles"
+
+// articleMapping 文章索引，标签是标准化之后的 keyword，按标签过滤时精确匹配
+const articleMapping = `{
+  "mappings": {
+    "properties": {
+      "id":          {"type": "long"},
+      "title":       {"type": "text"},
+      "content":     {"type": "text"},
+      "abstract":    {"type": "text", "index": false},
+      "author_id":   {"type": "long"},
+      "author_name": {"type": "keyword"},
+      "status":      {"type": "integer"},
+      "tags":        {"type": "keyword"},
+      "ctime":       {"type": "date", "format": "epoch_millis"},
+      "utime":       {"type": "date", "format": "epoch_millis"}
+    }
+  }
+}`
+
+// articleDocument 文章在索引中的文档
+type articleDocument struct {
+	ID         int64    `json:"id"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Abstract   string   `json:"abstract"`
+	AuthorID   int64    `json:"author_id"`
+	AuthorName string   `json:"author_name"`
+	Status     uint8    `json:"status"`
+	Tags       []string `json:"tags"`
+	Ctime      int64    `json:"ctime"`
+	Utime      int64    `json:"utime"`
+}
+
+// ArticleSearchResult 文章搜索结果
+type ArticleSearchResult struct {
+	ID         int64
+	Title      string
+	Abstract   string
+	Author     domain.Author
+	Status     domain.ArticleStatus
+	Ctime      time.Time
+	Utime      time.Time
+	Highlights map[string][]string // 命中关键字的片段，按字段分组
+}
+
+// ArticleSearchService 文章搜索
+type ArticleSearchService struct {
+	indexSvc  *IndexService
+	searchSvc *SearchService
+}
+
+func NewArticleSearchService(indexSvc *IndexService, searchSvc *SearchService) *ArticleSearchService {
+	return &ArticleSearchService{
+		indexSvc:  indexSvc,
+		searchSvc: searchSvc,
+	}
+}
+
+// EnsureIndex 确保文章索引存在，已有的索引会补上新增的字段
+func (s *ArticleSearchService) EnsureIndex(ctx context.Context) error {
+	return s.indexSvc.EnsureIndex(ctx, articleIndex, articleMapping)
+}
+
+// Search 按关键字搜索文章
+func (s *ArticleSearchService) Search(ctx context.Context, query string, from, size int) (*SearchResult, error) {
+	return s.search(ctx, query, nil, from, size)
+}
+
+// SearchByAuthor 在某个作者的文章中搜索
+func (s *ArticleSearchService) SearchByAuthor(ctx context.Context, query string, authorID int64, from, size int) (*SearchResult, error) {
+	return s.search(ctx, query, map[string]any{"term": map[string]any{"author_id": authorID}}, from, size)
+}
+
+// SearchByTag 在带有某个标签的文章中搜索，关键字为空时返回该标签下的所有文章
+// tag 需要是标准化之后的标签
+func (s *ArticleSearchService) SearchByTag(ctx context.Context, query, tag string, from, size int) (*SearchResult, error) {
+	return s.search(ctx, query, map[string]any{"term": map[string]any{"tags": tag}}, from, size)
+}
+
+// search 全文检索，filter 不为空时只在满足条件的文章中检索，过滤条件不参与打分
+// 只检索已发布的文章，索引没有及时删除的私密、撤回的文章也不会被搜到
+func (s *ArticleSearchService) search(ctx context.Context, query string, filter map[string]any, from, size int) (*SearchResult, error) {
+	query = strings.TrimSpace(query)
+	filters := []any{
+		map[string]any{"term": map[string]any{"status": domain.ArticleStatusPublished.ToUint8()}},
+	}
+	if filter != nil {
+		filters = append(filters, filter)
+	}
+	boolQuery := map[string]any{
+		"must":   textQuery(query, "title^3", "content"),
+		"filter": filters,
+	}
+	req := map[string]any{
+		"query": map[string]any{"bool": boolQuery},
+		"from":  from,
+		"size":  size,
+		// 相关度相同时最近更新的在前
+		"sort": []any{"_score", map[string]any{"utime": "desc"}},
+	}
+	if query != "" {
+		req["highlight"] = map[string]any{
+			"fields": map[string]any{
+				"title":   map[string]any{},
+				"content": map[string]any{"fragment_size": 100, "number_of_fragments": 3},
+			},
+		}
+	}
+	return s.searchSvc.Search(ctx, articleIndex, req)
+}
+
+// ProcessSearchResult 把命中的文档转换为搜索结果
+func (s *ArticleSearchService) ProcessSearchResult(result *SearchResult) ([]ArticleSearchResult, int64, error) {
+	articles := make([]ArticleSearchResult, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		var doc articleDocument
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, 0, err
+		}
+		articles = append(articles, ArticleSearchResult{
+			ID:       doc.ID,
+			Title:    doc.Title,
+			Abstract: doc.Abstract,
+			Author: domain.Author{
+				ID:   doc.AuthorID,
+				Name: doc.AuthorName,
+			},
+			Status:     domain.ArticleStatus(doc.Status),
+			Ctime:      time.UnixMilli(doc.Ctime),
+			Utime:      time.UnixMilli(doc.Utime),
+			Highlights: hit.Highlight,
+		})
+	}
+	return articles, result.Total, nil
+}
+
+// IndexArticle 写入文章文档，包含文章的标签
+func (s *ArticleSearchService) IndexArticle(ctx context.Context, article domain.Article) error {
+	tags := article.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return s.indexSvc.Index(ctx, articleIndex, strconv.FormatInt(article.ID, 10), articleDocument{
+		ID:         article.ID,
+		Title:      article.Title,
+		Content:    article.Content,
+		Abstract:   article.GenerateAbstract(),
+		AuthorID:   article.Author.ID,
+		AuthorName: article.Author.Name,
+		Status:     article.Status.ToUint8(),
+		Tags:       tags,
+		Ctime:      article.Ctime.UnixMilli(),
+		Utime:      article.Utime.UnixMilli(),
+	})
+}
+
+// DeleteArticle 删除文章文档
+func (s *ArticleSearchService) DeleteArticle(ctx context.Context, articleID int64) error {
+	return s.indexSvc.Delete(ctx, articleIndex, strconv.FormatInt(articleID, 10))
+}
@@ -0,0 +1,57 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Fairy-nn/inspora/config"
+	es8 "github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// Client 对官方客户端的封装，索引和搜索服务共用一个客户端
+type Client struct {
+	*es8.Client
+}
+
+// NewClient 根据配置创建 Elasticsearch 客户端
+func NewClient(cfg *config.ElasticSearchConfig) (*Client, error) {
+	esCfg := es8.Config{
+		Addresses:  cfg.Addresses,
+		Username:   cfg.Username,
+		Password:   cfg.Password,
+		MaxRetries: cfg.MaxRetries,
+	}
+	if cfg.RequestTimeout > 0 {
+		esCfg.Transport = &http.Transport{
+			ResponseHeaderTimeout: time.Duration(cfg.RequestTimeout) * time.Second,
+		}
+	}
+	client, err := es8.NewClient(esCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch client: %w", err)
+	}
+	return &Client{Client: client}, nil
+}
+
+// toBody 把请求体序列化为 JSON
+func toBody(v any) (io.Reader, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// checkResponse 把 Elasticsearch 返回的错误状态转换为 error
+func checkResponse(res *esapi.Response, action string) error {
+	if !res.IsError() {
+		return nil
+	}
+	body, _ := io.ReadAll(res.Body)
+	return fmt.Errorf("elasticsearch %s failed: %s %s", action, res.Status(), body)
+}
@@ -0,0 +1,96 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Fairy-nn/inspora/config"
+)
+
+// IndexService 索引的创建以及文档的写入和删除
+type IndexService struct {
+	client *Client
+	prefix string // 索引前缀，不同环境使用不同的索引
+}
+
+func NewBaseIndexService(client *Client, cfg *config.ElasticSearchConfig) *IndexService {
+	return &IndexService{
+		client: client,
+		prefix: cfg.IndexPrefix,
+	}
+}
+
+// IndexName 加上环境前缀的索引名
+func (s *IndexService) IndexName(name string) string {
+	return s.prefix + name
+}
+
+// EnsureIndex 索引不存在时按 mapping 创建，已经存在时把 mapping 中新增的字段加到索引上
+// mapping 是创建索引的请求体，包含 mappings.properties
+func (s *IndexService) EnsureIndex(ctx context.Context, name string, mapping string) error {
+	index := s.IndexName(name)
+	res, err := s.client.Indices.Exists([]string{index}, s.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		res, err = s.client.Indices.Create(index,
+			s.client.Indices.Create.WithContext(ctx),
+			s.client.Indices.Create.WithBody(strings.NewReader(mapping)))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		return checkResponse(res, "create index "+index)
+	}
+
+	// 已有的索引只能新增字段，已有字段的类型不变时不会报错
+	var body struct {
+		Mappings json.RawMessage `json:"mappings"`
+	}
+	if err := json.Unmarshal([]byte(mapping), &body); err != nil {
+		return err
+	}
+	res, err = s.client.Indices.PutMapping([]string{index}, strings.NewReader(string(body.Mappings)),
+		s.client.Indices.PutMapping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkResponse(res, "put mapping "+index)
+}
+
+// Index 写入文档，文档已存在时整体覆盖
+func (s *IndexService) Index(ctx context.Context, name, id string, doc any) error {
+	body, err := toBody(doc)
+	if err != nil {
+		return err
+	}
+	index := s.IndexName(name)
+	res, err := s.client.Index(index, body,
+		s.client.Index.WithContext(ctx),
+		s.client.Index.WithDocumentID(id))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkResponse(res, "index document "+index+"/"+id)
+}
+
+// Delete 删除文档，文档不存在时不报错
+func (s *IndexService) Delete(ctx context.Context, name, id string) error {
+	index := s.IndexName(name)
+	res, err := s.client.Delete(index, id, s.client.Delete.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkResponse(res, "delete document "+index+"/"+id)
+}
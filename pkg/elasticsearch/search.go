@@ -0,0 +1,95 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Fairy-nn/inspora/config"
+)
+
+// SearchService 执行搜索请求
+type SearchService struct {
+	client *Client
+	prefix string
+}
+
+func NewBaseSearchService(client *Client, cfg *config.ElasticSearchConfig) *SearchService {
+	return &SearchService{
+		client: client,
+		prefix: cfg.IndexPrefix,
+	}
+}
+
+// SearchResult 一次搜索的命中结果
+type SearchResult struct {
+	Total int64
+	Hits  []Hit
+}
+
+// Hit 命中的一个文档
+type Hit struct {
+	ID        string
+	Source    json.RawMessage
+	Highlight map[string][]string
+}
+
+// Search 在索引中执行查询，query 是完整的搜索请求体
+func (s *SearchService) Search(ctx context.Context, name string, query map[string]any) (*SearchResult, error) {
+	body, err := toBody(query)
+	if err != nil {
+		return nil, err
+	}
+	index := s.prefix + name
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(body))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res, "search "+index); err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID        string              `json:"_id"`
+				Source    json.RawMessage     `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	result := &SearchResult{
+		Total: resp.Hits.Total.Value,
+		Hits:  make([]Hit, 0, len(resp.Hits.Hits)),
+	}
+	for _, h := range resp.Hits.Hits {
+		result.Hits = append(result.Hits, Hit{
+			ID:        h.ID,
+			Source:    h.Source,
+			Highlight: h.Highlight,
+		})
+	}
+	return result, nil
+}
+
+// textQuery 全文检索的查询条件，关键字为空时匹配所有文档
+func textQuery(query string, fields ...string) map[string]any {
+	if query == "" {
+		return map[string]any{"match_all": map[string]any{}}
+	}
+	return map[string]any{
+		"multi_match": map[string]any{
+			"query":  query,
+			"fields": fields,
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Fairy-nn/inspora/internal/domain"
+)
+
+const userIndex = "users"
+
+// userMapping 用户索引，只索引公开的资料，不包含密码、手机号和邮箱
+const userMapping = `{
+  "mappings": {
+    "properties": {
+      "id":       {"type": "long"},
+      "username": {"type": "text", "fields": {"keyword": {"type": "keyword"}}},
+      "name":     {"type": "text"},
+      "ctime":    {"type": "date", "format": "epoch_millis"}
+    }
+  }
+}`
+
+// userDocument 用户在索引中的文档
+type userDocument struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Ctime    int64  `json:"ctime"`
+}
+
+// UserSearchService 用户搜索
+type UserSearchService struct {
+	indexSvc  *IndexService
+	searchSvc *SearchService
+}
+
+func NewUserSearchService(indexSvc *IndexService, searchSvc *SearchService) *UserSearchService {
+	return &UserSearchService{
+		indexSvc:  indexSvc,
+		searchSvc: searchSvc,
+	}
+}
+
+// EnsureIndex 确保用户索引存在
+func (s *UserSearchService) EnsureIndex(ctx context.Context) error {
+	return s.indexSvc.EnsureIndex(ctx, userIndex, userMapping)
+}
+
+// Search 按用户名搜索用户
+func (s *UserSearchService) Search(ctx context.Context, query string, from, size int) (*SearchResult, error) {
+	return s.searchSvc.Search(ctx, userIndex, map[string]any{
+		"query": textQuery(strings.TrimSpace(query), "username^2", "name"),
+		"from":  from,
+		"size":  size,
+	})
+}
+
+// ProcessSearchResult 把命中的文档转换为用户
+func (s *UserSearchService) ProcessSearchResult(result *SearchResult) ([]domain.User, int64, error) {
+	users := make([]domain.User, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		var doc userDocument
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, domain.User{
+			ID:       doc.ID,
+			Username: doc.Username,
+			Name:     doc.Name,
+			Ctime:    doc.Ctime,
+		})
+	}
+	return users, result.Total, nil
+}
+
+// IndexUser 写入用户文档
+func (s *UserSearchService) IndexUser(ctx context.Context, user domain.User) error {
+	return s.indexSvc.Index(ctx, userIndex, strconv.FormatInt(user.ID, 10), userDocument{
+		ID:       user.ID,
+		Username: user.Username,
+		Name:     user.Name,
+		Ctime:    user.Ctime,
+	})
+}
+
+// DeleteUser 删除用户文档
+func (s *UserSearchService) DeleteUser(ctx context.Context, userID int64) error {
+	return s.indexSvc.Delete(ctx, userIndex, strconv.FormatInt(userID, 10))
+}
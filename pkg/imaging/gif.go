@@ -0,0 +1,68 @@
+package imaging
+
+// scanGIF 只读取 GIF 的块结构，不解压图像数据，返回帧数和所有帧的像素之和
+// 帧数或像素超过限制时提前返回，不需要扫描完整个文件
+func scanGIF(data []byte) (frames, pixels int, err error) {
+	// 文件头 6 字节，逻辑屏幕描述符 7 字节
+	if len(data) < 13 {
+		return 0, 0, ErrInvalidImage
+	}
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		// 全局颜色表
+		pos += 3 << (flags&0x07 + 1)
+	}
+	for {
+		if pos >= len(data) {
+			return 0, 0, ErrInvalidImage
+		}
+		switch data[pos] {
+		case 0x21: // 扩展块：标识、标签，之后是数据子块
+			pos, err = skipSubBlocks(data, pos+2)
+			if err != nil {
+				return 0, 0, err
+			}
+		case 0x2C: // 图像描述符
+			if pos+10 > len(data) {
+				return 0, 0, ErrInvalidImage
+			}
+			w := int(data[pos+5]) | int(data[pos+6])<<8
+			h := int(data[pos+7]) | int(data[pos+8])<<8
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				// 局部颜色表
+				pos += 3 << (flags&0x07 + 1)
+			}
+			// LZW 最小码长之后是图像数据子块
+			pos, err = skipSubBlocks(data, pos+1)
+			if err != nil {
+				return 0, 0, err
+			}
+			frames++
+			pixels += w * h
+			if frames > maxGIFFrames || pixels > maxGIFTotalPixels {
+				return frames, pixels, nil
+			}
+		case 0x3B: // 文件结束
+			return frames, pixels, nil
+		default:
+			return 0, 0, ErrInvalidImage
+		}
+	}
+}
+
+// skipSubBlocks 跳过从 pos 开始的数据子块，返回结束标记之后的位置
+func skipSubBlocks(data []byte, pos int) (int, error) {
+	for {
+		if pos >= len(data) {
+			return 0, ErrInvalidImage
+		}
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+		pos += size
+	}
+}
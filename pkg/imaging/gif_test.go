@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// testGIF 生成 frames 帧、每帧 w*h 的动图
+func testGIF(t *testing.T, frames, w, h int) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		img := image.NewPaletted(image.Rect(0, 0, w, h), palette)
+		img.SetColorIndex(i%w, 0, 1)
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatalf("encode gif: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestScanGIF(t *testing.T) {
+	testCases := []struct {
+		name       string
+		data       []byte
+		wantFrames int
+		wantPixels int
+		wantErr    error
+	}{
+		{name: "单帧", data: testGIF(t, 1, 10, 20), wantFrames: 1, wantPixels: 200},
+		{name: "多帧", data: testGIF(t, 5, 10, 10), wantFrames: 5, wantPixels: 500},
+		{name: "文件头不完整", data: []byte("GIF89a"), wantErr: ErrInvalidImage},
+		{name: "缺少结束标记", data: bytes.TrimSuffix(testGIF(t, 2, 10, 10), []byte{0x3B}), wantErr: ErrInvalidImage},
+		{name: "未知的块", data: []byte("GIF89a\x0a\x00\x0a\x00\x00\x00\x00\x99"), wantErr: ErrInvalidImage},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			frames, pixels, err := scanGIF(tc.data)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("scanGIF error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if frames != tc.wantFrames || pixels != tc.wantPixels {
+				t.Errorf("scanGIF = %d frames, %d pixels, want %d, %d", frames, pixels, tc.wantFrames, tc.wantPixels)
+			}
+		})
+	}
+}
+
+func TestProcessGIF(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      []byte
+		maxPixels int
+		wantErr   error
+	}{
+		{name: "正常的动图", data: testGIF(t, 3, 40, 30), maxPixels: 10_000},
+		{name: "帧数超过限制", data: testGIF(t, maxGIFFrames+1, 2, 2), maxPixels: 10_000, wantErr: ErrTooManyFrames},
+		{name: "总像素超过限制", data: testGIF(t, 30, 2000, 2000), wantErr: ErrTooManyPixels},
+		{name: "单帧像素超过限制", data: testGIF(t, 1, 200, 100), maxPixels: 10_000, wantErr: ErrTooManyPixels},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original, variants, err := Process(tc.data, tc.maxPixels, []int{20})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Process error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if original.MimeType != "image/gif" || original.Width != 40 || original.Height != 30 {
+				t.Errorf("original = %s %dx%d", original.MimeType, original.Width, original.Height)
+			}
+			if len(variants) != 1 || variants[0].MimeType != "image/png" {
+				t.Errorf("variants = %d", len(variants))
+			}
+		})
+	}
+}
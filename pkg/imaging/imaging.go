@@ -0,0 +1,165 @@
+// Package imaging 负责上传图片的校验与处理：按文件内容识别格式、去除 EXIF 等元数据、生成缩略图
+// 只依赖标准库，支持 JPEG、PNG 和 GIF
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrInvalidImage      = errors.New("invalid image")
+	ErrTooManyPixels     = errors.New("image dimensions too large")
+	ErrTooManyFrames     = errors.New("too many animation frames")
+)
+
+const jpegQuality = 85
+
+const (
+	// maxGIFFrames 动图的最大帧数
+	maxGIFFrames = 300
+	// maxGIFTotalPixels 动图所有帧的像素之和，解码时每一帧都会单独分配内存
+	maxGIFTotalPixels = 100_000_000
+)
+
+// 支持的 MIME 类型及对应的扩展名
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Image 处理后的图片
+type Image struct {
+	Data     []byte
+	MimeType string
+	Ext      string
+	Width    int
+	Height   int
+}
+
+// Extension 返回支持的图片类型对应的扩展名
+func Extension(mimeType string) (string, bool) {
+	ext, ok := extensions[mimeType]
+	return ext, ok
+}
+
+// DetectType 根据文件头识别图片类型，不信任文件名和客户端传入的 Content-Type
+func DetectType(data []byte) (mimeType, ext string, err error) {
+	mimeType = http.DetectContentType(data)
+	ext, ok := extensions[mimeType]
+	if !ok {
+		return "", "", ErrUnsupportedFormat
+	}
+	return mimeType, ext, nil
+}
+
+// Process 解码并重新编码图片，丢弃 EXIF 等元数据，同时按 widths 生成缩略图
+// 只生成比原图窄的缩略图；maxPixels 用于在解码前拒绝超大尺寸的图片（解压炸弹）
+func Process(data []byte, maxPixels int, widths []int) (Image, []Image, error) {
+	mimeType, ext, err := DetectType(data)
+	if err != nil {
+		return Image{}, nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return Image{}, nil, ErrInvalidImage
+	}
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return Image{}, nil, ErrTooManyPixels
+	}
+
+	var (
+		original Image
+		frame    *image.RGBA
+	)
+	switch mimeType {
+	case "image/gif":
+		// 解码前先扫描帧数和每一帧的尺寸，单帧的尺寸限制挡不住由大量帧组成的解压炸弹
+		frames, pixels, err := scanGIF(data)
+		if err != nil {
+			return Image{}, nil, err
+		}
+		if frames > maxGIFFrames {
+			return Image{}, nil, ErrTooManyFrames
+		}
+		if pixels > maxGIFTotalPixels {
+			return Image{}, nil, ErrTooManyPixels
+		}
+		// GIF 没有 EXIF，逐帧重新编码以保留动画，同时丢掉注释等扩展块
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil || len(g.Image) == 0 {
+			return Image{}, nil, ErrInvalidImage
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, g); err != nil {
+			return Image{}, nil, err
+		}
+		original = Image{Data: buf.Bytes(), MimeType: mimeType, Ext: ext,
+			Width: g.Config.Width, Height: g.Config.Height}
+		frame = toRGBA(g.Image[0])
+	default:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, nil, ErrInvalidImage
+		}
+		frame = toRGBA(img)
+		if mimeType == "image/jpeg" {
+			frame = orient(frame, jpegOrientation(data))
+		}
+		original, err = encode(frame, mimeType)
+		if err != nil {
+			return Image{}, nil, err
+		}
+	}
+
+	variants := make([]Image, 0, len(widths))
+	for _, w := range widths {
+		if w <= 0 || w >= frame.Rect.Dx() {
+			continue
+		}
+		// 动图的缩略图只取第一帧，用 PNG 保存
+		thumbType := mimeType
+		if thumbType == "image/gif" {
+			thumbType = "image/png"
+		}
+		v, err := encode(resize(frame, w), thumbType)
+		if err != nil {
+			return Image{}, nil, err
+		}
+		variants = append(variants, v)
+	}
+	return original, variants, nil
+}
+
+func encode(img *image.RGBA, mimeType string) (Image, error) {
+	var buf bytes.Buffer
+	var err error
+	switch mimeType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	default:
+		return Image{}, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	return Image{
+		Data:     buf.Bytes(),
+		MimeType: mimeType,
+		Ext:      extensions[mimeType],
+		Width:    img.Rect.Dx(),
+		Height:   img.Rect.Dy(),
+	}, nil
+}
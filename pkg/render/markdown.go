@@ -0,0 +1,240 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingRe     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	hrRe          = regexp.MustCompile(`^([-*_])(\s*([-*_]))*$`)
+	ulItemRe      = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	olItemRe      = regexp.MustCompile(`^(\d{1,9})[.)]\s+(.*)$`)
+	imageRe       = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	linkRe        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	strongRe      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emRe          = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	strikeRe      = regexp.MustCompile(`~~([^~]+)~~`)
+	placeholderRe = regexp.MustCompile("\x00(\\d+)\x00")
+	listTypeUL    = "ul"
+	listTypeOL    = "ol"
+	fenceMarks    = []string{"```", "~~~"}
+)
+
+// Markdown 将 Markdown 渲染为 HTML
+// 支持标题、段落、强调、删除线、行内代码、代码块、引用、列表、链接、图片和分隔线，
+// Markdown 中的原始 HTML 会被转义，不会原样输出
+func Markdown(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var b strings.Builder
+	renderBlocks(&b, lines)
+	return b.String()
+}
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	var para []string
+	flushPara := func() {
+		if len(para) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		b.WriteString(renderInline(strings.Join(para, "\n")))
+		b.WriteString("</p>\n")
+		para = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+
+		if trimmed == "" {
+			flushPara()
+			continue
+		}
+
+		// 代码块
+		if fence := fencePrefix(trimmed); fence != "" {
+			flushPara()
+			lang := strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1]))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			if lang != "" {
+				b.WriteString(`<pre><code class="language-` + html.EscapeString(strings.Fields(lang)[0]) + `">`)
+			} else {
+				b.WriteString("<pre><code>")
+			}
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+			continue
+		}
+
+		// 标题
+		if m := headingRe.FindStringSubmatch(trimmed); m != nil {
+			flushPara()
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			continue
+		}
+
+		// 分隔线，至少三个相同的字符
+		if hrRe.MatchString(trimmed) && strings.Count(trimmed, trimmed[:1]) >= 3 {
+			flushPara()
+			b.WriteString("<hr>\n")
+			continue
+		}
+
+		// 引用，连续的 > 开头的行组成一个引用块
+		if strings.HasPrefix(trimmed, ">") {
+			flushPara()
+			var quote []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(t, ">") {
+					i--
+					break
+				}
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(t, ">"), " "))
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quote)
+			b.WriteString("</blockquote>\n")
+			continue
+		}
+
+		// 列表
+		if typ, start := listItemType(trimmed); typ != "" {
+			flushPara()
+			i = renderList(b, lines, i, typ, start)
+			continue
+		}
+
+		para = append(para, trimmed)
+	}
+	flushPara()
+}
+
+// renderList 渲染从 lines[i] 开始的列表，返回列表最后一行的下标
+func renderList(b *strings.Builder, lines []string, i int, typ string, start int) int {
+	if typ == listTypeOL && start != 1 {
+		b.WriteString(`<ol start="` + strconv.Itoa(start) + `">` + "\n")
+	} else {
+		b.WriteString("<" + typ + ">\n")
+	}
+
+	var item []string
+	flushItem := func() {
+		if item != nil {
+			b.WriteString("<li>" + renderInline(strings.Join(item, "\n")) + "</li>\n")
+			item = nil
+		}
+	}
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			break
+		}
+		if t, _ := listItemType(trimmed); t == typ {
+			flushItem()
+			item = []string{listItemText(trimmed, typ)}
+			continue
+		}
+		// 缩进的行是上一项的延续
+		if item != nil && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			item = append(item, trimmed)
+			continue
+		}
+		break
+	}
+	flushItem()
+	b.WriteString("</" + typ + ">\n")
+	return i - 1
+}
+
+func listItemType(line string) (string, int) {
+	if ulItemRe.MatchString(line) && !hrRe.MatchString(line) {
+		return listTypeUL, 0
+	}
+	if m := olItemRe.FindStringSubmatch(line); m != nil {
+		start, _ := strconv.Atoi(m[1])
+		return listTypeOL, start
+	}
+	return "", 0
+}
+
+func listItemText(line, typ string) string {
+	if typ == listTypeUL {
+		return ulItemRe.FindStringSubmatch(line)[1]
+	}
+	return olItemRe.FindStringSubmatch(line)[2]
+}
+
+func fencePrefix(line string) string {
+	for _, f := range fenceMarks {
+		if strings.HasPrefix(line, f) {
+			return f
+		}
+	}
+	return ""
+}
+
+// renderInline 渲染行内语法，反引号中的内容作为代码原样输出
+func renderInline(text string) string {
+	// 去掉 NUL 字符，NUL 用作渲染时的占位符
+	text = strings.ReplaceAll(text, "\x00", "")
+	parts := strings.Split(text, "`")
+	// 反引号数量为奇数时，最后一个反引号没有闭合，按普通字符处理
+	if len(parts)%2 == 0 {
+		last := len(parts) - 1
+		parts[last-1] = parts[last-1] + "`" + parts[last]
+		parts = parts[:last]
+	}
+
+	var b strings.Builder
+	for i, part := range parts {
+		if i%2 == 1 {
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		b.WriteString(renderSpans(html.EscapeString(part)))
+	}
+	return b.String()
+}
+
+// renderSpans 在已经转义过的文本上渲染链接、图片和强调
+func renderSpans(text string) string {
+	// 生成的标签先用占位符代替，避免标签属性中的 _ 和 * 被当作强调语法
+	var tags []string
+	protect := func(tag string) string {
+		tags = append(tags, tag)
+		return "\x00" + strconv.Itoa(len(tags)-1) + "\x00"
+	}
+
+	text = imageRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := imageRe.FindStringSubmatch(s)
+		if !safeURL(m[2]) {
+			return m[1]
+		}
+		return protect(`<img src="` + m[2] + `" alt="` + m[1] + `">`)
+	})
+	text = linkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRe.FindStringSubmatch(s)
+		if !safeURL(m[2]) {
+			return m[1]
+		}
+		return protect(`<a href="`+m[2]+`" rel="nofollow noopener" target="_blank">`) + m[1] + protect("</a>")
+	})
+	text = strongRe.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emRe.ReplaceAllString(text, "<em>$1$2</em>")
+	text = strikeRe.ReplaceAllString(text, "<del>$1</del>")
+	text = strings.ReplaceAll(text, "\n", "<br>\n")
+
+	return placeholderRe.ReplaceAllStringFunc(text, func(s string) string {
+		idx, _ := strconv.Atoi(strings.Trim(s, "\x00"))
+		return tags[idx]
+	})
+}
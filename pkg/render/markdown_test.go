@@ -0,0 +1,107 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	testCases := []struct {
+		name    string
+		src     string
+		want    string
+		notWant []string
+	}{
+		{
+			name: "标题",
+			src:  "## 标题 ##",
+			want: "<h2>标题</h2>\n",
+		},
+		{
+			name: "段落和强调",
+			src:  "**粗体** *斜体* ~~删除~~",
+			want: "<p><strong>粗体</strong> <em>斜体</em> <del>删除</del></p>\n",
+		},
+		{
+			name: "段落内换行",
+			src:  "a\nb\n\nc",
+			want: "<p>a<br>\nb</p>\n<p>c</p>\n",
+		},
+		{
+			name: "行内代码不渲染强调",
+			src:  "`**a** <b>`",
+			want: "<p><code>**a** &lt;b&gt;</code></p>\n",
+		},
+		{
+			name: "链接",
+			src:  "[站点](https://example.com/a_b_c)",
+			want: `<p><a href="https://example.com/a_b_c" rel="nofollow noopener" target="_blank">站点</a></p>` + "\n",
+		},
+		{
+			name: "图片",
+			src:  "![图](/img/a.png)",
+			want: `<p><img src="/img/a.png" alt="图"></p>` + "\n",
+		},
+		{
+			name:    "转义原始 HTML",
+			src:     "<script>alert(1)</script>",
+			want:    "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n",
+			notWant: []string{"<script>"},
+		},
+		{
+			name:    "javascript 链接只保留文字",
+			src:     "[点我](javascript:alert(1))",
+			notWant: []string{"<a", "javascript"},
+		},
+		{
+			name:    "协议相对链接只保留文字",
+			src:     "[点我](//evil.com)",
+			want:    "<p>点我</p>\n",
+			notWant: []string{"<a"},
+		},
+		{
+			name:    "反斜杠开放跳转只保留文字",
+			src:     `[点我](/\evil.com)`,
+			want:    "<p>点我</p>\n",
+			notWant: []string{"<a"},
+		},
+		{
+			name:    "data 图片只保留替代文字",
+			src:     "![x](data:image/svg+xml;base64,AAAA)",
+			want:    "<p>x</p>\n",
+			notWant: []string{"<img"},
+		},
+		{
+			name:    "链接文字中的引号不能逃出属性",
+			src:     `[a](/x"onmouseover="alert(1))`,
+			notWant: []string{`"onmouseover`},
+		},
+		{
+			name:    "NUL 字符不能伪造占位符",
+			src:     "[a](/x) \x000\x00",
+			notWant: []string{"\x00"},
+		},
+		{
+			name:    "代码块原样转义",
+			src:     "```go\n<b>x</b>\n```",
+			notWant: []string{"<b>"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Markdown(tc.src)
+			if tc.want != "" && got != tc.want {
+				t.Errorf("Markdown(%q) = %q, want %q", tc.src, got, tc.want)
+			}
+			for _, s := range tc.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("Markdown(%q) = %q, should not contain %q", tc.src, got, s)
+				}
+			}
+			// 渲染结果经过清洗后不应再有变化，说明没有生成白名单之外的内容
+			if sanitized := Sanitize(got); tc.want != "" && sanitized != got {
+				t.Errorf("Sanitize(Markdown(%q)) = %q, want %q", tc.src, sanitized, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// @ 前面不能是字母、数字或者 @ 和 .，避免把邮箱地址当成提及
+var mentionRe = regexp.MustCompile(`(^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}_-]{1,32})`)
+
+// 这些标签中的文本不做提及替换
+var mentionSkipTags = map[string]bool{"a": true, "code": true, "pre": true}
+
+// Mentions 提取文本中 @ 提及的用户名，按出现顺序去重，最多返回 limit 个
+func Mentions(text string, limit int) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range mentionRe.FindAllStringSubmatch(text, -1) {
+		name := m[2]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if len(names) >= limit {
+			break
+		}
+	}
+	return names
+}
+
+// LinkMentions 把 HTML 文本中的 @用户名 替换为链接，links 为用户名到链接地址的映射
+// 只替换 links 中存在的用户名，链接和代码中的文本保持不变
+func LinkMentions(src string, links map[string]string) string {
+	if len(links) == 0 {
+		return src
+	}
+	var b strings.Builder
+	z := xhtml.NewTokenizer(strings.NewReader(src))
+	skip := 0 // 处于不替换标签内部的层数
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			return b.String()
+		}
+		switch tt {
+		case xhtml.StartTagToken, xhtml.EndTagToken:
+			name, _ := z.TagName()
+			if mentionSkipTags[string(name)] {
+				if tt == xhtml.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			}
+		case xhtml.TextToken:
+			if skip == 0 {
+				b.WriteString(linkText(html.UnescapeString(string(z.Raw())), links))
+				continue
+			}
+		}
+		b.Write(z.Raw())
+	}
+}
+
+// linkText 转义纯文本并把其中的提及替换为链接
+func linkText(text string, links map[string]string) string {
+	var b strings.Builder
+	last := 0
+	for _, idx := range mentionRe.FindAllStringSubmatchIndex(text, -1) {
+		// idx[4]:idx[5] 是用户名，前面一个字符是 @
+		name := text[idx[4]:idx[5]]
+		href, ok := links[name]
+		if !ok {
+			continue
+		}
+		at := idx[4] - 1
+		b.WriteString(html.EscapeString(text[last:at]))
+		b.WriteString(`<a href="`)
+		b.WriteString(html.EscapeString(href))
+		b.WriteString(`">@`)
+		b.WriteString(html.EscapeString(name))
+		b.WriteString(`</a>`)
+		last = idx[5]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
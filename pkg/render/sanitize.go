@@ -0,0 +1,161 @@
+package render
+
+import (
+	"html"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// 允许输出的标签及其允许的属性
+var allowedTags = map[string][]string{
+	"p": nil, "br": nil, "hr": nil, "div": nil, "span": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"strong": nil, "b": nil, "em": nil, "i": nil, "u": nil, "del": nil, "s": nil,
+	"blockquote": nil, "pre": nil, "code": {"class"},
+	"ul": nil, "ol": {"start"}, "li": nil,
+	"table": nil, "thead": nil, "tbody": nil, "tr": nil, "th": nil, "td": nil,
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title"},
+}
+
+// 这些标签连同其中的内容一起丢弃
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true,
+	"embed": true, "template": true, "noscript": true, "textarea": true,
+}
+
+// 块级标签，提取纯文本时在前后补空白，避免相邻段落的文字粘在一起
+var blockTags = map[string]bool{
+	"p": true, "br": true, "hr": true, "div": true, "li": true, "blockquote": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "tr": true, "td": true, "th": true,
+}
+
+// Sanitize 按白名单清洗 HTML，只保留安全的标签和属性，链接只允许 http/https/mailto 和站内地址
+func Sanitize(src string) string {
+	var b strings.Builder
+	z := xhtml.NewTokenizer(strings.NewReader(src))
+	skip := 0 // 处于被丢弃标签内部的层数
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			// 读到结尾或者遇到无法解析的内容时结束
+			return b.String()
+		}
+		tok := z.Token()
+		switch tt {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				if tt == xhtml.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			attrs, ok := allowedTags[tok.Data]
+			if !ok || skip > 0 {
+				continue
+			}
+			b.WriteString("<" + tok.Data)
+			for _, attr := range tok.Attr {
+				if !allowedAttr(attrs, attr.Key) {
+					continue
+				}
+				if (attr.Key == "href" || attr.Key == "src") && !safeURL(attr.Val) {
+					continue
+				}
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			if tok.Data == "a" {
+				b.WriteString(` rel="nofollow noopener" target="_blank"`)
+			}
+			b.WriteString(">")
+		case xhtml.EndTagToken:
+			if droppedTags[tok.Data] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if _, ok := allowedTags[tok.Data]; ok && skip == 0 {
+				b.WriteString("</" + tok.Data + ">")
+			}
+		case xhtml.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+		}
+	}
+}
+
+// StripTags 去掉所有标签，返回其中的文本，连续空白会合并为一个空格
+func StripTags(src string) string {
+	var b strings.Builder
+	z := xhtml.NewTokenizer(strings.NewReader(src))
+	skip := 0
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			return strings.Join(strings.Fields(b.String()), " ")
+		}
+		tok := z.Token()
+		switch tt {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken, xhtml.EndTagToken:
+			if droppedTags[tok.Data] {
+				if tt == xhtml.StartTagToken {
+					skip++
+				} else if tt == xhtml.EndTagToken && skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if blockTags[tok.Data] {
+				b.WriteString(" ")
+			}
+		case xhtml.TextToken:
+			if skip == 0 {
+				b.WriteString(tok.Data)
+			}
+		}
+	}
+}
+
+// PlainToHTML 将纯文本转换为 HTML，空行分隔段落，段落内的换行转换为 <br>
+func PlainToHTML(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	var b strings.Builder
+	for _, para := range strings.Split(src, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+func allowedAttr(attrs []string, key string) bool {
+	for _, a := range attrs {
+		if a == key {
+			return true
+		}
+	}
+	return false
+}
+
+// safeURL 只允许 http、https、mailto 以及站内的相对地址
+// 浏览器会忽略地址中的制表符和换行，并把 \ 当作 /，所以 //evil.com、/\evil.com 都会跳转到站外
+func safeURL(raw string) bool {
+	u := strings.ToLower(strings.TrimSpace(html.UnescapeString(raw)))
+	u = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(u)
+	if len(u) > 1 && u[0] == '/' && (u[1] == '/' || u[1] == '\\') {
+		return false
+	}
+	for _, prefix := range []string{"http://", "https://", "mailto:", "/", "#"} {
+		if strings.HasPrefix(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
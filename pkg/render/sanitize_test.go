@@ -0,0 +1,142 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeURL(t *testing.T) {
+	testCases := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "http", url: "http://example.com/a", want: true},
+		{name: "https 大写", url: "HTTPS://example.com", want: true},
+		{name: "mailto", url: "mailto:a@example.com", want: true},
+		{name: "站内地址", url: "/articles/1", want: true},
+		{name: "锚点", url: "#section", want: true},
+		{name: "根路径", url: "/", want: true},
+		{name: "协议相对地址", url: "//evil.com", want: false},
+		{name: "反斜杠", url: `/\evil.com`, want: false},
+		{name: "反斜杠实体", url: "/&#92;evil.com", want: false},
+		{name: "制表符", url: "/\t/evil.com", want: false},
+		{name: "换行", url: "/\n\\evil.com", want: false},
+		{name: "前导空白", url: "  //evil.com", want: false},
+		{name: "javascript", url: "javascript:alert(1)", want: false},
+		{name: "javascript 大小写", url: "JaVaScRiPt:alert(1)", want: false},
+		{name: "javascript 实体", url: "&#106;avascript:alert(1)", want: false},
+		{name: "javascript 内含制表符", url: "java\tscript:alert(1)", want: false},
+		{name: "data", url: "data:text/html;base64,PHNjcmlwdD4=", want: false},
+		{name: "vbscript", url: "vbscript:msgbox(1)", want: false},
+		{name: "相对路径", url: "evil.com", want: false},
+		{name: "空地址", url: "", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := safeURL(tc.url); got != tc.want {
+				t.Errorf("safeURL(%q) = %v, want %v", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		name    string
+		src     string
+		want    string
+		notWant []string
+	}{
+		{
+			name: "保留白名单标签",
+			src:  "<p><strong>粗体</strong><em>斜体</em></p>",
+			want: "<p><strong>粗体</strong><em>斜体</em></p>",
+		},
+		{
+			name:    "丢弃 script 及其内容",
+			src:     "<p>a</p><script>alert(1)</script><p>b</p>",
+			want:    "<p>a</p><p>b</p>",
+			notWant: []string{"alert"},
+		},
+		{
+			name:    "嵌套的丢弃标签",
+			src:     "<style><script>x</script>y</style>z",
+			want:    "z",
+			notWant: []string{"x", "y"},
+		},
+		{
+			name:    "去掉事件属性",
+			src:     `<img src="/a.png" onerror="alert(1)">`,
+			want:    `<img src="/a.png">`,
+			notWant: []string{"onerror"},
+		},
+		{
+			name:    "去掉 javascript 链接",
+			src:     `<a href="javascript:alert(1)">x</a>`,
+			want:    `<a rel="nofollow noopener" target="_blank">x</a>`,
+			notWant: []string{"javascript"},
+		},
+		{
+			name:    "去掉开放跳转链接",
+			src:     `<a href="/\evil.com">x</a>`,
+			want:    `<a rel="nofollow noopener" target="_blank">x</a>`,
+			notWant: []string{"evil.com"},
+		},
+		{
+			name: "链接追加 rel",
+			src:  `<a href="https://example.com" title="t">x</a>`,
+			want: `<a href="https://example.com" title="t" rel="nofollow noopener" target="_blank">x</a>`,
+		},
+		{
+			name:    "去掉不在白名单的标签",
+			src:     `<svg onload="alert(1)"><p>x</p></svg>`,
+			want:    "<p>x</p>",
+			notWant: []string{"svg", "onload"},
+		},
+		{
+			name:    "iframe",
+			src:     `<iframe src="https://evil.com"></iframe>ok`,
+			want:    "ok",
+			notWant: []string{"iframe"},
+		},
+		{
+			name:    "转义文本和属性",
+			src:     `<img alt="&quot;><script>" src="/a.png">1 &lt; 2`,
+			want:    `<img alt="&#34;&gt;&lt;script&gt;" src="/a.png">1 &lt; 2`,
+			notWant: []string{"<script>"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Sanitize(tc.src)
+			if got != tc.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tc.src, got, tc.want)
+			}
+			for _, s := range tc.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("Sanitize(%q) = %q, should not contain %q", tc.src, got, s)
+				}
+			}
+		})
+	}
+}
+
+func TestStripTags(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "段落之间补空白", src: "<p>a</p><p>b</p>", want: "a b"},
+		{name: "丢弃 script", src: "x<script>alert(1)</script>y", want: "xy"},
+		{name: "合并空白", src: "<div>  a \n\n b </div>", want: "a b"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := StripTags(tc.src); got != tc.want {
+				t.Errorf("StripTags(%q) = %q, want %q", tc.src, got, tc.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ package main
 
 import (
 	events "github.com/Fairy-nn/inspora/internal/events/article"
+	commentevents "github.com/Fairy-nn/inspora/internal/events/comment"
 	feedevents "github.com/Fairy-nn/inspora/internal/events/feed"
+	"github.com/Fairy-nn/inspora/internal/events/notification"
+	"github.com/Fairy-nn/inspora/internal/events/push"
 	"github.com/Fairy-nn/inspora/internal/repository"
 	"github.com/Fairy-nn/inspora/internal/repository/cache"
 	"github.com/Fairy-nn/inspora/internal/repository/dao"
@@ -15,13 +18,63 @@ import (
 )
 
 var commentServiceSet = wire.NewSet(
+	ioc.InitCommentOptions,
 	dao.NewCommentDAO,
 	cache.NewRedisCommentCache,
+	cache.NewRedisCommentRankCache,
 	repository.NewCachedCommentRepository,
+	commentevents.NewKafkaProducer,
 	service.NewCommentService,
+	service.NewCommentRankService,
+	commentevents.NewEngagementConsumer,
 	web.NewCommentHandler,
 )
 
+var mentionServiceSet = wire.NewSet(
+	dao.NewMentionDAO,
+	repository.NewMentionRepository,
+	notification.NewKafkaProducer,
+	service.NewMentionService,
+)
+
+var notificationServiceSet = wire.NewSet(
+	dao.NewNotificationDAO,
+	cache.NewRedisNotificationCache,
+	repository.NewNotificationRepository,
+	service.NewNotificationService,
+	service.NewNotificationEventService,
+	notification.NewConsumer,
+	web.NewNotificationHandler,
+)
+
+var pushServiceSet = wire.NewSet(
+	ioc.InitPushHub,
+	wire.Bind(new(push.Publisher), new(*push.Hub)),
+	wire.Bind(new(push.Subscriber), new(*push.Hub)),
+	web.NewPushHandler,
+)
+
+var blockServiceSet = wire.NewSet(
+	dao.NewBlockDAO,
+	repository.NewBlockRepository,
+	service.NewBlockService,
+	web.NewBlockHandler,
+)
+
+var messageServiceSet = wire.NewSet(
+	dao.NewMessageDAO,
+	repository.NewMessageRepository,
+	service.NewMessageService,
+	web.NewMessageHandler,
+)
+
+var collectionServiceSet = wire.NewSet(
+	dao.NewCollectionDAO,
+	repository.NewCollectionRepository,
+	service.NewCollectionService,
+	web.NewCollectionHandler,
+)
+
 var followServiceSet = wire.NewSet(
 	dao.NewFollowRelationDAO,
 	cache.NewRedisFollowCache,
@@ -53,21 +106,59 @@ var interactionServiceSet = wire.NewSet(
 	ProvideDependentInteractionService,
 )
 
+var tagServiceSet = wire.NewSet(
+	dao.NewTagDAO,
+	cache.NewRedisTagCache,
+	repository.NewCachedTagRepository,
+	service.NewTagService,
+	web.NewTagHandler,
+)
+
+var seriesServiceSet = wire.NewSet(
+	dao.NewSeriesDAO,
+	repository.NewSeriesRepository,
+	service.NewSeriesService,
+	web.NewSeriesHandler,
+)
+
+var collaborationServiceSet = wire.NewSet(
+	dao.NewArticleCollaboratorDAO,
+	repository.NewCollaboratorRepository,
+	service.NewCollaborationService,
+	web.NewCollaborationHandler,
+)
+
 var ossServiceSet = wire.NewSet(
+	ioc.InitStorage,
+	ioc.InitUploadOptions,
+	dao.NewUploadDAO,
+	repository.NewUploadRepository,
 	service.NewOSSService,
 	web.NewUploadHandler,
 )
 
-func ProvideDependentCommentService(repo repository.CommentRepository, feedProd feedevents.Producer, articleSvc service.ArticleServiceInterface) service.CommentService {
-	return service.NewCommentService(repo, feedProd, articleSvc)
+var attachmentServiceSet = wire.NewSet(
+	ioc.InitAttachmentOptions,
+	dao.NewAttachmentDAO,
+	cache.NewRedisChunkUploadCache,
+	repository.NewAttachmentRepository,
+	service.NewAttachmentService,
+	web.NewAttachmentHandler,
+)
+
+func ProvideDependentCommentService(repo repository.CommentRepository, feedProd feedevents.Producer, articleSvc service.ArticleServiceInterface,
+	interactionSvc service.InteractionServiceInterface, engagementProd commentevents.Producer,
+	mentionSvc service.MentionServiceInterface, blockSvc service.BlockServiceInterface, opts service.CommentOptions) service.CommentService {
+	return service.NewCommentService(repo, feedProd, articleSvc, interactionSvc, engagementProd, mentionSvc, blockSvc, opts)
 }
 
-func ProvideDependentFollowService(repo repository.FollowRepository, feedProd feedevents.Producer) service.FollowService {
-	return service.NewFollowService(repo, feedProd)
+func ProvideDependentFollowService(repo repository.FollowRepository, feedProd feedevents.Producer, blockSvc service.BlockServiceInterface) service.FollowService {
+	return service.NewFollowService(repo, feedProd, blockSvc)
 }
 
-func ProvideDependentInteractionService(repo repository.InteractionRepositoryInterface, feedProd feedevents.Producer, articleSvc service.ArticleServiceInterface) service.InteractionServiceInterface {
-	return service.NewInteractionService(repo, feedProd, articleSvc)
+func ProvideDependentInteractionService(repo repository.InteractionRepositoryInterface, feedProd feedevents.Producer, countProd events.Producer, articleSvc service.ArticleServiceInterface,
+	blockSvc service.BlockServiceInterface, collectionRepo repository.CollectionRepository) service.InteractionServiceInterface {
+	return service.NewInteractionService(repo, feedProd, countProd, articleSvc, blockSvc, collectionRepo)
 }
 
 func InitApp() (*App, error) {
@@ -100,6 +191,8 @@ func InitApp() (*App, error) {
 		service.NewArticleService,
 		repository.NewCachedArticleRepository,
 		dao.NewArticleDAO,
+		dao.NewArticleShareDAO,
+		repository.NewArticleShareRepository,
 
 		//repository.NewInteractionRepository,
 		//cache.NewRedisInteractionCache,
@@ -109,11 +202,19 @@ func InitApp() (*App, error) {
 		//events.NewKafkaConsumer,
 		events.NewKafkaProducer,
 		events.NewInteractionBatchConsumer,
+		events.NewDeletedConsumer,
+		events.NewVisibilityConsumer,
+		events.NewInteractionCountConsumer,
+		service.NewArticleCleanupService,
+		wire.Bind(new(events.DeletedHandler), new(*service.ArticleCleanupService)),
+		wire.Bind(new(events.VisibilityHandler), new(*service.ArticleCleanupService)),
 
 		ioc.InitRankingRepository,
 		service.NewBatchRankService,
 
 		ioc.InitRankingJob,
+		ioc.InitScheduledPublishJob,
+		ioc.InitUploadCleanupJob,
 		ioc.InitJobs,
 		commentServiceSet,
 		followServiceSet,
@@ -124,6 +225,16 @@ func InitApp() (*App, error) {
 		interactionServiceSet,
 
 		ossServiceSet,
+		tagServiceSet,
+		seriesServiceSet,
+		collaborationServiceSet,
+		attachmentServiceSet,
+		mentionServiceSet,
+		notificationServiceSet,
+		pushServiceSet,
+		blockServiceSet,
+		messageServiceSet,
+		collectionServiceSet,
 		wire.Struct(new(App), "*"), // 绑定 App 结构体
 	)
 
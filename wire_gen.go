@@ -8,7 +8,10 @@ package main
 
 import (
 	"github.com/Fairy-nn/inspora/internal/events/article"
+	"github.com/Fairy-nn/inspora/internal/events/comment"
 	"github.com/Fairy-nn/inspora/internal/events/feed"
+	"github.com/Fairy-nn/inspora/internal/events/notification"
+	"github.com/Fairy-nn/inspora/internal/events/push"
 	"github.com/Fairy-nn/inspora/internal/repository"
 	"github.com/Fairy-nn/inspora/internal/repository/cache"
 	"github.com/Fairy-nn/inspora/internal/repository/dao"
@@ -37,7 +40,13 @@ func InitApp() (*App, error) {
 	searchService := elasticsearch.NewBaseSearchService(client, elasticSearchConfig)
 	userSearchService := elasticsearch.NewUserSearchService(indexService, searchService)
 	articleSearchService := elasticsearch.NewArticleSearchService(indexService, searchService)
-	serviceSearchService := service.NewSearchService(userSearchService, articleSearchService)
+	followRelationDAO := dao.NewFollowRelationDAO(db)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewFollowRepository(followRelationDAO, followCache)
+	blockDAO := dao.NewBlockDAO(db)
+	blockRepository := repository.NewBlockRepository(blockDAO)
+	blockServiceInterface := service.NewBlockService(blockRepository, followRepository)
+	serviceSearchService := service.NewSearchService(userSearchService, articleSearchService, blockServiceInterface)
 	userServiceInterface := service.NewUserService(userRepositoryInterface, serviceSearchService)
 	codeCacheInterface := cache.NewCodeCache(cmdable)
 	codeRepositoryInterface := repository.NewCodeRepository(codeCacheInterface)
@@ -54,38 +63,93 @@ func InitApp() (*App, error) {
 	feedCache := cache.NewRedisFeedCache(cmdable)
 	feedRepository := repository.NewFeedRepository(feedDAOInterface, feedCache)
 	feedProducer := feed.NewKafkaProducer(syncProducer, feedRepository)
-	articleServiceInterface := service.NewArticleService(articleRepository, producer, serviceSearchService, feedProducer)
+	tagDAO := dao.NewTagDAO(db)
+	tagCache := cache.NewRedisTagCache(cmdable)
+	tagRepository := repository.NewCachedTagRepository(tagDAO, tagCache)
+	seriesDAO := dao.NewSeriesDAO(db)
+	seriesRepository := repository.NewSeriesRepository(seriesDAO)
+	articleShareDAO := dao.NewArticleShareDAO(db)
+	articleShareRepository := repository.NewArticleShareRepository(articleShareDAO)
+	articleCollaboratorDAO := dao.NewArticleCollaboratorDAO(db)
+	collaboratorRepository := repository.NewCollaboratorRepository(articleCollaboratorDAO)
+	uploadDAO := dao.NewUploadDAO(db)
+	uploadRepository := repository.NewUploadRepository(uploadDAO)
+	storageStorage, err := ioc.InitStorage()
+	if err != nil {
+		return nil, err
+	}
+	uploadOptions := ioc.InitUploadOptions()
+	ossServiceInterface := service.NewOSSService(storageStorage, uploadRepository, articleRepository, uploadOptions)
+	mentionDAO := dao.NewMentionDAO(db)
+	mentionRepository := repository.NewMentionRepository(mentionDAO)
+	notificationProducer := notification.NewKafkaProducer(syncProducer)
+	mentionServiceInterface := service.NewMentionService(mentionRepository, userRepositoryInterface, notificationProducer)
+	articleServiceInterface := service.NewArticleService(articleRepository, producer, serviceSearchService, feedProducer, tagRepository, seriesRepository, articleShareRepository, collaboratorRepository, ossServiceInterface, mentionServiceInterface)
 	interactionDaoInterface := dao.NewGormInteractionDAO(db)
 	interactionCacheInterface := cache.NewRedisInteractionCache(cmdable)
 	interactionRepositoryInterface := repository.NewInteractionRepository(interactionDaoInterface, interactionCacheInterface)
-	interactionServiceInterface := ProvideDependentInteractionService(interactionRepositoryInterface, feedProducer, articleServiceInterface)
+	collectionDAO := dao.NewCollectionDAO(db)
+	collectionRepository := repository.NewCollectionRepository(collectionDAO)
+	interactionServiceInterface := ProvideDependentInteractionService(interactionRepositoryInterface, feedProducer, producer, articleServiceInterface, blockServiceInterface, collectionRepository)
 	rankingRepositoryInterface := ioc.InitRankingRepository(cmdable)
 	rankingServiceInterface := service.NewBatchRankService(articleServiceInterface, interactionServiceInterface, rankingRepositoryInterface)
 	articleHandler := web.NewArticleHandler(articleServiceInterface, interactionServiceInterface, rankingServiceInterface)
 	commentDAO := dao.NewCommentDAO(db)
 	commentCache := cache.NewRedisCommentCache(cmdable)
-	commentRepository := repository.NewCachedCommentRepository(commentDAO, commentCache)
-	commentService := service.NewCommentService(commentRepository, feedProducer, articleServiceInterface)
+	commentRankCache := cache.NewRedisCommentRankCache(cmdable)
+	commentRepository := repository.NewCachedCommentRepository(commentDAO, commentCache, commentRankCache)
+	commentProducer := comment.NewKafkaProducer(syncProducer)
+	commentOptions := ioc.InitCommentOptions()
+	commentService := service.NewCommentService(commentRepository, feedProducer, articleServiceInterface, interactionServiceInterface, commentProducer, mentionServiceInterface, blockServiceInterface, commentOptions)
 	commentHandler := web.NewCommentHandler(commentService)
-	followRelationDAO := dao.NewFollowRelationDAO(db)
-	followCache := cache.NewRedisFollowCache(cmdable)
-	followRepository := repository.NewFollowRepository(followRelationDAO, followCache)
-	followService := service.NewFollowService(followRepository, feedProducer)
+	followService := service.NewFollowService(followRepository, feedProducer, blockServiceInterface)
 	followHandler := web.NewFollowHandler(followService)
 	searchHandler := web.NewSearchHandler(serviceSearchService)
-	feedServiceInterface := service.NewFeedService(feedRepository, followRepository, articleServiceInterface, userRepositoryInterface, feedProducer)
+	feedServiceInterface := service.NewFeedService(feedRepository, followRepository, articleServiceInterface, userRepositoryInterface, feedProducer, blockServiceInterface)
 	feedHandler := web.NewFeedHandler(feedServiceInterface)
-	ossServiceInterface, err := service.NewOSSService()
-	if err != nil {
-		return nil, err
-	}
 	uploadHandler := web.NewUploadHandler(ossServiceInterface)
-	engine := ioc.InitGin(v, userHandler, articleHandler, commentHandler, followHandler, searchHandler, feedHandler, uploadHandler)
+	tagServiceInterface := service.NewTagService(tagRepository, articleRepository)
+	tagHandler := web.NewTagHandler(tagServiceInterface)
+	seriesServiceInterface := service.NewSeriesService(seriesRepository, articleRepository)
+	seriesHandler := web.NewSeriesHandler(seriesServiceInterface)
+	collaborationServiceInterface := service.NewCollaborationService(articleRepository, collaboratorRepository, userRepositoryInterface)
+	collaborationHandler := web.NewCollaborationHandler(collaborationServiceInterface)
+	attachmentDAO := dao.NewAttachmentDAO(db)
+	chunkUploadCache := cache.NewRedisChunkUploadCache(cmdable)
+	attachmentRepository := repository.NewAttachmentRepository(attachmentDAO, chunkUploadCache)
+	attachmentOptions := ioc.InitAttachmentOptions()
+	attachmentServiceInterface := service.NewAttachmentService(attachmentRepository, articleRepository, collaboratorRepository, storageStorage, attachmentOptions)
+	attachmentHandler := web.NewAttachmentHandler(attachmentServiceInterface)
+	notificationDAO := dao.NewNotificationDAO(db)
+	notificationCache := cache.NewRedisNotificationCache(cmdable)
+	notificationRepository := repository.NewNotificationRepository(notificationDAO, notificationCache)
+	notificationServiceInterface := service.NewNotificationService(notificationRepository, userRepositoryInterface)
+	notificationHandler := web.NewNotificationHandler(notificationServiceInterface)
+	hub := ioc.InitPushHub(cmdable)
+	pushHandler := web.NewPushHandler(hub)
+	blockHandler := web.NewBlockHandler(blockServiceInterface)
+	messageDAO := dao.NewMessageDAO(db)
+	messageRepository := repository.NewMessageRepository(messageDAO)
+	messageServiceInterface := service.NewMessageService(messageRepository, userRepositoryInterface, followRepository, blockServiceInterface, hub)
+	messageHandler := web.NewMessageHandler(messageServiceInterface)
+	collectionServiceInterface := service.NewCollectionService(collectionRepository, articleRepository, interactionServiceInterface)
+	collectionHandler := web.NewCollectionHandler(collectionServiceInterface)
+	engine := ioc.InitGin(v, userHandler, articleHandler, commentHandler, followHandler, searchHandler, feedHandler, uploadHandler, tagHandler, seriesHandler, collaborationHandler, attachmentHandler, notificationHandler, pushHandler, blockHandler, messageHandler, collectionHandler, storageStorage, ossServiceInterface)
 	consumer := article.NewInteractionBatchConsumer(saramaClient, interactionRepositoryInterface)
-	feedConsumer := feed.NewKafkaFeedConsumer(saramaClient, feedRepository, followRepository, articleRepository, userRepositoryInterface)
-	v2 := ioc.NewConsumers(consumer, feedConsumer)
+	feedConsumer := feed.NewKafkaFeedConsumer(saramaClient, feedRepository, followRepository, articleRepository, userRepositoryInterface, hub)
+	articleCleanupService := service.NewArticleCleanupService(articleRepository, interactionRepositoryInterface, commentRepository, rankingRepositoryInterface, feedProducer, tagRepository, seriesRepository, collaboratorRepository, serviceSearchService, ossServiceInterface, attachmentServiceInterface, mentionRepository)
+	deletedConsumer := article.NewDeletedConsumer(saramaClient, articleCleanupService)
+	visibilityConsumer := article.NewVisibilityConsumer(saramaClient, articleCleanupService)
+	engagementHandler := service.NewCommentRankService(commentRepository)
+	engagementConsumer := comment.NewEngagementConsumer(saramaClient, engagementHandler)
+	handler := service.NewNotificationEventService(notificationRepository, hub, blockServiceInterface)
+	notificationConsumer := notification.NewConsumer(saramaClient, handler)
+	interactionCountConsumer := article.NewInteractionCountConsumer(saramaClient, interactionRepositoryInterface)
+	v2 := ioc.NewConsumers(consumer, feedConsumer, deletedConsumer, visibilityConsumer, interactionCountConsumer, engagementConsumer, notificationConsumer, hub)
 	rankingJob := ioc.InitRankingJob(rankingServiceInterface)
-	cron := ioc.InitJobs(rankingJob)
+	scheduledPublishJob := ioc.InitScheduledPublishJob(articleServiceInterface)
+	uploadCleanupJob := ioc.InitUploadCleanupJob(ossServiceInterface, attachmentServiceInterface)
+	cron := ioc.InitJobs(rankingJob, scheduledPublishJob, uploadCleanupJob)
 	defaultSearchInitializer := ioc.ProvideSearchInitializer(userSearchService, articleSearchService)
 	app := &App{
 		Server:    engine,
@@ -98,7 +162,19 @@ func InitApp() (*App, error) {
 
 // wire.go:
 
-var commentServiceSet = wire.NewSet(dao.NewCommentDAO, cache.NewRedisCommentCache, repository.NewCachedCommentRepository, service.NewCommentService, web.NewCommentHandler)
+var commentServiceSet = wire.NewSet(ioc.InitCommentOptions, dao.NewCommentDAO, cache.NewRedisCommentCache, cache.NewRedisCommentRankCache, repository.NewCachedCommentRepository, comment.NewKafkaProducer, service.NewCommentService, service.NewCommentRankService, comment.NewEngagementConsumer, web.NewCommentHandler)
+
+var mentionServiceSet = wire.NewSet(dao.NewMentionDAO, repository.NewMentionRepository, notification.NewKafkaProducer, service.NewMentionService)
+
+var notificationServiceSet = wire.NewSet(dao.NewNotificationDAO, cache.NewRedisNotificationCache, repository.NewNotificationRepository, service.NewNotificationService, service.NewNotificationEventService, notification.NewConsumer, web.NewNotificationHandler)
+
+var pushServiceSet = wire.NewSet(ioc.InitPushHub, wire.Bind(new(push.Publisher), new(*push.Hub)), wire.Bind(new(push.Subscriber), new(*push.Hub)), web.NewPushHandler)
+
+var blockServiceSet = wire.NewSet(dao.NewBlockDAO, repository.NewBlockRepository, service.NewBlockService, web.NewBlockHandler)
+
+var messageServiceSet = wire.NewSet(dao.NewMessageDAO, repository.NewMessageRepository, service.NewMessageService, web.NewMessageHandler)
+
+var collectionServiceSet = wire.NewSet(dao.NewCollectionDAO, repository.NewCollectionRepository, service.NewCollectionService, web.NewCollectionHandler)
 
 var followServiceSet = wire.NewSet(dao.NewFollowRelationDAO, cache.NewRedisFollowCache, repository.NewFollowRepository, service.NewFollowService, web.NewFollowHandler)
 
@@ -108,16 +184,27 @@ var feedServiceSet = wire.NewSet(dao.NewGORMFeedDAO, cache.NewRedisFeedCache, re
 
 var interactionServiceSet = wire.NewSet(dao.NewGormInteractionDAO, cache.NewRedisInteractionCache, repository.NewInteractionRepository, ProvideDependentInteractionService)
 
-var ossServiceSet = wire.NewSet(service.NewOSSService, web.NewUploadHandler)
+var tagServiceSet = wire.NewSet(dao.NewTagDAO, cache.NewRedisTagCache, repository.NewCachedTagRepository, service.NewTagService, web.NewTagHandler)
+
+var seriesServiceSet = wire.NewSet(dao.NewSeriesDAO, repository.NewSeriesRepository, service.NewSeriesService, web.NewSeriesHandler)
+
+var collaborationServiceSet = wire.NewSet(dao.NewArticleCollaboratorDAO, repository.NewCollaboratorRepository, service.NewCollaborationService, web.NewCollaborationHandler)
+
+var ossServiceSet = wire.NewSet(ioc.InitStorage, ioc.InitUploadOptions, dao.NewUploadDAO, repository.NewUploadRepository, service.NewOSSService, web.NewUploadHandler)
+
+var attachmentServiceSet = wire.NewSet(ioc.InitAttachmentOptions, dao.NewAttachmentDAO, cache.NewRedisChunkUploadCache, repository.NewAttachmentRepository, service.NewAttachmentService, web.NewAttachmentHandler)
 
-func ProvideDependentCommentService(repo repository.CommentRepository, feedProd feed.Producer, articleSvc service.ArticleServiceInterface) service.CommentService {
-	return service.NewCommentService(repo, feedProd, articleSvc)
+func ProvideDependentCommentService(repo repository.CommentRepository, feedProd feed.Producer, articleSvc service.ArticleServiceInterface,
+	interactionSvc service.InteractionServiceInterface, engagementProd comment.Producer,
+	mentionSvc service.MentionServiceInterface, blockSvc service.BlockServiceInterface, opts service.CommentOptions) service.CommentService {
+	return service.NewCommentService(repo, feedProd, articleSvc, interactionSvc, engagementProd, mentionSvc, blockSvc, opts)
 }
 
-func ProvideDependentFollowService(repo repository.FollowRepository, feedProd feed.Producer) service.FollowService {
-	return service.NewFollowService(repo, feedProd)
+func ProvideDependentFollowService(repo repository.FollowRepository, feedProd feed.Producer, blockSvc service.BlockServiceInterface) service.FollowService {
+	return service.NewFollowService(repo, feedProd, blockSvc)
 }
 
-func ProvideDependentInteractionService(repo repository.InteractionRepositoryInterface, feedProd feed.Producer, articleSvc service.ArticleServiceInterface) service.InteractionServiceInterface {
-	return service.NewInteractionService(repo, feedProd, articleSvc)
+func ProvideDependentInteractionService(repo repository.InteractionRepositoryInterface, feedProd feed.Producer, countProd article.Producer, articleSvc service.ArticleServiceInterface,
+	blockSvc service.BlockServiceInterface, collectionRepo repository.CollectionRepository) service.InteractionServiceInterface {
+	return service.NewInteractionService(repo, feedProd, countProd, articleSvc, blockSvc, collectionRepo)
 }